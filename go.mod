@@ -6,8 +6,11 @@ require (
 	github.com/flyaways/pool v1.0.1
 	github.com/go-kit/kit v0.10.0
 	github.com/golang/protobuf v1.3.3
-	github.com/oklog/run v1.1.0 // indirect
+	github.com/nats-io/nats-server/v2 v2.1.2
+	github.com/nats-io/nats.go v1.9.2
+	github.com/oklog/run v1.1.0
 	github.com/prometheus/client_golang v1.3.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/prometheus/common v0.9.1
 	github.com/shimingyah/pool v0.0.0-20190724082523-04bd98b0fbfe // indirect
 	golang.org/x/net v0.0.0-20190813141303-74dc4d7220e7
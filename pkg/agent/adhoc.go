@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const AdhocReconnectInterval = 10 * time.Second
+
+// AdhocWork keeps the adhoc stream to the server open and runs every
+// received job once with the AdhocProbers, results are sent back on the
+// stream and never stored in PbResMap
+func AdhocWork(ctx context.Context, logger log.Logger) {
+	level.Info(logger).Log("msg", "AdhocWork start")
+	for {
+		if err := runAdhocStream(ctx, logger); err != nil {
+			level.Error(logger).Log("msg", "adhoc stream broken", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(AdhocReconnectInterval):
+		}
+	}
+}
+
+func runAdhocStream(ctx context.Context, logger log.Logger) error {
+	conn, err := GrpcPool.Get()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := pb.NewProberAdhocClient(conn).AdhocProbeStream(ctx)
+	if err != nil {
+		return err
+	}
+	var sendMux sync.Mutex
+	send := func(msg *pb.AdhocAgentMessage) error {
+		sendMux.Lock()
+		defer sendMux.Unlock()
+		return stream.Send(msg)
+	}
+	if err := send(&pb.AdhocAgentMessage{Ip: LocalIp, Region: LocalRegion}); err != nil {
+		return err
+	}
+	for {
+		job, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		level.Info(logger).Log("msg", "adhoc job receive", "job_id", job.JobId, "prober_type", job.ProberType, "target", job.Target)
+		go func(job *pb.AdhocProbeJob) {
+			msg := runAdhocJob(logger, job)
+			if err := send(msg); err != nil {
+				level.Error(logger).Log("msg", "adhoc result send failed", "job_id", job.JobId, "err", err)
+			}
+		}(job)
+	}
+}
+
+func runAdhocJob(logger log.Logger, job *pb.AdhocProbeJob) *pb.AdhocAgentMessage {
+	msg := &pb.AdhocAgentMessage{Ip: LocalIp, Region: LocalRegion, JobId: job.JobId}
+	pbFunc, ok := AdhocProbers[job.ProberType]
+	if !ok {
+		msg.Error = "unsupported prober type: " + job.ProberType
+		return msg
+	}
+	if err := common.ValidateTarget(job.ProberType, job.Target); err != nil {
+		msg.Error = err.Error()
+		return msg
+	}
+	lt := &LocalTarget{
+		logger:       logger,
+		Addr:         job.Target,
+		SourceRegion: LocalRegion,
+		TargetRegion: job.TargetRegion,
+		ProbeType:    job.ProberType,
+		Prober:       pbFunc,
+	}
+	msg.Results = lt.Prober(lt)
+	if len(msg.Results) == 0 {
+		msg.Error = "probe returned no result"
+	}
+	return msg
+}
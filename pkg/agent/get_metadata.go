@@ -4,13 +4,18 @@ import (
 	"net"
 	"net/http"
 	"io/ioutil"
+	"os"
+	"strings"
+	"time"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 )
 
 var (
-	LocalRegion string
-	LocalIp     string
+	LocalRegion     string
+	LocalIp         string
+	LocalHostname   string
+	LocalInstanceId string
 )
 // TODO get real id func
 
@@ -60,3 +65,31 @@ func GetLocalIp(logger log.Logger) bool {
 	return false
 
 }
+
+// GetLocalInventory fills hostname and ec2 instance id, both are only
+// informational so failures are logged and ignored
+func GetLocalInventory(logger log.Logger) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		level.Warn(logger).Log("msg", "GetLocalInventory_hostname_error", "err", err)
+	}
+	LocalHostname = hostname
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get("http://169.254.169.254/latest/meta-data/instance-id")
+	if err != nil {
+		level.Warn(logger).Log("msg", "GetLocalInventory_instance_id_error", "err", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		level.Warn(logger).Log("msg", "GetLocalInventory_instance_id_rc_ne_200", "resp.StatusCode", resp.StatusCode)
+		return
+	}
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		level.Warn(logger).Log("msg", "GetLocalInventory_instance_id_read_body", "err", err)
+		return
+	}
+	LocalInstanceId = strings.TrimSpace(string(respBytes))
+}
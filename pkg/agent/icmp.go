@@ -79,6 +79,11 @@ func ProbeICMP(lt *LocalTarget) ([]*pb.ProberResultOne) {
 		}
 	}()
 
+	// the target ends up in a shell command line
+	if err := common.ValidateTarget("icmp", lt.Addr); err != nil {
+		level.Error(lt.logger).Log("msg", "ProbeICMP invalid target", "uid", lt.Uid(), "err", err)
+		return nil
+	}
 	pingCmd := fmt.Sprintf("/usr/bin/timeout --signal=KILL 15s  /usr/bin/ping -q -A -f -s 100 -W 1000 -c 50 %s", lt.Addr)
 	level.Info(lt.logger).Log("msg", "LocalTarget  ProbeICMP start ...", "uid", lt.Uid(), "pingcmd", pingCmd)
 	success, outPutStr := execCmd(pingCmd, lt.logger)
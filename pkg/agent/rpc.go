@@ -2,41 +2,62 @@ package agent
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/flyaways/pool"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/version"
 
 	"xprober/pkg/pb"
 )
 
 var (
-	GrpcPool *pool.GRPCPool
+	GrpcPool *ServerPool
+
+	// unix time of the last successful result push
+	lastPushUnix int64
+
+	// RpcToken is sent as a bearer token on every rpc when set
+	RpcToken string
+
+	// results per message of the push stream
+	PushBatchSize = DefaultPushBatchSize
+	// compress pushed results with gzip
+	PushGzip = true
+	// set when the server has no stream rpc, pushes fall back to unary
+	pushStreamUnsupported int32
+
+	// newest probe time of every local target handed to the server or the
+	// spool, only newer results are pushed
+	handedOffMux sync.Mutex
+	handedOff    = make(map[string]int64)
+
+	pushMux sync.Mutex
 )
 
 const (
 	RefreshInterval = 60 * time.Second
 	PushInterval    = 15 * time.Second
 	ReportInterval  = 60 * time.Second
-)
 
-func InitRpcPool(serverAddr string, logger log.Logger) bool {
+	DefaultPushBatchSize = 1000
+	pushTimeout          = 10 * time.Second
+)
 
-	options := &pool.Options{
-		InitTargets:  []string{serverAddr},
-		InitCap:      5,
-		MaxCap:       30,
-		DialTimeout:  time.Second * 5,
-		IdleTimeout:  time.Second * 60,
-		ReadTimeout:  time.Second * 5,
-		WriteTimeout: time.Second * 5,
-	}
+// InitRpcPool dials the servers over tls when creds is set, plaintext otherwise
+func InitRpcPool(serverAddrs []string, creds credentials.TransportCredentials, logger log.Logger) bool {
 
 	//初始化连接池
 	var err error
-	GrpcPool, err = pool.NewGRPCPool(options, grpc.WithInsecure())
+	GrpcPool, err = NewServerPool(logger, serverAddrs, creds, RpcToken)
 
 	if err != nil {
 		level.Error(logger).Log("init_rpc_pool_failed_error", err)
@@ -50,7 +71,19 @@ func InitRpcPool(serverAddr string, logger log.Logger) bool {
 	return true
 
 }
-func reportAgentIp(logger log.Logger) {
+
+// tokenCreds sends the agent token, also over plaintext connections
+type tokenCreds string
+
+func (t tokenCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCreds) RequireTransportSecurity() bool {
+	return false
+}
+
+func reportAgentIp(ctx context.Context, logger log.Logger) {
 	level.Info(logger).Log("msg", "reportAgentIp run...", )
 	conn, err := GrpcPool.Get()
 	if err != nil {
@@ -60,8 +93,20 @@ func reportAgentIp(logger log.Logger) {
 
 	defer conn.Close()
 	c := pb.NewProberAgentIpReportClient(conn)
-	t := pb.ProberAgentIpReportRequest{Ip: LocalIp, Region: LocalRegion}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	targetNum, cycle := LTM.Inventory()
+	t := pb.ProberAgentIpReportRequest{
+		Ip:                    LocalIp,
+		Region:                LocalRegion,
+		Version:               version.Version,
+		Hostname:              LocalHostname,
+		InstanceId:            LocalInstanceId,
+		ProbeTypes:            ProbeTypes(),
+		TargetNum:             int32(targetNum),
+		LastProbeCycleSeconds: cycle.Seconds(),
+		LastPush:              atomic.LoadInt64(&lastPushUnix),
+		ConnectedServer:       GrpcPool.Current(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	r, err := c.ProberAgentIpReports(ctx, &t)
 	if err != nil {
@@ -72,7 +117,7 @@ func reportAgentIp(logger log.Logger) {
 	level.Info(logger).Log("reportAgentIpResult", r)
 
 }
-func getProberTarget(logger log.Logger) {
+func getProberTarget(ctx context.Context, logger log.Logger) {
 	level.Info(logger).Log("msg", "getProberTarget run...", )
 	conn, err := GrpcPool.Get()
 	if err != nil {
@@ -85,7 +130,7 @@ func getProberTarget(logger log.Logger) {
 
 	// Contact the server and print out its response.
 	name := LocalRegion
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	r, err := c.GetProberTargets(ctx, &pb.ProberTargetsGetRequest{LocalRegion: LocalRegion, LocalIp: LocalIp})
@@ -102,37 +147,172 @@ func getProberTarget(logger log.Logger) {
 
 }
 
-func pushPbResults(logger log.Logger) {
-
+// collectNewResults returns the results probed since the last handoff of
+// every local target, with the probe time to mark once they are handed off
+func collectNewResults() ([]*pb.ProberResultOne, map[string]int64) {
+	handedOffMux.Lock()
+	defer handedOffMux.Unlock()
 	var prs []*pb.ProberResultOne
-	f := func(k, v interface{}) bool {
-
+	marks := make(map[string]int64)
+	PbResMap.Range(func(k, v interface{}) bool {
+		uid := k.(string)
 		va := v.([]*pb.ProberResultOne)
-		//key := k.(string)
-		//fmt.Println(key, va)
-		prs = append(prs, va...)
+		ts := newestTimeStamp(va)
+		if ts > handedOff[uid] {
+			prs = append(prs, va...)
+			marks[uid] = ts
+		}
 		return true
+	})
+	return prs, marks
+}
+
+// markHandedOff records results the server or the spool took
+func markHandedOff(marks map[string]int64) {
+	handedOffMux.Lock()
+	defer handedOffMux.Unlock()
+	for uid, ts := range marks {
+		// evicted in between
+		if _, ok := PbResMap.Load(uid); !ok {
+			continue
+		}
+		if ts > handedOff[uid] {
+			handedOff[uid] = ts
+		}
 	}
-	PbResMap.Range(f)
-	if len(prs) == 0 {
-		level.Info(logger).Log("msg", "empty_result_list_not_to_push")
+}
+
+// evictResults forgets the results of a removed local target
+func evictResults(uid string) {
+	handedOffMux.Lock()
+	defer handedOffMux.Unlock()
+	PbResMap.Delete(uid)
+	delete(handedOff, uid)
+}
+
+func newestTimeStamp(prs []*pb.ProberResultOne) int64 {
+	var ts int64
+	for _, r := range prs {
+		if r.TimeStamp > ts {
+			ts = r.TimeStamp
+		}
+	}
+	return ts
+}
+
+func pushPbResults(logger log.Logger) {
+	// the final push on shutdown may meet a periodic one
+	pushMux.Lock()
+	defer pushMux.Unlock()
+	prs, marks := collectNewResults()
+	if len(prs) == 0 && (Spool == nil || Spool.Empty()) {
+		level.Info(logger).Log("msg", "no_new_result_to_push")
 		return
 	}
 
+	if Spool == nil {
+		if err := pushResults(logger, prs); err != nil {
+			level.Error(logger).Log("msg", "could_not_push_result ", "results", len(prs), "error:", err)
+			return
+		}
+		markHandedOff(marks)
+		return
+	}
+	if len(prs) > 0 {
+		// keep probe order, nothing goes out directly while results are spooled
+		if Spool.Empty() {
+			err := pushResults(logger, prs)
+			if err == nil {
+				markHandedOff(marks)
+				return
+			}
+			level.Error(logger).Log("msg", "could_not_push_result_spooling", "results", len(prs), "error:", err)
+		}
+		if err := Spool.Append(prs); err != nil {
+			level.Error(logger).Log("msg", "spool_append_error", "error:", err)
+			return
+		}
+		markHandedOff(marks)
+	}
+	push := func(prs []*pb.ProberResultOne) error { return pushResults(logger, prs) }
+	if err := Spool.Replay(push, time.Now().Add(PushInterval/2)); err != nil {
+		level.Warn(logger).Log("msg", "spool_replay_stopped", "error:", err)
+	}
+}
+
+// pushResults streams the results to the current server in batches, they
+// count as pushed once the server took or rejected all of them
+func pushResults(logger log.Logger, prs []*pb.ProberResultOne) error {
+	if len(prs) == 0 {
+		return nil
+	}
 	conn, err := GrpcPool.Get()
 	if err != nil {
-		level.Error(logger).Log("get_rpc_conn_from_pool_err", err)
-		return
+		return err
 	}
 
 	defer conn.Close()
 	c := pb.NewPushProberResultClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
 	defer cancel()
 
+	if atomic.LoadInt32(&pushStreamUnsupported) == 1 {
+		return pushResultsUnary(ctx, logger, c, prs)
+	}
+	var opts []grpc.CallOption
+	if PushGzip {
+		opts = append(opts, grpc.UseCompressor(gzip.Name))
+	}
+	stream, err := c.PushProberResultStream(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	batch := PushBatchSize
+	if batch <= 0 {
+		batch = DefaultPushBatchSize
+	}
+	for i := 0; i < len(prs); i += batch {
+		end := i + batch
+		if end > len(prs) {
+			end = len(prs)
+		}
+		// io.EOF means the server ended the stream, CloseAndRecv tells why
+		if err := stream.Send(&pb.ProberResultPushRequest{ProberResults: prs[i:end]}); err != nil {
+			break
+		}
+	}
+	r, err := stream.CloseAndRecv()
+	if status.Code(err) == codes.Unimplemented {
+		// server older than the stream rpc, it has no gzip either
+		atomic.StoreInt32(&pushStreamUnsupported, 1)
+		return pushResultsUnary(ctx, logger, c, prs)
+	}
+	if err != nil {
+		return err
+	}
+	return checkPushResponse(logger, r, len(prs))
+}
+
+func pushResultsUnary(ctx context.Context, logger log.Logger, c pb.PushProberResultClient, prs []*pb.ProberResultOne) error {
 	r, err := c.PushProberResults(ctx, &pb.ProberResultPushRequest{ProberResults: prs})
 	if err != nil {
-		level.Error(logger).Log("msg", "could_not_push_result ", "prs", prs, "error:", err)
+		return err
+	}
+	return checkPushResponse(logger, r, len(prs))
+}
+
+// checkPushResponse logs the results the server rejected, those are not
+// pushed again
+func checkPushResponse(logger log.Logger, r *pb.ProberResultPushResponse, sent int) error {
+	if int(r.SuccessNum+r.RejectedNum) != sent {
+		return fmt.Errorf("server acknowledged %d of %d results", r.SuccessNum+r.RejectedNum, sent)
+	}
+	if r.RejectedNum > 0 {
+		level.Warn(logger).Log("msg", "server_rejected_results", "rejected", r.RejectedNum, "results", sent)
+		for _, rj := range r.Rejected {
+			level.Debug(logger).Log("msg", "server_rejected_result", "seq", rj.Seq, "metric", rj.MetricName, "target", rj.TargetAddr, "reason", rj.Reason)
+		}
 	}
-	level.Info(logger).Log("pushPbResults", r)
+	atomic.StoreInt64(&lastPushUnix, time.Now().Unix())
+	return nil
 }
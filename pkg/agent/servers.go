@@ -0,0 +1,309 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flyaways/pool"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"xprober/pkg/common"
+)
+
+/*
+   the agent can be given several server addresses, a name resolving to
+   several ips is expanded to one endpoint per ip. every endpoint is health
+   checked with a blocking dial, rpcs go to the first healthy endpoint in the
+   configured order so the agent fails over and back on its own
+*/
+
+const (
+	ServerCheckInterval = 10 * time.Second
+	serverCheckTimeout  = 3 * time.Second
+)
+
+var (
+	ServerHealthyGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameAgentRpcServerHealthy,
+		Help: "1 when the server endpoint passed the last health check",
+	}, []string{"server"})
+	ServerConnectedGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameAgentRpcServerConnected,
+		Help: "1 for the server endpoint the agent currently sends rpcs to",
+	}, []string{"server"})
+)
+
+func init() {
+	prometheus.MustRegister(ServerHealthyGaugeVec, ServerConnectedGaugeVec)
+}
+
+type serverEndpoint struct {
+	// dial address, ip:port when the configured name was resolved
+	addr string
+	// configured host, verified against the server certificate
+	host    string
+	pool    *pool.GRPCPool
+	healthy bool
+}
+
+// ServerPool hands out connections to the current server endpoint
+type ServerPool struct {
+	logger   log.Logger
+	addrs    []string
+	creds    credentials.TransportCredentials
+	token    string
+	quitChan chan struct{}
+
+	mux       sync.Mutex
+	endpoints []*serverEndpoint
+	current   *serverEndpoint
+}
+
+func NewServerPool(logger log.Logger, addrs []string, creds credentials.TransportCredentials, token string) (*ServerPool, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no server address")
+	}
+	sp := &ServerPool{
+		logger:   log.With(logger, "component", "server_pool"),
+		addrs:    addrs,
+		creds:    creds,
+		token:    token,
+		quitChan: make(chan struct{}),
+	}
+	if err := sp.refresh(); err != nil {
+		return nil, err
+	}
+	sp.check()
+	go sp.run()
+	return sp, nil
+}
+
+func (sp *ServerPool) dialOptions(host string) []grpc.DialOption {
+	var opts []grpc.DialOption
+	if sp.creds != nil {
+		creds := sp.creds.Clone()
+		if creds.Info().ServerName == "" {
+			creds.OverrideServerName(host)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure(), grpc.WithAuthority(host))
+	}
+	if sp.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCreds(sp.token)))
+	}
+	return opts
+}
+
+// resolve expands the configured addresses to endpoints in order, a name
+// that fails to resolve is dialed as is
+func (sp *ServerPool) resolve() []*serverEndpoint {
+	var eps []*serverEndpoint
+	seen := make(map[string]bool)
+	add := func(addr, host string) {
+		if !seen[addr] {
+			seen[addr] = true
+			eps = append(eps, &serverEndpoint{addr: addr, host: host})
+		}
+	}
+	for _, a := range sp.addrs {
+		host, port, err := net.SplitHostPort(a)
+		if err != nil || host == "" || net.ParseIP(host) != nil {
+			add(a, host)
+			continue
+		}
+		ips, err := net.LookupHost(host)
+		if err != nil || len(ips) == 0 {
+			level.Warn(sp.logger).Log("msg", "resolve server address failed", "addr", a, "err", err)
+			add(a, host)
+			continue
+		}
+		sort.Strings(ips)
+		for _, ip := range ips {
+			add(net.JoinHostPort(ip, port), host)
+		}
+	}
+	return eps
+}
+
+// refresh re-resolves the addresses, keeping the pools of known endpoints
+func (sp *ServerPool) refresh() error {
+	eps := sp.resolve()
+	sp.mux.Lock()
+	defer sp.mux.Unlock()
+	old := make(map[string]*serverEndpoint)
+	for _, ep := range sp.endpoints {
+		old[ep.addr] = ep
+	}
+	for i, ep := range eps {
+		if o, ok := old[ep.addr]; ok {
+			eps[i] = o
+			delete(old, ep.addr)
+			continue
+		}
+		p, err := pool.NewGRPCPool(&pool.Options{
+			InitTargets:  []string{ep.addr},
+			InitCap:      5,
+			MaxCap:       30,
+			DialTimeout:  time.Second * 5,
+			IdleTimeout:  time.Second * 60,
+			ReadTimeout:  time.Second * 5,
+			WriteTimeout: time.Second * 5,
+		}, sp.dialOptions(ep.host)...)
+		if err != nil {
+			return fmt.Errorf("init rpc pool for %s: %v", ep.addr, err)
+		}
+		ep.pool = p
+	}
+	for _, o := range old {
+		level.Info(sp.logger).Log("msg", "server endpoint removed", "server", o.addr)
+		o.pool.Close()
+		ServerHealthyGaugeVec.DeleteLabelValues(o.addr)
+		ServerConnectedGaugeVec.DeleteLabelValues(o.addr)
+		if sp.current == o {
+			sp.current = nil
+		}
+	}
+	sp.endpoints = eps
+	if sp.current == nil && len(eps) > 0 {
+		sp.current = eps[0]
+	}
+	return nil
+}
+
+func (sp *ServerPool) healthCheck(ep *serverEndpoint) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), serverCheckTimeout)
+	defer cancel()
+	opts := append(sp.dialOptions(ep.host), grpc.WithBlock(), grpc.FailOnNonTempDialError(true))
+	conn, err := grpc.DialContext(ctx, ep.addr, opts...)
+	if err != nil {
+		level.Debug(sp.logger).Log("msg", "server health check failed", "server", ep.addr, "err", err)
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// check health checks every endpoint and switches to the first healthy one
+func (sp *ServerPool) check() {
+	sp.mux.Lock()
+	eps := append([]*serverEndpoint(nil), sp.endpoints...)
+	sp.mux.Unlock()
+
+	healthy := make([]bool, len(eps))
+	var wg sync.WaitGroup
+	for i, ep := range eps {
+		wg.Add(1)
+		go func(i int, ep *serverEndpoint) {
+			defer wg.Done()
+			healthy[i] = sp.healthCheck(ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	sp.mux.Lock()
+	defer sp.mux.Unlock()
+	var next *serverEndpoint
+	for i, ep := range eps {
+		if ep.healthy != healthy[i] {
+			level.Info(sp.logger).Log("msg", "server health changed", "server", ep.addr, "healthy", healthy[i])
+		}
+		ep.healthy = healthy[i]
+		ServerHealthyGaugeVec.WithLabelValues(ep.addr).Set(boolFloat(ep.healthy))
+		if next == nil && ep.healthy {
+			next = ep
+		}
+	}
+	if next == nil {
+		// nothing is healthy, stay on the current one and keep trying
+		return
+	}
+	if next != sp.current {
+		from := ""
+		if sp.current != nil {
+			from = sp.current.addr
+			ServerConnectedGaugeVec.WithLabelValues(from).Set(0)
+		}
+		level.Warn(sp.logger).Log("msg", "switch rpc server", "from", from, "to", next.addr)
+		sp.current = next
+	}
+	ServerConnectedGaugeVec.WithLabelValues(next.addr).Set(1)
+}
+
+func (sp *ServerPool) run() {
+	ticker := time.NewTicker(ServerCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := sp.refresh(); err != nil {
+				level.Error(sp.logger).Log("msg", "refresh server endpoints failed", "err", err)
+			}
+			sp.check()
+		case <-sp.quitChan:
+			return
+		}
+	}
+}
+
+// Get returns a connection to the current server, the caller closes it
+func (sp *ServerPool) Get() (*grpc.ClientConn, error) {
+	sp.mux.Lock()
+	cur := sp.current
+	sp.mux.Unlock()
+	if cur == nil {
+		return nil, fmt.Errorf("no server endpoint")
+	}
+	return cur.pool.Get()
+}
+
+// Current returns the address of the server rpcs are sent to
+func (sp *ServerPool) Current() string {
+	if sp == nil {
+		return ""
+	}
+	sp.mux.Lock()
+	defer sp.mux.Unlock()
+	if sp.current == nil {
+		return ""
+	}
+	return sp.current.addr
+}
+
+func (sp *ServerPool) Close() {
+	close(sp.quitChan)
+	sp.mux.Lock()
+	defer sp.mux.Unlock()
+	for _, ep := range sp.endpoints {
+		ep.pool.Close()
+	}
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SplitServerAddrs accepts repeated and comma separated addresses
+func SplitServerAddrs(addrs []string) []string {
+	var res []string
+	for _, a := range addrs {
+		for _, s := range strings.Split(a, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
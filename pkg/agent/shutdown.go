@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"xprober/pkg/pb"
+)
+
+const DefaultShutdownTimeout = 15 * time.Second
+
+// Shutdown runs after the agent context is done: it waits for in-flight
+// probes until deadline, pushes what they found, spooling it when the
+// server is unreachable, and deregisters so the server takes this agent out
+// of the target pool of its peers
+func Shutdown(logger log.Logger, deadline time.Time) {
+	if LTM != nil {
+		if LTM.Wait(deadline) {
+			level.Info(logger).Log("msg", "in-flight probes finished")
+		} else {
+			level.Warn(logger).Log("msg", "in-flight probes not finished before the shutdown deadline")
+		}
+	}
+	pushPbResults(logger)
+	deregisterAgent(logger)
+	if Spool != nil {
+		Spool.Close()
+	}
+	GrpcPool.Close()
+}
+
+func deregisterAgent(logger log.Logger) {
+	conn, err := GrpcPool.Get()
+	if err != nil {
+		level.Error(logger).Log("get_rpc_conn_from_pool_err", err)
+		return
+	}
+
+	defer conn.Close()
+	c := pb.NewProberAgentIpReportClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.ProberAgentDeregister(ctx, &pb.ProberAgentDeregisterRequest{Ip: LocalIp, Region: LocalRegion}); err != nil {
+		level.Error(logger).Log("msg", "could_not_deregister", "ip", LocalIp, "region", LocalRegion, "error:", err)
+		return
+	}
+	level.Info(logger).Log("msg", "agent deregistered", "ip", LocalIp, "region", LocalRegion)
+}
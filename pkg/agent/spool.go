@@ -0,0 +1,428 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+/*
+   disk spool of results the server did not take. every failed push is
+   appended as one record to the newest segment file, a record is a length
+   and crc32 header followed by a marshaled ProberResultPushRequest. while
+   anything is spooled new results are appended too, so the server receives
+   everything in probe order once it is reachable again. the oldest segments
+   are dropped beyond the size limit, results older than the age limit are
+   dropped on replay
+*/
+
+const (
+	spoolSegmentSuffix = `.spool`
+	spoolSegmentSize   = 4 << 20
+	spoolHeaderSize    = 8
+
+	DefaultSpoolMaxBytes = 256 << 20
+	DefaultSpoolMaxAge   = 24 * time.Hour
+
+	// results sent per replay rpc
+	spoolReplayBatch = 5000
+)
+
+var (
+	Spool *ResultSpool
+
+	SpoolBytesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: common.MetricsNameAgentSpoolBytes,
+		Help: "bytes of results waiting in the spool",
+	})
+	SpoolResultsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: common.MetricsNameAgentSpoolResults,
+		Help: "results waiting in the spool",
+	})
+	SpoolOldestGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: common.MetricsNameAgentSpoolOldest,
+		Help: "probe time of the oldest spooled result, 0 when empty",
+	})
+	SpoolWrittenCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameAgentSpoolWritten,
+		Help: "results written to the spool",
+	})
+	SpoolReplayedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameAgentSpoolReplayed,
+		Help: "spooled results pushed to the server",
+	})
+	SpoolDroppedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameAgentSpoolDropped,
+		Help: "spooled results dropped by the size or age limit or corruption",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(SpoolBytesGauge, SpoolResultsGauge, SpoolOldestGauge,
+		SpoolWrittenCounter, SpoolReplayedCounter, SpoolDroppedCounterVec)
+}
+
+type SpoolConfig struct {
+	Dir      string
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+type spoolSegment struct {
+	seq     uint64
+	path    string
+	size    int64
+	results int
+	minTs   int64
+	maxTs   int64
+	// bytes already replayed
+	readOff int64
+	// results already replayed
+	readResults int
+}
+
+type ResultSpool struct {
+	logger log.Logger
+	cfg    SpoolConfig
+
+	mux      sync.Mutex
+	segments []*spoolSegment
+	active   *os.File
+}
+
+func NewSpool(logger log.Logger, cfg SpoolConfig) (*ResultSpool, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultSpoolMaxBytes
+	}
+	if cfg.MaxBytes < spoolSegmentSize {
+		return nil, fmt.Errorf("spool max size must be at least %d bytes", spoolSegmentSize)
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultSpoolMaxAge
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &ResultSpool{
+		logger: log.With(logger, "component", "spool"),
+		cfg:    cfg,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open scans the segments left by a previous run
+func (s *ResultSpool) open() error {
+	files, err := filepath.Glob(filepath.Join(s.cfg.Dir, "*"+spoolSegmentSuffix))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(f), spoolSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := &spoolSegment{seq: seq, path: f}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		n, _ := readSpoolRecords(data, 0, -1, func(prs []*pb.ProberResultOne) {
+			seg.add(prs)
+		})
+		if n < int64(len(data)) {
+			// torn write of a crash, the rest is dropped on the next append
+			level.Warn(s.logger).Log("msg", "spool segment truncated", "file", f, "valid_bytes", n, "size", len(data))
+			if err := os.Truncate(f, n); err != nil {
+				return err
+			}
+		}
+		seg.size = n
+		if seg.results == 0 {
+			os.Remove(f)
+			continue
+		}
+		s.segments = append(s.segments, seg)
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	if len(s.segments) > 0 {
+		level.Info(s.logger).Log("msg", "spool opened with pending results", "segments", len(s.segments), "results", s.pendingResults())
+	}
+	s.updateMetrics()
+	return nil
+}
+
+func (seg *spoolSegment) add(prs []*pb.ProberResultOne) {
+	for _, r := range prs {
+		if seg.minTs == 0 || r.TimeStamp < seg.minTs {
+			seg.minTs = r.TimeStamp
+		}
+		if r.TimeStamp > seg.maxTs {
+			seg.maxTs = r.TimeStamp
+		}
+	}
+	seg.results += len(prs)
+}
+
+// readSpoolRecords calls fn for the records in data from off on, at most
+// maxResults results when not negative, and returns the offset read up to
+// and the number of results read
+func readSpoolRecords(data []byte, off int64, maxResults int, fn func(prs []*pb.ProberResultOne)) (int64, int) {
+	read := 0
+	for off+spoolHeaderSize <= int64(len(data)) {
+		if maxResults >= 0 && read >= maxResults {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(data[off:]))
+		sum := binary.BigEndian.Uint32(data[off+4:])
+		end := off + spoolHeaderSize + n
+		if end > int64(len(data)) || crc32.ChecksumIEEE(data[off+spoolHeaderSize:end]) != sum {
+			break
+		}
+		var req pb.ProberResultPushRequest
+		if err := req.Unmarshal(data[off+spoolHeaderSize : end]); err != nil {
+			break
+		}
+		fn(req.ProberResults)
+		read += len(req.ProberResults)
+		off = end
+	}
+	return off, read
+}
+
+// Empty reports whether nothing waits for replay
+func (s *ResultSpool) Empty() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.segments) == 0
+}
+
+// Append spools results as one record
+func (s *ResultSpool) Append(prs []*pb.ProberResultOne) error {
+	if len(prs) == 0 {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	req := pb.ProberResultPushRequest{ProberResults: prs}
+	body, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	var header [spoolHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(body)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(body))
+	buf.Write(header[:])
+	buf.Write(body)
+
+	seg := s.activeSegment()
+	if seg == nil || seg.size+int64(buf.Len()) > spoolSegmentSize {
+		if seg, err = s.newSegment(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.active.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := s.active.Sync(); err != nil {
+		return err
+	}
+	seg.size += int64(buf.Len())
+	seg.add(prs)
+	SpoolWrittenCounter.Add(float64(len(prs)))
+	s.enforceSize()
+	s.updateMetrics()
+	return nil
+}
+
+// activeSegment is the newest segment when it is open for writing
+func (s *ResultSpool) activeSegment() *spoolSegment {
+	if s.active == nil || len(s.segments) == 0 {
+		return nil
+	}
+	return s.segments[len(s.segments)-1]
+}
+
+func (s *ResultSpool) newSegment() (*spoolSegment, error) {
+	if s.active != nil {
+		s.active.Close()
+		s.active = nil
+	}
+	seq := uint64(time.Now().UnixNano())
+	if n := len(s.segments); n > 0 && seq <= s.segments[n-1].seq {
+		seq = s.segments[n-1].seq + 1
+	}
+	path := filepath.Join(s.cfg.Dir, fmt.Sprintf("%020d%s", seq, spoolSegmentSuffix))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &spoolSegment{seq: seq, path: path}
+	s.segments = append(s.segments, seg)
+	s.active = f
+	return seg, nil
+}
+
+// enforceSize drops the oldest segments beyond the size limit
+func (s *ResultSpool) enforceSize() {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size - seg.readOff
+	}
+	for total > s.cfg.MaxBytes && len(s.segments) > 1 {
+		seg := s.segments[0]
+		total -= seg.size - seg.readOff
+		level.Warn(s.logger).Log("msg", "spool full, dropping oldest segment", "file", seg.path, "results", seg.results-seg.readResults)
+		s.removeSegment(seg, "size")
+	}
+}
+
+// removeSegment must be called with mux held, reason counts the unreplayed
+// results as dropped when not empty
+func (s *ResultSpool) removeSegment(seg *spoolSegment, reason string) {
+	if reason != "" {
+		SpoolDroppedCounterVec.WithLabelValues(reason).Add(float64(seg.results - seg.readResults))
+	}
+	if s.activeSegment() == seg {
+		s.active.Close()
+		s.active = nil
+	}
+	os.Remove(seg.path)
+	for i, x := range s.segments {
+		if x == seg {
+			s.segments = append(s.segments[:i], s.segments[i+1:]...)
+			break
+		}
+	}
+}
+
+// Replay pushes spooled results oldest first until the spool is empty, a
+// push fails or the deadline passes
+func (s *ResultSpool) Replay(push func(prs []*pb.ProberResultOne) error, deadline time.Time) error {
+	for time.Now().Before(deadline) {
+		prs, commit, err := s.next(spoolReplayBatch)
+		if err != nil {
+			return err
+		}
+		if commit == nil {
+			return nil
+		}
+		if len(prs) > 0 {
+			if err := push(prs); err != nil {
+				return err
+			}
+			SpoolReplayedCounter.Add(float64(len(prs)))
+		}
+		commit()
+	}
+	return nil
+}
+
+// next reads up to maxResults from the oldest segment, commit marks them
+// replayed, a nil commit means the spool is empty
+func (s *ResultSpool) next(maxResults int) ([]*pb.ProberResultOne, func(), error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	minTs := time.Now().Add(-s.cfg.MaxAge).Unix()
+	for len(s.segments) > 0 {
+		seg := s.segments[0]
+		if seg.maxTs < minTs {
+			level.Warn(s.logger).Log("msg", "dropping expired spool segment", "file", seg.path, "results", seg.results-seg.readResults)
+			s.removeSegment(seg, "age")
+			continue
+		}
+		if seg.readOff >= seg.size {
+			s.removeSegment(seg, "")
+			continue
+		}
+		data, err := ioutil.ReadFile(seg.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64(len(data)) > seg.size {
+			data = data[:seg.size]
+		}
+		var prs []*pb.ProberResultOne
+		expired := 0
+		off, read := readSpoolRecords(data, seg.readOff, maxResults, func(rs []*pb.ProberResultOne) {
+			for _, r := range rs {
+				if r.TimeStamp < minTs {
+					expired++
+					continue
+				}
+				prs = append(prs, r)
+			}
+		})
+		if read == 0 {
+			level.Error(s.logger).Log("msg", "unreadable spool segment dropped", "file", seg.path, "offset", seg.readOff)
+			s.removeSegment(seg, "corrupt")
+			continue
+		}
+		commit := func() {
+			s.mux.Lock()
+			defer s.mux.Unlock()
+			SpoolDroppedCounterVec.WithLabelValues("age").Add(float64(expired))
+			seg.readOff = off
+			seg.readResults += read
+			// a drained active segment is removed too, appends start a new one
+			if seg.readOff >= seg.size {
+				s.removeSegment(seg, "")
+			}
+			s.updateMetrics()
+		}
+		return prs, commit, nil
+	}
+	s.updateMetrics()
+	return nil, nil, nil
+}
+
+func (s *ResultSpool) pendingResults() int {
+	n := 0
+	for _, seg := range s.segments {
+		n += seg.results - seg.readResults
+	}
+	return n
+}
+
+// updateMetrics must be called with mux held
+func (s *ResultSpool) updateMetrics() {
+	var size int64
+	for _, seg := range s.segments {
+		size += seg.size - seg.readOff
+	}
+	SpoolBytesGauge.Set(float64(size))
+	SpoolResultsGauge.Set(float64(s.pendingResults()))
+	oldest := int64(0)
+	if len(s.segments) > 0 {
+		oldest = s.segments[0].minTs
+	}
+	SpoolOldestGauge.Set(float64(oldest))
+}
+
+func (s *ResultSpool) Close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.active != nil {
+		s.active.Close()
+		s.active = nil
+	}
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"xprober/pkg/pb"
+)
+
+func openTestSpool(t *testing.T, dir string, maxBytes int64, maxAge time.Duration) *ResultSpool {
+	s, err := NewSpool(log.NewNopLogger(), SpoolConfig{Dir: dir, MaxBytes: maxBytes, MaxAge: maxAge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// spoolBatch returns n results numbered from first on
+func spoolBatch(first uint64, n int, ts int64, pad int) []*pb.ProberResultOne {
+	addr := strings.Repeat("a", pad)
+	prs := make([]*pb.ProberResultOne, n)
+	for i := range prs {
+		prs[i] = &pb.ProberResultOne{Seq: first + uint64(i), TimeStamp: ts, TargetAddr: addr}
+	}
+	return prs
+}
+
+func replayAll(t *testing.T, s *ResultSpool) []uint64 {
+	var seqs []uint64
+	err := s.Replay(func(prs []*pb.ProberResultOne) error {
+		for _, r := range prs {
+			seqs = append(seqs, r.Seq)
+		}
+		return nil
+	}, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Empty() {
+		t.Fatalf("spool not empty after replay")
+	}
+	return seqs
+}
+
+func checkSeqs(t *testing.T, got []uint64, first uint64, n int) {
+	if len(got) != n {
+		t.Fatalf("replayed %d results, want %d", len(got), n)
+	}
+	for i, seq := range got {
+		if seq != first+uint64(i) {
+			t.Fatalf("result %d has seq %d, want %d", i, seq, first+uint64(i))
+		}
+	}
+}
+
+func TestSpoolCorruptTailRecovery(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Unix()
+	s := openTestSpool(t, dir, 0, 0)
+	for i := 0; i < 3; i++ {
+		if err := s.Append(spoolBatch(uint64(i*10+1), 10, now, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentSuffix))
+	if len(files) != 1 {
+		t.Fatalf("got %d segments, want 1", len(files))
+	}
+	fi, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := fi.Size()
+	// a record torn by a crash: full header, half the body
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 1, 0, 1, 2, 3, 4, 5, 6, 7})
+	f.Close()
+
+	corrupt := testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("corrupt"))
+	s = openTestSpool(t, dir, 0, 0)
+	defer s.Close()
+	if fi, _ := os.Stat(files[0]); fi.Size() != valid {
+		t.Fatalf("segment is %d bytes after reopen, want the valid %d", fi.Size(), valid)
+	}
+	if n := s.pendingResults(); n != 30 {
+		t.Fatalf("%d results pending after reopen, want 30", n)
+	}
+	if err := s.Append(spoolBatch(31, 10, now, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkSeqs(t, replayAll(t, s), 1, 40)
+	if n := testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("corrupt")) - corrupt; n != 0 {
+		t.Fatalf("%v results counted corrupt, want 0", n)
+	}
+}
+
+func TestSpoolEvictsOldestFirst(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Unix()
+	s := openTestSpool(t, dir, spoolSegmentSize, time.Hour)
+	defer s.Close()
+
+	sizeDropped := testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("size"))
+	// about 1MB per record, four records per segment
+	const perBatch = 1000
+	for i := 0; i < 10; i++ {
+		if err := s.Append(spoolBatch(uint64(i*perBatch+1), perBatch, now, 1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dropped := int(testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("size")) - sizeDropped)
+	if dropped == 0 || dropped%(4*perBatch) != 0 {
+		t.Fatalf("%d results dropped by size, want whole segments", dropped)
+	}
+	seqs := replayAll(t, s)
+	// only the oldest segments went, the rest comes in order
+	checkSeqs(t, seqs, uint64(dropped+1), 10*perBatch-dropped)
+
+	ageDropped := testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("age"))
+	old := now - int64(2*time.Hour/time.Second)
+	if err := s.Append(spoolBatch(1, 5, old, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(spoolBatch(6, 5, now, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkSeqs(t, replayAll(t, s), 6, 5)
+	if n := testutil.ToFloat64(SpoolDroppedCounterVec.WithLabelValues("age")) - ageDropped; n != 5 {
+		t.Fatalf("%v results dropped by age, want 5", n)
+	}
+}
+
+func TestSpoolReplayOrderAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Unix()
+	s := openTestSpool(t, dir, 0, 0)
+	if err := s.Append(spoolBatch(1, 10, now, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkSeqs(t, replayAll(t, s), 1, 10)
+
+	for _, first := range []uint64{11, 21} {
+		if err := s.Append(spoolBatch(first, 10, now, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the server is still down, nothing is marked replayed
+	err := s.Replay(func(prs []*pb.ProberResultOne) error { return errors.New("server unavailable") }, time.Now().Add(time.Minute))
+	if err == nil {
+		t.Fatalf("replay with a failing push returned no error")
+	}
+	s.Close()
+
+	s = openTestSpool(t, dir, 0, 0)
+	defer s.Close()
+	if err := s.Append(spoolBatch(31, 10, now, 0)); err != nil {
+		t.Fatal(err)
+	}
+	checkSeqs(t, replayAll(t, s), 11, 30)
+}
@@ -1,12 +1,17 @@
 package agent
 
 import (
+	"context"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 
+	"xprober/pkg/otlp"
+	"xprober/pkg/common"
 	"xprober/pkg/pb"
 )
 
@@ -14,9 +19,15 @@ var (
 	TargetCache        = sync.Map{}
 	PbResMap           = sync.Map{}
 	Probers            map[string]ProbeFn
+	AdhocProbers       map[string]ProbeFn
 	LTM                *LocalTargetManger
 	ProberFuncInterval = 15 * time.Second
 	TargetUpdateChan   = make(chan *pb.ProberTargetsGetResponse, 1)
+	OtlpExporter       *otlp.Exporter
+
+	// the result sequence restarts with the agent, SeqEpoch tells the runs apart
+	SeqEpoch  = time.Now().UnixNano()
+	resultSeq uint64
 )
 
 //type ProbeFn func(ctx context.Context, lt *LocalTarget, logger log.Logger) pb.ProberResultOne
@@ -26,6 +37,10 @@ type LocalTargetManger struct {
 	logger log.Logger
 	mux    sync.RWMutex
 	Map    map[string]*LocalTarget
+	// local targets run until ctx is done
+	ctx context.Context
+	// running local targets, to wait for in-flight probes on shutdown
+	wg sync.WaitGroup
 }
 
 func (ltm *LocalTargetManger) GetMapKeys() []string {
@@ -45,6 +60,10 @@ func (ltm *LocalTargetManger) realRefreshWork(tgs *pb.ProberTargetsGetResponse)
 	level.Info(ltm.logger).Log("msg", "realRefreshWork start")
 	LTM.mux.Lock()
 	defer LTM.mux.Unlock()
+	if LTM.ctx.Err() != nil {
+		// shutting down
+		return
+	}
 	remoteTargetIds := make(map[string]bool)
 
 	localIds := LTM.GetMapKeys()
@@ -55,12 +74,17 @@ func (ltm *LocalTargetManger) realRefreshWork(tgs *pb.ProberTargetsGetResponse)
 		}
 
 		for _, addr := range t.Target {
+			if err := common.ValidateTarget(t.ProberType, addr); err != nil {
+				level.Warn(LTM.logger).Log("msg", "skip invalid target", "err", err)
+				continue
+			}
 			thisId := t.Region + addr + t.ProberType
 			remoteTargetIds[thisId] = true
 			if _, ok := LTM.Map[thisId]; ok {
 				continue
 			}
 
+			ctx, cancel := context.WithCancel(LTM.ctx)
 			nt := &LocalTarget{
 				logger:       LTM.logger,
 				Addr:         addr,
@@ -68,9 +92,11 @@ func (ltm *LocalTargetManger) realRefreshWork(tgs *pb.ProberTargetsGetResponse)
 				TargetRegion: t.Region,
 				ProbeType:    t.ProberType,
 				Prober:       pbFunc,
-				QuitChan:     make(chan struct{}),
+				ctx:          ctx,
+				cancel:       cancel,
 			}
 			LTM.Map[thisId] = nt
+			LTM.wg.Add(1)
 			go nt.Start()
 
 		}
@@ -81,16 +107,37 @@ func (ltm *LocalTargetManger) realRefreshWork(tgs *pb.ProberTargetsGetResponse)
 		if _, found := remoteTargetIds[key]; !found {
 			LTM.Map[key].Stop()
 			delete(LTM.Map, key)
+			evictResults(key)
 		}
 	}
 
 }
 
-func NewLocalTargetManger(logger log.Logger) {
+func NewLocalTargetManger(ctx context.Context, logger log.Logger) {
 	localM := make(map[string]*LocalTarget)
 	LTM = &LocalTargetManger{}
 	LTM.logger = logger
 	LTM.Map = localM
+	LTM.ctx = ctx
+}
+
+// Wait waits for the local targets to finish their in-flight probes after
+// ctx is done, it reports false when the deadline passed first
+func (ltm *LocalTargetManger) Wait(deadline time.Time) bool {
+	// a refresh running right now starts its targets before we wait
+	ltm.mux.Lock()
+	ltm.mux.Unlock()
+	done := make(chan struct{})
+	go func() {
+		ltm.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Until(deadline)):
+		return false
+	}
 }
 
 type LocalTarget struct {
@@ -101,56 +148,84 @@ type LocalTarget struct {
 
 	ProbeType string
 	Prober    ProbeFn
-	QuitChan  chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// set when the target was removed from the pool, not on shutdown
+	removed int32
+
+	// nanoseconds the last probe took, read by the inventory report
+	lastProbeNanos int64
 }
 
-func PushWork(logger log.Logger) {
+// PushWork pushes results until ctx is done, the final push is left to
+// Shutdown
+func PushWork(ctx context.Context, logger log.Logger) {
 
 	for {
 		pushPbResults(logger)
 
-		time.Sleep(PushInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(PushInterval):
+		}
 	}
 }
-func ReportIp(logger log.Logger) {
+func ReportIp(ctx context.Context, logger log.Logger) {
 
 	for {
-		reportAgentIp(logger)
+		reportAgentIp(ctx, logger)
 
-		time.Sleep(ReportInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ReportInterval):
+		}
 	}
 
 }
 
-func RefreshTarget(logger log.Logger) {
-	go doRefreshWork(logger)
+func RefreshTarget(ctx context.Context, logger log.Logger) {
+	go doRefreshWork(ctx, logger)
 	level.Info(logger).Log("msg", "RefreshTarget start", )
 	for {
 
-		getProberTarget(logger)
-		time.Sleep(RefreshInterval)
+		getProberTarget(ctx, logger)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(RefreshInterval):
+		}
 	}
 
 }
 
-func doRefreshWork(logger log.Logger) {
+func doRefreshWork(ctx context.Context, logger log.Logger) {
 	for {
 		select {
 		case tgs := <-TargetUpdateChan:
 			// refresh local map
 			LTM.realRefreshWork(tgs)
-
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
-func Init(logger log.Logger) {
+func Init(ctx context.Context, logger log.Logger) {
 	Probers = map[string]ProbeFn{
 		"http": ProbeHTTP,
 		"icmp": ProbeICMP,
 		//"icmp": ProbeHTTP,
 	}
-	NewLocalTargetManger(logger)
+	// tcp has no server side storage, it is only probed on demand
+	AdhocProbers = map[string]ProbeFn{
+		"tcp": ProbeTCP,
+	}
+	for k, fn := range Probers {
+		AdhocProbers[k] = fn
+	}
+	NewLocalTargetManger(ctx, logger)
 }
 
 func (lt *LocalTarget) Uid() string {
@@ -159,18 +234,29 @@ func (lt *LocalTarget) Uid() string {
 }
 
 func (lt *LocalTarget) Start() {
+	defer LTM.wg.Done()
 	ticker := time.NewTicker(ProberFuncInterval)
 	level.Info(lt.logger).Log("msg", "LocalTarget probe start....", "uid", lt.Uid())
 	defer ticker.Stop()
 	for {
 		select {
-		case <-lt.QuitChan:
+		case <-lt.ctx.Done():
 			level.Info(lt.logger).Log("msg", "receive_quit_signal", "uid", lt.Uid())
 			return
 		case <-ticker.C:
+			start := time.Now()
 			res := lt.Prober(lt)
+			atomic.StoreInt64(&lt.lastProbeNanos, int64(time.Since(start)))
 			if len(res) > 0 {
+				if atomic.LoadInt32(&lt.removed) == 1 {
+					// removed while probing, its results were evicted
+					return
+				}
+				// numbered only when handed to the push buffer, a dropped
+				// probe must not leave a gap the server counts as lost
+				numberResults(res, start)
 				PbResMap.Store(lt.Uid(), res)
+				recordOtlp(res)
 			}
 
 		}
@@ -179,6 +265,57 @@ func (lt *LocalTarget) Start() {
 
 }
 
+// numberResults gives every result the next agent sequence, the server
+// drops results it already has by it
+func numberResults(res []*pb.ProberResultOne, start time.Time) {
+	for _, r := range res {
+		r.Seq = atomic.AddUint64(&resultSeq, 1)
+		r.SeqEpoch = SeqEpoch
+		r.ProbeStart = start.UnixNano()
+	}
+}
+
+// Stop removes the target, results of a probe still running are dropped
 func (lt *LocalTarget) Stop() {
-	close(lt.QuitChan)
+	atomic.StoreInt32(&lt.removed, 1)
+	lt.cancel()
+}
+
+// recordOtlp emits every probe measurement directly when otlp export is enabled
+func recordOtlp(res []*pb.ProberResultOne) {
+	if OtlpExporter == nil {
+		return
+	}
+	for _, r := range res {
+		OtlpExporter.Record(r.MetricName, map[string]string{
+			"worker_name":   r.WorkerName,
+			"source_region": r.SourceRegion,
+			"target_region": r.TargetRegion,
+			"target_addr":   r.TargetAddr,
+			"probe_type":    r.ProbeType,
+		}, float64(r.Value), time.Unix(r.TimeStamp, 0))
+	}
+}
+
+// Inventory returns the local target count and the longest last probe
+// duration, targets probe in parallel so that is the probe cycle duration
+func (ltm *LocalTargetManger) Inventory() (targetNum int, cycle time.Duration) {
+	ltm.mux.RLock()
+	defer ltm.mux.RUnlock()
+	for _, lt := range ltm.Map {
+		if d := time.Duration(atomic.LoadInt64(&lt.lastProbeNanos)); d > cycle {
+			cycle = d
+		}
+	}
+	return len(ltm.Map), cycle
+}
+
+// ProbeTypes returns the sorted names of the registered Probers
+func ProbeTypes() []string {
+	res := make([]string, 0, len(Probers))
+	for k := range Probers {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
 }
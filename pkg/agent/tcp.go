@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const tcpDialTimeout = 5 * time.Second
+
+// ProbeTCP connects to addr as host:port, success is 1 or -1 like ping
+func ProbeTCP(lt *LocalTarget) []*pb.ProberResultOne {
+	level.Info(lt.logger).Log("msg", "LocalTarget  ProbeTCP start ...", "uid", lt.Uid())
+	prSu := pb.ProberResultOne{
+		MetricName:   common.MetricsNameTcpConnectSuccess,
+		WorkerName:   LocalIp,
+		TargetAddr:   lt.Addr,
+		SourceRegion: LocalRegion,
+		TargetRegion: lt.TargetRegion,
+		ProbeType:    lt.ProbeType,
+		TimeStamp:    time.Now().Unix(),
+		Value:        -1,
+	}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", lt.Addr, tcpDialTimeout)
+	if err != nil {
+		level.Error(lt.logger).Log("msg", "ProbeTCP failed ...", "uid", lt.Uid(), "err", err)
+		return []*pb.ProberResultOne{&prSu}
+	}
+	duration := time.Since(start)
+	conn.Close()
+	prSu.Value = 1
+	prConn := pb.ProberResultOne{
+		MetricName:   common.MetricsNameTcpConnectDurationMillonseconds,
+		WorkerName:   LocalIp,
+		TargetAddr:   lt.Addr,
+		SourceRegion: LocalRegion,
+		TargetRegion: lt.TargetRegion,
+		ProbeType:    lt.ProbeType,
+		TimeStamp:    time.Now().Unix(),
+		Value:        float32(duration.Seconds() * 1000),
+	}
+	return []*pb.ProberResultOne{&prSu, &prConn}
+}
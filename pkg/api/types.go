@@ -0,0 +1,55 @@
+package api
+
+/*
+   json bodies of the server's query api, shared by the server and xprober-ctl
+*/
+
+// MatrixCell is the aggregate of all samples of one source/target pair
+type MatrixCell struct {
+	SourceRegion string   `json:"source_region"`
+	TargetRegion string   `json:"target_region,omitempty"`
+	Addr         string   `json:"addr,omitempty"`
+	Value        float64  `json:"value"`
+	TimeStamp    int64    `json:"timestamp"`
+	AgentNum     int      `json:"agent_num"`
+	SampleNum    int      `json:"sample_num"`
+	Agents       []string `json:"agents,omitempty"`
+}
+
+type MatrixResponse struct {
+	Metric  string                            `json:"metric"`
+	Regions []string                          `json:"regions"`
+	Matrix  map[string]map[string]*MatrixCell `json:"matrix"`
+}
+
+type HttpTargetResponse struct {
+	SourceRegion string                 `json:"source_region"`
+	Addr         string                 `json:"addr"`
+	Metrics      map[string]*MatrixCell `json:"metrics"`
+}
+
+// AgentInfo is what the server knows about one agent, keyed by ip, the
+// inventory part comes from the agent's own periodic report
+type AgentInfo struct {
+	Ip             string `json:"ip"`
+	Region         string `json:"region"`
+	LastReport     int64  `json:"last_report"`
+	LastTargetsGet int64  `json:"last_targets_get"`
+	LastPush       int64  `json:"last_push"`
+
+	Version               string   `json:"version"`
+	Hostname              string   `json:"hostname"`
+	InstanceId            string   `json:"instance_id"`
+	ProbeTypes            []string `json:"probe_types"`
+	TargetNum             int32    `json:"target_num"`
+	LastProbeCycleSeconds float64  `json:"last_probe_cycle_seconds"`
+	ReportedLastPush      int64    `json:"reported_last_push"`
+	ConnectedServer       string   `json:"connected_server"`
+	// probe time of the newest result received from the agent
+	NewestResult int64 `json:"newest_result"`
+	// unix time the agent deregistered, it is gone unless it reported after
+	Deregistered int64 `json:"deregistered,omitempty"`
+
+	// verified client certificate names, empty without mtls
+	CertIdentity string `json:"cert_identity,omitempty"`
+}
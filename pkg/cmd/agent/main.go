@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -9,17 +12,39 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/promlog"
 	promlogflag "github.com/prometheus/common/promlog/flag"
 	"github.com/prometheus/common/version"
+	"google.golang.org/grpc/credentials"
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"xprober/pkg/agent"
+	"xprober/pkg/otlp"
+	"xprober/pkg/tlsutil"
 )
 
 var (
 	app               = kingpin.New(filepath.Base(os.Args[0]), "The xprober-agent")
-	grpcServerAddress = app.Flag("grpc.server-address", "server addr").Default(":6001").String()
+	grpcServerAddress = app.Flag("grpc.server-address", "server addr, repeat or separate with commas for failover, a name resolving to several ips is expanded").Default(":6001").Strings()
+	grpcTls           = app.Flag("grpc.tls", "connect to the server over tls").Bool()
+	grpcTlsCAFile     = app.Flag("grpc.tls-ca-file", "ca to verify the server certificate, system pool when empty").Default("").String()
+	grpcTlsCertFile   = app.Flag("grpc.tls-cert-file", "client certificate for mtls").Default("").String()
+	grpcTlsKeyFile    = app.Flag("grpc.tls-key-file", "client key for mtls").Default("").String()
+	grpcTlsServerName = app.Flag("grpc.tls-server-name", "server name to verify, host of the server address when empty").Default("").String()
+	grpcToken         = app.Flag("grpc.token", "token sent to the server on every rpc").Envar("XPROBER_AGENT_TOKEN").Default("").String()
+	spoolDir          = app.Flag("spool.dir", "directory to keep results the server did not take, empty to disable").Default("").String()
+	spoolMaxSize      = app.Flag("spool.max-size", "size limit of the spool, the oldest results are dropped beyond it").Default("256MB").Bytes()
+	spoolMaxAge       = app.Flag("spool.max-age", "spooled results older than this are dropped").Default("24h").Duration()
+	pushBatchSize     = app.Flag("push.batch-size", "results per message when pushing to the server").Default("1000").Int()
+	pushGzip          = app.Flag("push.gzip", "gzip pushed results, --no-push.gzip to disable").Default("true").Bool()
+	shutdownTimeout   = app.Flag("shutdown.timeout", "time to wait for in-flight probes on SIGTERM before the final push").Default(agent.DefaultShutdownTimeout.String()).Duration()
+	otlpEndpoint      = app.Flag("otlp.endpoint", "otlp collector endpoint, empty to disable").Default("").String()
+	otlpProtocol      = app.Flag("otlp.protocol", "otlp protocol: grpc or http").Default(otlp.ProtocolGrpc).String()
+	otlpInsecure      = app.Flag("otlp.insecure", "disable tls to the otlp collector").Bool()
+	otlpInterval      = app.Flag("otlp.interval", "otlp export interval").Default("30s").Duration()
+	webListenAddress  = app.Flag("web.listen-address", "address to expose the agent metrics on /metrics, empty to disable").Default(":6003").String()
 )
 
 func main() {
@@ -71,22 +96,81 @@ func main() {
 		level.Error(logger).Log("msg", "failed_to_get_ip_exit...")
 		return
 	}
-	level.Info(logger).Log("msg", "agent_metadata", "ip", agent.LocalIp, "region", agent.LocalRegion)
+	agent.GetLocalInventory(logger)
+	level.Info(logger).Log("msg", "agent_metadata", "ip", agent.LocalIp, "region", agent.LocalRegion, "hostname", agent.LocalHostname, "instance_id", agent.LocalInstanceId)
 	// init rpc pool
-	//ctx, cancelAll := context.WithCancel(context.Background())
-	isSuccess := agent.InitRpcPool(*grpcServerAddress, logger)
+	var creds credentials.TransportCredentials
+	if *grpcTls {
+		r, err := tlsutil.NewReloader(logger, tlsutil.Files{CAFile: *grpcTlsCAFile, CertFile: *grpcTlsCertFile, KeyFile: *grpcTlsKeyFile})
+		if err != nil {
+			level.Error(logger).Log("msg", "init_grpc_tls_failed_and_exit", "err", err)
+			os.Exit(1)
+		}
+		creds = r.ClientCredentials(*grpcTlsServerName)
+	}
+	if *grpcToken != "" && !*grpcTls {
+		level.Warn(logger).Log("msg", "grpc_token_sent_without_tls")
+	}
+	agent.RpcToken = *grpcToken
+	agent.PushBatchSize = *pushBatchSize
+	agent.PushGzip = *pushGzip
+	isSuccess := agent.InitRpcPool(agent.SplitServerAddrs(*grpcServerAddress), creds, logger)
 	if isSuccess == false {
 		level.Error(logger).Log("msg", "init_rpc_pool_failed_and_exit")
 		os.Exit(1)
 	}
-	level.Info(logger).Log("msg", "init_rpc_pool_success")
-	// report ip
-
-	go agent.ReportIp(logger)
+	level.Info(logger).Log("msg", "init_rpc_pool_success", "server", agent.GrpcPool.Current())
+	ctxAll, cancelAll := context.WithCancel(context.Background())
+	if *otlpEndpoint != "" {
+		e, err := otlp.NewExporter(logger, otlp.Config{
+			Endpoint: *otlpEndpoint,
+			Protocol: *otlpProtocol,
+			Insecure: *otlpInsecure,
+			Interval: *otlpInterval,
+			Resource: map[string]string{"xprober.region": agent.LocalRegion, "xprober.ip": agent.LocalIp},
+		}, "xprober-agent", prometheus.DefaultGatherer)
+		if err != nil {
+			level.Error(logger).Log("msg", "init_otlp_exporter_failed_and_exit", "err", err)
+			os.Exit(1)
+		}
+		agent.OtlpExporter = e
+		go e.Run(ctxAll)
+	}
+	if *spoolDir != "" {
+		s, err := agent.NewSpool(logger, agent.SpoolConfig{Dir: *spoolDir, MaxBytes: int64(*spoolMaxSize), MaxAge: *spoolMaxAge})
+		if err != nil {
+			level.Error(logger).Log("msg", "init_spool_failed_and_exit", "err", err)
+			os.Exit(1)
+		}
+		agent.Spool = s
+	}
+	if *webListenAddress != "" {
+		l, err := net.Listen("tcp", *webListenAddress)
+		if err != nil {
+			level.Error(logger).Log("msg", "listen_web_address_failed_and_exit", "address", *webListenAddress, "err", err)
+			os.Exit(1)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		srv := &http.Server{Handler: mux}
+		level.Info(logger).Log("msg", "Listening on address", "address", *webListenAddress)
+		go func() {
+			if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+				level.Error(logger).Log("msg", "web_server_error", "err", err)
+			}
+		}()
+		go func() {
+			<-ctxAll.Done()
+			srv.Close()
+		}()
+	}
+	agent.Init(ctxAll, logger)
+	// report ip and inventory
+	go agent.ReportIp(ctxAll, logger)
 	// refresh target
-	agent.Init(logger)
-	go agent.RefreshTarget(logger)
-	go agent.PushWork(logger)
+	go agent.RefreshTarget(ctxAll, logger)
+	go agent.PushWork(ctxAll, logger)
+	go agent.AdhocWork(ctxAll, logger)
 
 	// term handler
 	term := make(chan os.Signal, 1)
@@ -95,7 +179,9 @@ func main() {
 		select {
 		case <-term:
 			level.Info(logger).Log("msg", "Received SIGTERM, exiting gracefully...")
-			agent.GrpcPool.Close()
+			// stops the local targets, then flush and deregister
+			cancelAll()
+			agent.Shutdown(logger, time.Now().Add(*shutdownTimeout))
 			return
 		}
 	}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/common/version"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"xprober/pkg/common"
+	"xprober/pkg/ctl"
+	"xprober/pkg/pb"
+)
+
+func main() {
+
+	var (
+		app        = kingpin.New(filepath.Base(os.Args[0]), "The xprober command-line client")
+		serverAddr = app.Flag("server", "xprober-server http address (metrics_listen_addr).").Default("http://127.0.0.1:6002").Envar("XPROBER_SERVER").String()
+		token      = app.Flag("token", "admin api token.").Envar("XPROBER_ADMIN_TOKEN").String()
+		timeout    = app.Flag("timeout", "request timeout.").Default("10s").Duration()
+
+		agentsCmd = app.Command("agents", "List agents with region and last seen time.")
+
+		targetsCmd    = app.Command("targets", "Show the targets assigned to an agent.")
+		targetsAgent  = targetsCmd.Arg("agent-ip", "agent ip.").String()
+		targetsRegion = targetsCmd.Flag("region", "show targets for a region instead of an agent.").String()
+
+		matrixCmd    = app.Command("matrix", "Print the live region by region matrix.")
+		matrixMetric = matrixCmd.Flag("metric", "ping metric name.").Default(common.MetricsNamePingLatency).String()
+
+		samplesCmd    = app.Command("samples", "Show the raw samples of a region pair.")
+		samplesSource = samplesCmd.Arg("source-region", "source region.").Required().String()
+		samplesTarget = samplesCmd.Arg("target-region", "target region.").Required().String()
+		samplesMetric = samplesCmd.Flag("metric", "only this metric.").String()
+
+		httpCmd    = app.Command("http", "Show http target results per source region.")
+		httpSource = httpCmd.Flag("source-region", "only this source region.").String()
+
+		groupCmd        = app.Command("group", "Manage target groups through the admin api.")
+		groupListCmd    = groupCmd.Command("list", "List file and admin target groups.")
+		groupSetCmd     = groupCmd.Command("set", "Create or replace an admin target group.")
+		groupSetName    = groupSetCmd.Arg("name", "group name.").Required().String()
+		groupSetType    = groupSetCmd.Flag("type", "prober type.").Required().String()
+		groupSetRegion  = groupSetCmd.Flag("region", "target region.").Required().String()
+		groupSetTargets = groupSetCmd.Arg("target", "targets.").Strings()
+		groupDeleteCmd  = groupCmd.Command("delete", "Delete an admin target group.")
+		groupDeleteName = groupDeleteCmd.Arg("name", "group name.").Required().String()
+
+		targetCmd           = app.Command("target", "Add or remove targets of an admin target group.")
+		targetAddCmd        = targetCmd.Command("add", "Add targets to a group.")
+		targetAddGroup      = targetAddCmd.Arg("group", "group name.").Required().String()
+		targetAddTargets    = targetAddCmd.Arg("target", "targets.").Required().Strings()
+		targetRemoveCmd     = targetCmd.Command("remove", "Remove targets from a group.")
+		targetRemoveGroup   = targetRemoveCmd.Arg("group", "group name.").Required().String()
+		targetRemoveTargets = targetRemoveCmd.Arg("target", "targets.").Required().Strings()
+
+		probeCmd          = app.Command("probe", "Run a one-shot probe on the connected agents now.")
+		probeType         = probeCmd.Arg("prober-type", "prober type, icmp http or tcp.").Required().String()
+		probeTarget       = probeCmd.Arg("target", "target, host:port for tcp.").Required().String()
+		probeSource       = probeCmd.Flag("source-region", "only agents of this region.").String()
+		probeAgent        = probeCmd.Flag("agent", "only this agent ip.").String()
+		probeTargetRegion = probeCmd.Flag("target-region", "target region label of the results.").String()
+		probeWait         = probeCmd.Flag("wait", "max time to wait for the agents.").Default("30s").Duration()
+
+		dashboardCmd        = app.Command("dashboard", "Print a grafana dashboard json for the server metrics.")
+		dashboardTitle      = dashboardCmd.Flag("title", "dashboard title.").Default("xprober").String()
+		dashboardDatasource = dashboardCmd.Flag("datasource", "default prometheus datasource name.").Default("Prometheus").String()
+	)
+
+	app.Version(version.Print("xprober-ctl"))
+	app.HelpFlag.Short('h')
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	if cmd == probeCmd.FullCommand() && *timeout < *probeWait+5*time.Second {
+		*timeout = *probeWait + 5*time.Second
+	}
+	c := ctl.NewClient(*serverAddr, *token, *timeout)
+	var err error
+	switch cmd {
+	case agentsCmd.FullCommand():
+		err = ctl.Agents(c, os.Stdout)
+	case targetsCmd.FullCommand():
+		if *targetsAgent == "" && *targetsRegion == "" {
+			app.Fatalf("agent-ip or --region is required")
+		}
+		err = ctl.Targets(c, os.Stdout, *targetsAgent, *targetsRegion)
+	case matrixCmd.FullCommand():
+		err = ctl.Matrix(c, os.Stdout, *matrixMetric)
+	case samplesCmd.FullCommand():
+		err = ctl.Samples(c, os.Stdout, *samplesSource, *samplesTarget, *samplesMetric)
+	case httpCmd.FullCommand():
+		err = ctl.Http(c, os.Stdout, *httpSource)
+	case groupListCmd.FullCommand():
+		err = ctl.GroupList(c, os.Stdout)
+	case groupSetCmd.FullCommand():
+		err = ctl.GroupSet(c, *groupSetName, *groupSetType, *groupSetRegion, *groupSetTargets)
+	case groupDeleteCmd.FullCommand():
+		err = ctl.GroupDelete(c, *groupDeleteName)
+	case targetAddCmd.FullCommand():
+		err = ctl.TargetAdd(c, *targetAddGroup, *targetAddTargets)
+	case targetRemoveCmd.FullCommand():
+		err = ctl.TargetRemove(c, *targetRemoveGroup, *targetRemoveTargets)
+	case probeCmd.FullCommand():
+		err = ctl.Probe(c, os.Stdout, &pb.AdhocProbeRequest{
+			ProberType:     *probeType,
+			Target:         *probeTarget,
+			SourceRegion:   *probeSource,
+			AgentIp:        *probeAgent,
+			TargetRegion:   *probeTargetRegion,
+			TimeoutSeconds: int32(probeWait.Seconds()),
+		})
+	case dashboardCmd.FullCommand():
+		err = ctl.Dashboard(c, os.Stdout, *dashboardTitle, *dashboardDatasource)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -78,14 +78,86 @@ func main() {
 
 	webListenAddr := sConfig.MetricsListenAddr
 
-	rc.NewManagager(logger, grpcListenAddress)
+	if err := rc.NewManagager(logger, grpcListenAddress, sConfig.RpcTls); err != nil {
+		level.Error(logger).Log("msg", "init_grpc_tls_error", "err", err)
+		return
+	}
+
+	// new agent rpc authentication
+	if err := rc.NewAgentAuth(logger, sConfig.AgentAuth); err != nil {
+		level.Error(logger).Log("msg", "init_agent_auth_error", "err", err)
+		return
+	}
+
+	// new pushed result validation
+	if err := rc.NewIngestValidator(logger, sConfig.Ingest); err != nil {
+		level.Error(logger).Log("msg", "init_ingest_error", "err", err)
+		return
+	}
 
 	// new prome register
 	rc.NewMetrics()
 
+	// new otlp exporter
+	if err := rc.NewOtlpExporter(logger, sConfig.OtlpExporter, grpcListenAddress); err != nil {
+		level.Error(logger).Log("msg", "init_otlp_exporter_error", "err", err)
+		return
+	}
+
+	// new output sinks
+	if err := rc.NewSinkManager(logger, sConfig.OutputSinks); err != nil {
+		level.Error(logger).Log("msg", "init_output_sinks_error", "err", err)
+		return
+	}
+
+	// new raw result stream
+	if err := rc.NewResultStreamer(logger, sConfig.ResultStream); err != nil {
+		level.Error(logger).Log("msg", "init_result_stream_error", "err", err)
+		return
+	}
+
+	// new alert rule engine
+	if err := rc.NewAlertManager(logger, sConfig.AlertRules, sConfig.AlertWebhooks); err != nil {
+		level.Error(logger).Log("msg", "init_alert_rules_error", "err", err)
+		return
+	}
+
+	// new anomaly detector
+	rc.NewAnomalyDetector(logger, sConfig.AnomalyDetection)
+
+	// new embedded history store
+	if err := rc.NewHistory(logger, sConfig.History); err != nil {
+		level.Error(logger).Log("msg", "init_history_error", "err", err)
+		return
+	}
+
+	// new scheduled sla reports
+	if err := rc.NewSlaReporter(logger, sConfig.SlaReports); err != nil {
+		level.Error(logger).Log("msg", "init_sla_reports_error", "err", err)
+		return
+	}
+
+	// new slo tracking, after history so it can backfill
+	if err := rc.NewSloManager(logger, sConfig.Slos); err != nil {
+		level.Error(logger).Log("msg", "init_slos_error", "err", err)
+		return
+	}
+
+	// new server cluster
+	if err := rc.NewCluster(logger, sConfig.Cluster, sConfig.RpcTls); err != nil {
+		level.Error(logger).Log("msg", "init_cluster_error", "err", err)
+		return
+	}
+
 	// new target pool manager
 	tfm := rc.NewTargetFlushManager(logger, *configFile)
 
+	// new runtime target admin
+	if err := rc.NewAdminStore(logger, sConfig.Admin, tfm); err != nil {
+		level.Error(logger).Log("msg", "init_admin_error", "err", err)
+		return
+	}
+
 	var g run.Group
 	{
 		// Termination handler.
@@ -131,6 +203,8 @@ func main() {
 		// metrics http handler.
 		g.Add(func() error {
 			http.Handle("/metrics", promhttp.Handler())
+			rc.RegisterApiHandlers(http.DefaultServeMux)
+			rc.RegisterAdminHandlers(http.DefaultServeMux)
 			srv := http.Server{Addr: webListenAddr}
 			level.Info(logger).Log("msg", "Listening on address", "address", webListenAddr)
 			errchan := make(chan error)
@@ -163,6 +237,96 @@ func main() {
 		})
 	}
 
+	{
+		// output sinks
+		g.Add(func() error {
+			err := rc.SinkM.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// raw result stream
+		g.Add(func() error {
+			err := rc.StreamM.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// otlp exporter
+		g.Add(func() error {
+			err := rc.OtlpE.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// alert rule engine
+		g.Add(func() error {
+			err := rc.AlertM.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// anomaly baseline persistence
+		g.Add(func() error {
+			err := rc.AnomalyD.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// history tsdb maintenance
+		g.Add(func() error {
+			err := rc.RunHistory(ctxAll, logger)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// scheduled sla reports
+		g.Add(func() error {
+			err := rc.SlaR.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// slo evaluation
+		g.Add(func() error {
+			err := rc.SloM.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
+	{
+		// cluster sync and result forwarding
+		g.Add(func() error {
+			err := rc.ClusterC.Run(ctxAll)
+			return err
+		}, func(err error) {
+			cancelAll()
+		})
+	}
+
 	{
 		// target flush manager
 		g.Add(func() error {
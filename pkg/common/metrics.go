@@ -16,4 +16,68 @@ const (
 	MetricsNameHttpProcessingDurationMillonseconds = `http_processingDuration_millonseconds`
 	MetricsNameHttpTransferDurationMillonseconds   = `http_transferDuration_millonseconds`
 	MetricsNameHttpInterfaceSuccess                = `http_interface_success`
+
+	// tcp
+	MetricsNameTcpConnectDurationMillonseconds = `tcp_connectDuration_millonseconds`
+	MetricsNameTcpConnectSuccess               = `tcp_connect_success`
+
+	// output sink
+	MetricsNameSinkPointsWritten = `xprober_sink_points_written_total`
+	MetricsNameSinkPointsDropped = `xprober_sink_points_dropped_total`
+	MetricsNameSinkWriteFailures = `xprober_sink_write_failures_total`
+
+	// result stream
+	MetricsNameStreamResultsPublished = `xprober_stream_results_published_total`
+	MetricsNameStreamResultsDropped   = `xprober_stream_results_dropped_total`
+	MetricsNameStreamPublishFailures  = `xprober_stream_publish_failures_total`
+	MetricsNameStreamQueueLength      = `xprober_stream_queue_length`
+
+	// alert
+	MetricsNameAlerts                    = `xprober_alerts`
+	MetricsNameAlertNotifications        = `xprober_alert_notifications_total`
+	MetricsNameAlertNotificationFailures = `xprober_alert_notification_failures_total`
+
+	// anomaly
+	MetricsNameAnomalyScore  = `xprober_anomaly_score`
+	MetricsNameAnomalyEvents = `xprober_anomaly_events_total`
+
+	// agent inventory
+	MetricsNameAgentInfo              = `xprober_agent_info`
+	MetricsNameAgentTargets           = `xprober_agent_targets`
+	MetricsNameAgentProbeCycleSeconds = `xprober_agent_probe_cycle_seconds`
+	MetricsNameAgentLastPush          = `xprober_agent_last_push_timestamp_seconds`
+	MetricsNameAgentLastReport        = `xprober_agent_last_report_timestamp_seconds`
+
+	// agent side server failover
+	MetricsNameAgentRpcServerHealthy   = `xprober_agent_rpc_server_healthy`
+	MetricsNameAgentRpcServerConnected = `xprober_agent_rpc_server_connected`
+
+	// agent side result spool
+	MetricsNameAgentSpoolBytes    = `xprober_agent_spool_bytes`
+	MetricsNameAgentSpoolResults  = `xprober_agent_spool_results`
+	MetricsNameAgentSpoolOldest   = `xprober_agent_spool_oldest_timestamp_seconds`
+	MetricsNameAgentSpoolWritten  = `xprober_agent_spool_written_results_total`
+	MetricsNameAgentSpoolReplayed = `xprober_agent_spool_replayed_results_total`
+	MetricsNameAgentSpoolDropped  = `xprober_agent_spool_dropped_results_total`
+
+	// slo
+	MetricsNameSloObjective            = `xprober_slo_objective_ratio`
+	MetricsNameSloSli                  = `xprober_slo_sli_ratio`
+	MetricsNameSloErrorBudgetRemaining = `xprober_slo_error_budget_remaining_ratio`
+	MetricsNameSloBurnRate             = `xprober_slo_burn_rate`
+
+	// agent rpc auth
+	MetricsNameRpcRejected = `xprober_rpc_rejected_total`
+
+	// server cluster
+	MetricsNameClusterPeerUp         = `xprober_cluster_peer_up`
+	MetricsNameClusterLeader         = `xprober_cluster_leader`
+	MetricsNameClusterForwarded      = `xprober_cluster_forwarded_results_total`
+	MetricsNameClusterForwardDropped = `xprober_cluster_forward_dropped_results_total`
+
+	// result ingestion
+	MetricsNameIngestDuplicates            = `xprober_ingest_duplicate_results_total`
+	MetricsNameIngestSequenceGaps          = `xprober_ingest_sequence_gaps_total`
+	MetricsNameIngestRejected              = `xprober_ingest_rejected_results_total`
+	MetricsNameAgentResultFreshnessSeconds = `xprober_agent_result_freshness_seconds`
 )
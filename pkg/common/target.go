@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+/*
+   targets come from config files, the admin api, cluster peers and adhoc
+   requests and end up in ping command lines, they are checked per prober type
+   on the server and again on the agent before probing
+*/
+
+var hostnameRe = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9])?\.?$`)
+
+// ValidHost accepts an ip or a dns name
+func ValidHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	return len(host) <= 253 && hostnameRe.MatchString(host)
+}
+
+// ValidateTarget checks target is usable by the proberType prober
+func ValidateTarget(proberType, target string) error {
+	if target == "" {
+		return fmt.Errorf("empty target")
+	}
+	switch proberType {
+	case "icmp":
+		if !ValidHost(target) {
+			return fmt.Errorf("icmp target %q is not an ip or hostname", target)
+		}
+	case "tcp":
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return fmt.Errorf("tcp target %q: %v", target, err)
+		}
+		if !ValidHost(host) {
+			return fmt.Errorf("tcp target %q: host is not an ip or hostname", target)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("tcp target %q: invalid port", target)
+		}
+	case "http":
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("http target %q: %v", target, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("http target %q: scheme must be http or https", target)
+		}
+		if !ValidHost(u.Hostname()) {
+			return fmt.Errorf("http target %q: host is not an ip or hostname", target)
+		}
+	default:
+		return fmt.Errorf("unsupported prober type %q", proberType)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the json api on the server metrics listener
+type Client struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func NewClient(addr, token string, timeout time.Duration) *Client {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return &Client{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (c *Client) do(method, path string, query url.Values, body, out interface{}) error {
+	u := c.addr + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var rb io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rb = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u, rb)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		ae := &apiError{}
+		if json.Unmarshal(content, ae) == nil && ae.Error != "" {
+			return fmt.Errorf("%s %s: %s", method, path, ae.Error)
+		}
+		return fmt.Errorf("%s %s: unexpected status %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
+
+func (c *Client) Get(path string, query url.Values, out interface{}) error {
+	return c.do(http.MethodGet, path, query, nil, out)
+}
+
+func (c *Client) Send(method, path string, body interface{}) error {
+	return c.do(method, path, nil, body, nil)
+}
+
+func (c *Client) Post(path string, body, out interface{}) error {
+	return c.do(http.MethodPost, path, nil, body, out)
+}
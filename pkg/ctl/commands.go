@@ -0,0 +1,209 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const adminTargetGroupsPath = `/api/v1/admin/target_groups`
+
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func since(ts int64) string {
+	if ts == 0 {
+		return "never"
+	}
+	return time.Since(time.Unix(ts, 0)).Truncate(time.Second).String() + " ago"
+}
+
+func fmtValue(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func Agents(c *Client, w io.Writer) error {
+	var agents []*api.AgentInfo
+	if err := c.Get("/api/v1/agents", nil, &agents); err != nil {
+		return err
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "IP\tREGION\tHOSTNAME\tINSTANCE\tVERSION\tPROBE TYPES\tTARGETS\tPROBE CYCLE\tLAST REPORT\tLAST TARGETS GET\tLAST PUSH\tSERVER\tCERT IDENTITY")
+	for _, a := range agents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%.2fs\t%s\t%s\t%s\t%s\t%s\n",
+			a.Ip, a.Region, a.Hostname, a.InstanceId, a.Version, strings.Join(a.ProbeTypes, ","), a.TargetNum, a.LastProbeCycleSeconds,
+			since(a.LastReport), since(a.LastTargetsGet), since(a.LastPush), a.ConnectedServer, a.CertIdentity)
+	}
+	return tw.Flush()
+}
+
+func Targets(c *Client, w io.Writer, agentIp, region string) error {
+	q := url.Values{}
+	if agentIp != "" {
+		q.Set("agent", agentIp)
+	}
+	if region != "" {
+		q.Set("region", region)
+	}
+	var tgs []*pb.Targets
+	if err := c.Get("/api/v1/targets", q, &tgs); err != nil {
+		return err
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "PROBER TYPE\tTARGET REGION\tTARGET")
+	for _, t := range tgs {
+		for _, addr := range t.Target {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", t.ProberType, t.Region, addr)
+		}
+	}
+	return tw.Flush()
+}
+
+// Matrix prints the region by region matrix with source regions as rows
+func Matrix(c *Client, w io.Writer, metricName string) error {
+	m := &api.MatrixResponse{}
+	if err := c.Get("/api/v1/matrix", url.Values{"metric": []string{metricName}}, m); err != nil {
+		return err
+	}
+	if len(m.Regions) == 0 {
+		fmt.Fprintln(w, "no data")
+		return nil
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintf(tw, "%s\t%s\n", "SOURCE\\TARGET", strings.Join(m.Regions, "\t"))
+	for _, src := range m.Regions {
+		row := []string{src}
+		for _, dst := range m.Regions {
+			if c, ok := m.Matrix[src][dst]; ok {
+				row = append(row, fmtValue(c.Value))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func Samples(c *Client, w io.Writer, sourceRegion, targetRegion, metricName string) error {
+	q := url.Values{"source_region": []string{sourceRegion}, "target_region": []string{targetRegion}}
+	if metricName != "" {
+		q.Set("metric", metricName)
+	}
+	var prs []*pb.ProberResultOne
+	if err := c.Get("/api/v1/samples", q, &prs); err != nil {
+		return err
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "METRIC\tAGENT\tTARGET ADDR\tVALUE\tUPDATED")
+	for _, p := range prs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.MetricName, p.WorkerName, p.TargetAddr, fmtValue(float64(p.Value)), since(p.TimeStamp))
+	}
+	return tw.Flush()
+}
+
+var httpStageMetrics = []string{
+	common.MetricsNameHttpInterfaceSuccess,
+	common.MetricsNameHttpResolvedurationMillonseconds,
+	common.MetricsNameHttpConnectDurationMillonseconds,
+	common.MetricsNameHttpTlsDurationMillonseconds,
+	common.MetricsNameHttpProcessingDurationMillonseconds,
+	common.MetricsNameHttpTransferDurationMillonseconds,
+}
+
+func Http(c *Client, w io.Writer, sourceRegion string) error {
+	var hts []*api.HttpTargetResponse
+	if err := c.Get("/api/v1/http", url.Values{"source_region": []string{sourceRegion}}, &hts); err != nil {
+		return err
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "SOURCE\tADDR\tSUCCESS\tRESOLVE\tCONNECT\tTLS\tPROCESSING\tTRANSFER")
+	for _, ht := range hts {
+		row := []string{ht.SourceRegion, ht.Addr}
+		for _, m := range httpStageMetrics {
+			if c, ok := ht.Metrics[m]; ok {
+				row = append(row, fmtValue(c.Value))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func GroupList(c *Client, w io.Writer) error {
+	var groups []*pb.TargetGroup
+	if err := c.Get(adminTargetGroupsPath, nil, &groups); err != nil {
+		return err
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Source > groups[j].Source })
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tPROBER TYPE\tREGION\tTARGETS")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", g.Name, g.Source, g.ProberType, g.Region, strings.Join(g.Target, ","))
+	}
+	return tw.Flush()
+}
+
+func GroupSet(c *Client, name, proberType, region string, targets []string) error {
+	return c.Send("PUT", adminTargetGroupsPath+"/"+url.PathEscape(name), &pb.TargetGroup{
+		ProberType: proberType,
+		Region:     region,
+		Target:     targets,
+	})
+}
+
+func GroupDelete(c *Client, name string) error {
+	return c.Send("DELETE", adminTargetGroupsPath+"/"+url.PathEscape(name), nil)
+}
+
+func TargetAdd(c *Client, group string, targets []string) error {
+	return c.Send("POST", adminTargetGroupsPath+"/"+url.PathEscape(group)+"/targets", &pb.AdminTargetsRequest{Target: targets})
+}
+
+func TargetRemove(c *Client, group string, targets []string) error {
+	return c.Send("DELETE", adminTargetGroupsPath+"/"+url.PathEscape(group)+"/targets", &pb.AdminTargetsRequest{Target: targets})
+}
+
+// Probe runs an adhoc probe and prints one row per agent and metric
+func Probe(c *Client, w io.Writer, req *pb.AdhocProbeRequest) error {
+	resp := &pb.AdhocProbeResponse{}
+	if err := c.Post("/api/v1/admin/probe", req, resp); err != nil {
+		return err
+	}
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "AGENT\tREGION\tMETRIC\tVALUE\tERROR")
+	for _, r := range resp.Results {
+		if len(r.Results) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t%s\n", r.Ip, r.Region, r.Error)
+			continue
+		}
+		for _, p := range r.Results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Ip, r.Region, p.MetricName, fmtValue(float64(p.Value)), r.Error)
+		}
+	}
+	return tw.Flush()
+}
+
+// Dashboard writes the generated grafana dashboard json, ready to import
+func Dashboard(c *Client, w io.Writer, title, datasource string) error {
+	var d map[string]interface{}
+	q := url.Values{"title": []string{title}, "datasource": []string{datasource}}
+	if err := c.Get("/api/v1/grafana/dashboard", q, &d); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
@@ -0,0 +1,223 @@
+package otlp
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+/*
+   minimal protobuf encoder for the otlp metrics messages we send,
+   field numbers follow opentelemetry/proto/metrics/v1/metrics.proto
+*/
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+
+	aggregationTemporalityCumulative = 2
+)
+
+type buffer struct {
+	b []byte
+}
+
+func (b *buffer) varint(v uint64) {
+	for v >= 0x80 {
+		b.b = append(b.b, byte(v)|0x80)
+		v >>= 7
+	}
+	b.b = append(b.b, byte(v))
+}
+
+func (b *buffer) fixed64(v uint64) {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	b.b = append(b.b, tmp[:]...)
+}
+
+func (b *buffer) tag(field int, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *buffer) uint64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, wireVarint)
+	b.varint(v)
+}
+
+func (b *buffer) boolField(field int, v bool) {
+	if !v {
+		return
+	}
+	b.tag(field, wireVarint)
+	b.varint(1)
+}
+
+func (b *buffer) fixed64Field(field int, v uint64) {
+	b.tag(field, wireFixed64)
+	b.fixed64(v)
+}
+
+func (b *buffer) doubleField(field int, v float64) {
+	b.fixed64Field(field, math.Float64bits(v))
+}
+
+func (b *buffer) stringField(field int, s string) {
+	if s == "" {
+		return
+	}
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(s)))
+	b.b = append(b.b, s...)
+}
+
+func (b *buffer) messageField(field int, m *buffer) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(m.b)))
+	b.b = append(b.b, m.b...)
+}
+
+func (b *buffer) packedFixed64(field int, vs []uint64) {
+	if len(vs) == 0 {
+		return
+	}
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(vs) * 8))
+	for _, v := range vs {
+		b.fixed64(v)
+	}
+}
+
+func (b *buffer) packedDouble(field int, vs []float64) {
+	if len(vs) == 0 {
+		return
+	}
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(vs) * 8))
+	for _, v := range vs {
+		b.fixed64(math.Float64bits(v))
+	}
+}
+
+// attributes encodes a map as repeated KeyValue with string AnyValue, sorted by key
+func (b *buffer) attributes(field int, attrs map[string]string) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		anyValue := &buffer{}
+		anyValue.tag(1, wireBytes)
+		anyValue.varint(uint64(len(attrs[k])))
+		anyValue.b = append(anyValue.b, attrs[k]...)
+
+		kv := &buffer{}
+		kv.stringField(1, k)
+		kv.messageField(2, anyValue)
+		b.messageField(field, kv)
+	}
+}
+
+func encodeNumberDataPoint(p *numberPoint, start uint64) *buffer {
+	dp := &buffer{}
+	if start > 0 {
+		dp.fixed64Field(2, start)
+	}
+	dp.fixed64Field(3, p.timeUnixNano)
+	dp.doubleField(4, p.value)
+	dp.attributes(7, p.attributes)
+	return dp
+}
+
+func encodeHistogramDataPoint(p *histogramPoint, start uint64) *buffer {
+	dp := &buffer{}
+	dp.fixed64Field(2, start)
+	dp.fixed64Field(3, p.timeUnixNano)
+	dp.fixed64Field(4, p.count)
+	dp.doubleField(5, p.sum)
+	dp.packedFixed64(6, p.bucketCounts)
+	dp.packedDouble(7, p.explicitBounds)
+	dp.attributes(9, p.attributes)
+	return dp
+}
+
+func encodeSummaryDataPoint(p *summaryPoint, start uint64) *buffer {
+	dp := &buffer{}
+	dp.fixed64Field(2, start)
+	dp.fixed64Field(3, p.timeUnixNano)
+	dp.fixed64Field(4, p.count)
+	dp.doubleField(5, p.sum)
+	for i := range p.quantiles {
+		qv := &buffer{}
+		qv.doubleField(1, p.quantiles[i])
+		qv.doubleField(2, p.values[i])
+		dp.messageField(6, qv)
+	}
+	dp.attributes(7, p.attributes)
+	return dp
+}
+
+func encodeMetric(m *metric, start uint64) *buffer {
+	mb := &buffer{}
+	mb.stringField(1, m.name)
+	mb.stringField(2, m.description)
+	mb.stringField(3, m.unit)
+
+	data := &buffer{}
+	switch m.kind {
+	case kindGauge:
+		for _, p := range m.numbers {
+			data.messageField(1, encodeNumberDataPoint(p, 0))
+		}
+		mb.messageField(5, data)
+	case kindSum:
+		for _, p := range m.numbers {
+			data.messageField(1, encodeNumberDataPoint(p, start))
+		}
+		data.uint64Field(2, aggregationTemporalityCumulative)
+		data.boolField(3, m.monotonic)
+		mb.messageField(7, data)
+	case kindHistogram:
+		for _, p := range m.histograms {
+			data.messageField(1, encodeHistogramDataPoint(p, start))
+		}
+		data.uint64Field(2, aggregationTemporalityCumulative)
+		mb.messageField(9, data)
+	case kindSummary:
+		for _, p := range m.summaries {
+			data.messageField(1, encodeSummaryDataPoint(p, start))
+		}
+		mb.messageField(11, data)
+	}
+	return mb
+}
+
+// encodeExportRequest builds an ExportMetricsServiceRequest with a single
+// ResourceMetrics and ScopeMetrics
+func encodeExportRequest(resource map[string]string, scopeName, scopeVersion string, metrics []*metric, start uint64) []byte {
+	res := &buffer{}
+	res.attributes(1, resource)
+
+	scope := &buffer{}
+	scope.stringField(1, scopeName)
+	scope.stringField(2, scopeVersion)
+
+	sm := &buffer{}
+	sm.messageField(1, scope)
+	for _, m := range metrics {
+		sm.messageField(2, encodeMetric(m, start))
+	}
+
+	rm := &buffer{}
+	rm.messageField(1, res)
+	rm.messageField(2, sm)
+
+	req := &buffer{}
+	req.messageField(1, rm)
+	return req.b
+}
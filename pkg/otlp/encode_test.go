@@ -0,0 +1,84 @@
+package otlp
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func testMetrics() []*metric {
+	attrs := map[string]string{"target_region": "r2", "source_region": "r1"}
+	return []*metric{
+		{name: "ping_latency", unit: "ms", kind: kindGauge,
+			numbers: []*numberPoint{{attributes: attrs, timeUnixNano: 2000, value: 1.5}}},
+		{name: "pushes_total", description: "pushes", kind: kindSum, monotonic: true,
+			numbers: []*numberPoint{{timeUnixNano: 2000, value: 3}}},
+		{name: "push_seconds", kind: kindHistogram,
+			histograms: []*histogramPoint{{timeUnixNano: 2000, count: 3, sum: 0.75,
+				bucketCounts: []uint64{1, 2, 0}, explicitBounds: []float64{0.1, 1}}}},
+		{name: "gc_seconds", kind: kindSummary,
+			summaries: []*summaryPoint{{attributes: map[string]string{"a": "b"}, timeUnixNano: 2000, count: 2, sum: 0.5,
+				quantiles: []float64{0.5, 1}, values: []float64{0.2, 0.3}}}},
+	}
+}
+
+// decoded with go.opentelemetry.io/proto/otlp metrics/v1 MetricsData, which
+// shares the wire format of ExportMetricsServiceRequest:
+//
+//	resource_metrics {
+//	  resource { attributes { key: "service.name" value { string_value: "xprober-server" } } }
+//	  scope_metrics {
+//	    scope { name: "xprober" version: "0.1" }
+//	    metrics { name: "ping_latency" unit: "ms" gauge { data_points {
+//	      time_unix_nano: 2000 as_double: 1.5
+//	      attributes { key: "source_region" value { string_value: "r1" } }
+//	      attributes { key: "target_region" value { string_value: "r2" } } } } }
+//	    metrics { name: "pushes_total" description: "pushes" sum {
+//	      data_points { start_time_unix_nano: 1000 time_unix_nano: 2000 as_double: 3 }
+//	      aggregation_temporality: AGGREGATION_TEMPORALITY_CUMULATIVE is_monotonic: true } }
+//	    metrics { name: "push_seconds" histogram {
+//	      data_points { start_time_unix_nano: 1000 time_unix_nano: 2000 count: 3 sum: 0.75
+//	        bucket_counts: [1, 2, 0] explicit_bounds: [0.1, 1] }
+//	      aggregation_temporality: AGGREGATION_TEMPORALITY_CUMULATIVE } }
+//	    metrics { name: "gc_seconds" summary { data_points {
+//	      start_time_unix_nano: 1000 time_unix_nano: 2000 count: 2 sum: 0.5
+//	      quantile_values { quantile: 0.5 value: 0.2 } quantile_values { quantile: 1 value: 0.3 }
+//	      attributes { key: "a" value { string_value: "b" } } } } }
+//	  }
+//	}
+const goldenExportRequest = "" +
+	"0a98030a220a200a0c736572766963652e6e616d6512100a0e7870726f626572" +
+	"2d73657276657212f1020a0e0a077870726f6265721203302e3112560a0c7069" +
+	"6e675f6c6174656e63791a026d732a420a4019d0070000000000002100000000" +
+	"0000f83f3a150a0d736f757263655f726567696f6e12040a0272313a150a0d74" +
+	"61726765745f726567696f6e12040a02723212390a0c7075736865735f746f74" +
+	"616c12067075736865733a210a1b11e80300000000000019d007000000000000" +
+	"2100000000000008401002180112640a0c707573685f7365636f6e64734a540a" +
+	"5011e80300000000000019d00700000000000021030000000000000029000000" +
+	"000000e83f32180100000000000000020000000000000000000000000000003a" +
+	"109a9999999999b93f000000000000f03f100212660a0a67635f7365636f6e64" +
+	"735a580a5611e80300000000000019d007000000000000210200000000000000" +
+	"29000000000000e03f321209000000000000e03f119a9999999999c93f321209" +
+	"000000000000f03f11333333333333d33f3a080a016112030a0162"
+
+func TestEncodeExportRequestGolden(t *testing.T) {
+	b := encodeExportRequest(map[string]string{"service.name": "xprober-server"}, scopeName, "0.1", testMetrics(), 1000)
+	if got := hex.EncodeToString(b); got != goldenExportRequest {
+		t.Fatalf("export request changed\n got: %s\nwant: %s", got, goldenExportRequest)
+	}
+}
+
+func TestRecordDropsPointsOverLimit(t *testing.T) {
+	e := &Exporter{recorded: make(map[string]*metric)}
+	ts := time.Unix(1, 0)
+	for i := 0; i <= maxPendingPoints; i++ {
+		e.Record("m", nil, float64(i), ts)
+	}
+	points := e.recorded["m"].numbers
+	if len(points) != maxPendingPoints {
+		t.Fatalf("kept %d points, want %d", len(points), maxPendingPoints)
+	}
+	if last := points[len(points)-1].value; last != maxPendingPoints-1 {
+		t.Fatalf("last kept point %v, want the earlier points kept", last)
+	}
+}
@@ -0,0 +1,390 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	ProtocolGrpc = `grpc`
+	ProtocolHttp = `http`
+
+	exportMethod   = `/opentelemetry.proto.collector.metrics.v1.MetricsService/Export`
+	httpExportPath = `/v1/metrics`
+	scopeName      = `xprober`
+
+	defaultInterval = 30 * time.Second
+	defaultTimeout  = 10 * time.Second
+	// recorded points are kept until the next export, points recorded once
+	// the limit is reached are dropped
+	maxPendingPoints = 100000
+)
+
+type Config struct {
+	Endpoint string
+	Protocol string
+	Insecure bool
+	Headers  map[string]string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Resource is merged into the resource attributes of every export
+	Resource map[string]string
+}
+
+type metricKind int
+
+const (
+	kindGauge metricKind = iota
+	kindSum
+	kindHistogram
+	kindSummary
+)
+
+type numberPoint struct {
+	attributes   map[string]string
+	timeUnixNano uint64
+	value        float64
+}
+
+type histogramPoint struct {
+	attributes     map[string]string
+	timeUnixNano   uint64
+	count          uint64
+	sum            float64
+	bucketCounts   []uint64
+	explicitBounds []float64
+}
+
+type summaryPoint struct {
+	attributes   map[string]string
+	timeUnixNano uint64
+	count        uint64
+	sum          float64
+	quantiles    []float64
+	values       []float64
+}
+
+type metric struct {
+	name        string
+	description string
+	unit        string
+	kind        metricKind
+	monotonic   bool
+	numbers     []*numberPoint
+	histograms  []*histogramPoint
+	summaries   []*summaryPoint
+}
+
+// Exporter periodically pushes the metrics of a prometheus gatherer plus
+// directly recorded measurements to an otlp collector
+type Exporter struct {
+	logger   log.Logger
+	cfg      Config
+	gatherer prometheus.Gatherer
+	resource map[string]string
+	start    uint64
+
+	httpURL    string
+	httpClient *http.Client
+	grpcConn   *grpc.ClientConn
+
+	mu       sync.Mutex
+	recorded map[string]*metric
+	pending  int
+}
+
+// NewExporter creates an exporter, gatherer may be nil when only Record is used
+func NewExporter(logger log.Logger, cfg Config, serviceName string, gatherer prometheus.Gatherer) (*Exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("otlp: empty endpoint")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	e := &Exporter{
+		logger:   log.With(logger, "component", "otlp"),
+		cfg:      cfg,
+		gatherer: gatherer,
+		start:    uint64(time.Now().UnixNano()),
+		recorded: make(map[string]*metric),
+	}
+
+	e.resource = map[string]string{
+		"service.name":    serviceName,
+		"service.version": version.Version,
+	}
+	if host, err := os.Hostname(); err == nil {
+		e.resource["host.name"] = host
+		e.resource["service.instance.id"] = host
+	}
+	for k, v := range cfg.Resource {
+		e.resource[k] = v
+	}
+
+	switch cfg.Protocol {
+	case ProtocolGrpc, "":
+		e.cfg.Protocol = ProtocolGrpc
+		opts := []grpc.DialOption{}
+		if cfg.Insecure {
+			opts = append(opts, grpc.WithInsecure())
+		} else {
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+		}
+		conn, err := grpc.Dial(cfg.Endpoint, opts...)
+		if err != nil {
+			return nil, err
+		}
+		e.grpcConn = conn
+	case ProtocolHttp:
+		u := cfg.Endpoint
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			if cfg.Insecure {
+				u = "http://" + u
+			} else {
+				u = "https://" + u
+			}
+		}
+		if !strings.HasSuffix(u, httpExportPath) {
+			u = strings.TrimSuffix(u, "/") + httpExportPath
+		}
+		e.httpURL = u
+		e.httpClient = &http.Client{Timeout: cfg.Timeout}
+	default:
+		return nil, fmt.Errorf("otlp: unknown protocol %s", cfg.Protocol)
+	}
+	return e, nil
+}
+
+// Record adds a single gauge measurement which is sent with the next export
+func (e *Exporter) Record(name string, attrs map[string]string, value float64, ts time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending >= maxPendingPoints {
+		return
+	}
+	m, ok := e.recorded[name]
+	if !ok {
+		m = &metric{name: name, kind: kindGauge}
+		e.recorded[name] = m
+	}
+	m.numbers = append(m.numbers, &numberPoint{attributes: attrs, timeUnixNano: uint64(ts.UnixNano()), value: value})
+	e.pending++
+}
+
+func (e *Exporter) Run(ctx context.Context) error {
+	if e == nil {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(e.cfg.Interval)
+	level.Info(e.logger).Log("msg", "otlp exporter start....", "endpoint", e.cfg.Endpoint, "protocol", e.cfg.Protocol)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.export(ctx)
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+			e.export(fctx)
+			cancel()
+			if e.grpcConn != nil {
+				e.grpcConn.Close()
+			}
+			level.Info(e.logger).Log("msg", "otlp exporter exit....")
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) export(ctx context.Context) {
+	var metrics []*metric
+	if e.gatherer != nil {
+		mfs, err := e.gatherer.Gather()
+		if err != nil {
+			level.Warn(e.logger).Log("msg", "gather metrics error", "err", err)
+		}
+		metrics = append(metrics, convertMetricFamilies(mfs)...)
+	}
+
+	e.mu.Lock()
+	for _, m := range e.recorded {
+		metrics = append(metrics, m)
+	}
+	e.recorded = make(map[string]*metric)
+	e.pending = 0
+	e.mu.Unlock()
+
+	if len(metrics) == 0 {
+		return
+	}
+	body := encodeExportRequest(e.resource, scopeName, version.Version, metrics, e.start)
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	var err error
+	if e.grpcConn != nil {
+		err = e.exportGrpc(ctx, body)
+	} else {
+		err = e.exportHttp(ctx, body)
+	}
+	if err != nil {
+		level.Error(e.logger).Log("msg", "otlp export error", "metrics", len(metrics), "err", err)
+		return
+	}
+	level.Debug(e.logger).Log("msg", "otlp export done", "metrics", len(metrics), "bytes", len(body))
+}
+
+func (e *Exporter) exportGrpc(ctx context.Context, body []byte) error {
+	if len(e.cfg.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.cfg.Headers))
+	}
+	var resp rawMessage
+	req := rawMessage(body)
+	return e.grpcConn.Invoke(ctx, exportMethod, &req, &resp, grpc.ForceCodec(rawCodec{}))
+}
+
+func (e *Exporter) exportHttp(ctx context.Context, body []byte) error {
+	req, err := http.NewRequest("POST", e.httpURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func labelsToAttributes(lps []*dto.LabelPair) map[string]string {
+	attrs := make(map[string]string, len(lps))
+	for _, lp := range lps {
+		attrs[lp.GetName()] = lp.GetValue()
+	}
+	return attrs
+}
+
+func convertMetricFamilies(mfs []*dto.MetricFamily) []*metric {
+	now := uint64(time.Now().UnixNano())
+	res := make([]*metric, 0, len(mfs))
+	for _, mf := range mfs {
+		m := &metric{name: mf.GetName(), description: mf.GetHelp()}
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			m.kind = kindSum
+			m.monotonic = true
+			for _, pm := range mf.Metric {
+				m.numbers = append(m.numbers, &numberPoint{labelsToAttributes(pm.Label), now, pm.GetCounter().GetValue()})
+			}
+		case dto.MetricType_GAUGE:
+			m.kind = kindGauge
+			for _, pm := range mf.Metric {
+				m.numbers = append(m.numbers, &numberPoint{labelsToAttributes(pm.Label), now, pm.GetGauge().GetValue()})
+			}
+		case dto.MetricType_UNTYPED:
+			m.kind = kindGauge
+			for _, pm := range mf.Metric {
+				m.numbers = append(m.numbers, &numberPoint{labelsToAttributes(pm.Label), now, pm.GetUntyped().GetValue()})
+			}
+		case dto.MetricType_HISTOGRAM:
+			m.kind = kindHistogram
+			for _, pm := range mf.Metric {
+				h := pm.GetHistogram()
+				hp := &histogramPoint{
+					attributes:   labelsToAttributes(pm.Label),
+					timeUnixNano: now,
+					count:        h.GetSampleCount(),
+					sum:          h.GetSampleSum(),
+				}
+				// prometheus buckets are cumulative, otlp wants per bucket counts
+				// plus an implicit +Inf bucket
+				var prev uint64
+				for _, b := range h.Bucket {
+					hp.explicitBounds = append(hp.explicitBounds, b.GetUpperBound())
+					hp.bucketCounts = append(hp.bucketCounts, b.GetCumulativeCount()-prev)
+					prev = b.GetCumulativeCount()
+				}
+				hp.bucketCounts = append(hp.bucketCounts, h.GetSampleCount()-prev)
+				m.histograms = append(m.histograms, hp)
+			}
+		case dto.MetricType_SUMMARY:
+			m.kind = kindSummary
+			for _, pm := range mf.Metric {
+				s := pm.GetSummary()
+				sp := &summaryPoint{
+					attributes:   labelsToAttributes(pm.Label),
+					timeUnixNano: now,
+					count:        s.GetSampleCount(),
+					sum:          s.GetSampleSum(),
+				}
+				for _, q := range s.Quantile {
+					sp.quantiles = append(sp.quantiles, q.GetQuantile())
+					sp.values = append(sp.values, q.GetValue())
+				}
+				m.summaries = append(m.summaries, sp)
+			}
+		default:
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// rawMessage and rawCodec pass the already encoded otlp request through grpc
+type rawMessage []byte
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("otlp: unexpected message type %T", v)
+	}
+	return *m, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("otlp: unexpected message type %T", v)
+	}
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -245,14 +245,21 @@ func (m *ProberResultPushRequest) GetProberResults() []*ProberResultOne {
 
 // ProberResultOne
 type ProberResultOne struct {
-	WorkerName           string   `protobuf:"bytes,1,opt,name=worker_name,json=workerName,proto3" json:"worker_name,omitempty"`
-	MetricName           string   `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
-	TargetAddr           string   `protobuf:"bytes,3,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
-	SourceRegion         string   `protobuf:"bytes,4,opt,name=source_region,json=sourceRegion,proto3" json:"source_region,omitempty"`
-	TargetRegion         string   `protobuf:"bytes,5,opt,name=target_region,json=targetRegion,proto3" json:"target_region,omitempty"`
-	ProbeType            string   `protobuf:"bytes,6,opt,name=probe_type,json=probeType,proto3" json:"probe_type,omitempty"`
-	TimeStamp            int64    `protobuf:"varint,7,opt,name=time_stamp,json=timeStamp,proto3" json:"time_stamp,omitempty"`
-	Value                float32  `protobuf:"fixed32,8,opt,name=value,proto3" json:"value,omitempty"`
+	WorkerName   string  `protobuf:"bytes,1,opt,name=worker_name,json=workerName,proto3" json:"worker_name,omitempty"`
+	MetricName   string  `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	TargetAddr   string  `protobuf:"bytes,3,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
+	SourceRegion string  `protobuf:"bytes,4,opt,name=source_region,json=sourceRegion,proto3" json:"source_region,omitempty"`
+	TargetRegion string  `protobuf:"bytes,5,opt,name=target_region,json=targetRegion,proto3" json:"target_region,omitempty"`
+	ProbeType    string  `protobuf:"bytes,6,opt,name=probe_type,json=probeType,proto3" json:"probe_type,omitempty"`
+	TimeStamp    int64   `protobuf:"varint,7,opt,name=time_stamp,json=timeStamp,proto3" json:"time_stamp,omitempty"`
+	Value        float32 `protobuf:"fixed32,8,opt,name=value,proto3" json:"value,omitempty"`
+	// per agent sequence, increases by one for every probe result, 0 from
+	// older agents and adhoc probes
+	Seq uint64 `protobuf:"varint,9,opt,name=seq,proto3" json:"seq,omitempty"`
+	// agent start time in unix nanos, the sequence restarts with it
+	SeqEpoch int64 `protobuf:"varint,10,opt,name=seq_epoch,json=seqEpoch,proto3" json:"seq_epoch,omitempty"`
+	// unix nanos when the probe started
+	ProbeStart           int64    `protobuf:"varint,11,opt,name=probe_start,json=probeStart,proto3" json:"probe_start,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -347,11 +354,37 @@ func (m *ProberResultOne) GetValue() float32 {
 	return 0
 }
 
+func (m *ProberResultOne) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *ProberResultOne) GetSeqEpoch() int64 {
+	if m != nil {
+		return m.SeqEpoch
+	}
+	return 0
+}
+
+func (m *ProberResultOne) GetProbeStart() int64 {
+	if m != nil {
+		return m.ProbeStart
+	}
+	return 0
+}
+
 type ProberResultPushResponse struct {
-	SuccessNum           int32    `protobuf:"varint,1,opt,name=success_num,json=successNum,proto3" json:"success_num,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	// results taken, duplicates included
+	SuccessNum int32 `protobuf:"varint,1,opt,name=success_num,json=successNum,proto3" json:"success_num,omitempty"`
+	// results failing validation, the agent does not send them again
+	RejectedNum int32 `protobuf:"varint,2,opt,name=rejected_num,json=rejectedNum,proto3" json:"rejected_num,omitempty"`
+	// the first rejected results with the reason
+	Rejected             []*RejectedResult `protobuf:"bytes,3,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ProberResultPushResponse) Reset()         { *m = ProberResultPushResponse{} }
@@ -394,10 +427,106 @@ func (m *ProberResultPushResponse) GetSuccessNum() int32 {
 	return 0
 }
 
+func (m *ProberResultPushResponse) GetRejectedNum() int32 {
+	if m != nil {
+		return m.RejectedNum
+	}
+	return 0
+}
+
+func (m *ProberResultPushResponse) GetRejected() []*RejectedResult {
+	if m != nil {
+		return m.Rejected
+	}
+	return nil
+}
+
+type RejectedResult struct {
+	Seq                  uint64   `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	MetricName           string   `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	TargetAddr           string   `protobuf:"bytes,3,opt,name=target_addr,json=targetAddr,proto3" json:"target_addr,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RejectedResult) Reset()         { *m = RejectedResult{} }
+func (m *RejectedResult) String() string { return proto.CompactTextString(m) }
+func (*RejectedResult) ProtoMessage()    {}
+func (*RejectedResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{6}
+}
+func (m *RejectedResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RejectedResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RejectedResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RejectedResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RejectedResult.Merge(m, src)
+}
+func (m *RejectedResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *RejectedResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_RejectedResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RejectedResult proto.InternalMessageInfo
+
+func (m *RejectedResult) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *RejectedResult) GetMetricName() string {
+	if m != nil {
+		return m.MetricName
+	}
+	return ""
+}
+
+func (m *RejectedResult) GetTargetAddr() string {
+	if m != nil {
+		return m.TargetAddr
+	}
+	return ""
+}
+
+func (m *RejectedResult) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
 // ProberAgentIpReport
 type ProberAgentIpReportRequest struct {
-	Ip                   string   `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
-	Region               string   `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Ip     string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Region string `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// inventory, empty from agents older than the inventory report
+	Version    string   `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Hostname   string   `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	InstanceId string   `protobuf:"bytes,5,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	ProbeTypes []string `protobuf:"bytes,6,rep,name=probe_types,json=probeTypes,proto3" json:"probe_types,omitempty"`
+	TargetNum  int32    `protobuf:"varint,7,opt,name=target_num,json=targetNum,proto3" json:"target_num,omitempty"`
+	// longest duration of the last probe of every local target, they run in parallel
+	LastProbeCycleSeconds float64 `protobuf:"fixed64,8,opt,name=last_probe_cycle_seconds,json=lastProbeCycleSeconds,proto3" json:"last_probe_cycle_seconds,omitempty"`
+	LastPush              int64   `protobuf:"varint,9,opt,name=last_push,json=lastPush,proto3" json:"last_push,omitempty"`
+	// server address the agent is currently connected to
+	ConnectedServer      string   `protobuf:"bytes,10,opt,name=connected_server,json=connectedServer,proto3" json:"connected_server,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -407,7 +536,7 @@ func (m *ProberAgentIpReportRequest) Reset()         { *m = ProberAgentIpReportR
 func (m *ProberAgentIpReportRequest) String() string { return proto.CompactTextString(m) }
 func (*ProberAgentIpReportRequest) ProtoMessage()    {}
 func (*ProberAgentIpReportRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_802a4ee07f8d018d, []int{6}
+	return fileDescriptor_802a4ee07f8d018d, []int{7}
 }
 func (m *ProberAgentIpReportRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -450,6 +579,62 @@ func (m *ProberAgentIpReportRequest) GetRegion() string {
 	return ""
 }
 
+func (m *ProberAgentIpReportRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ProberAgentIpReportRequest) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *ProberAgentIpReportRequest) GetInstanceId() string {
+	if m != nil {
+		return m.InstanceId
+	}
+	return ""
+}
+
+func (m *ProberAgentIpReportRequest) GetProbeTypes() []string {
+	if m != nil {
+		return m.ProbeTypes
+	}
+	return nil
+}
+
+func (m *ProberAgentIpReportRequest) GetTargetNum() int32 {
+	if m != nil {
+		return m.TargetNum
+	}
+	return 0
+}
+
+func (m *ProberAgentIpReportRequest) GetLastProbeCycleSeconds() float64 {
+	if m != nil {
+		return m.LastProbeCycleSeconds
+	}
+	return 0
+}
+
+func (m *ProberAgentIpReportRequest) GetLastPush() int64 {
+	if m != nil {
+		return m.LastPush
+	}
+	return 0
+}
+
+func (m *ProberAgentIpReportRequest) GetConnectedServer() string {
+	if m != nil {
+		return m.ConnectedServer
+	}
+	return ""
+}
+
 type ProberAgentIpReportResponse struct {
 	IsSuccess            bool     `protobuf:"varint,1,opt,name=is_success,json=isSuccess,proto3" json:"is_success,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -461,7 +646,7 @@ func (m *ProberAgentIpReportResponse) Reset()         { *m = ProberAgentIpReport
 func (m *ProberAgentIpReportResponse) String() string { return proto.CompactTextString(m) }
 func (*ProberAgentIpReportResponse) ProtoMessage()    {}
 func (*ProberAgentIpReportResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_802a4ee07f8d018d, []int{7}
+	return fileDescriptor_802a4ee07f8d018d, []int{8}
 }
 func (m *ProberAgentIpReportResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -497,874 +682,7197 @@ func (m *ProberAgentIpReportResponse) GetIsSuccess() bool {
 	return false
 }
 
-func init() {
-	proto.RegisterType((*ProberTargetsGetRequest)(nil), "pb.ProberTargetsGetRequest")
-	proto.RegisterType((*Targets)(nil), "pb.Targets")
-	proto.RegisterType((*ProberTargetsGetResponse)(nil), "pb.ProberTargetsGetResponse")
-	proto.RegisterType((*ProberResultPushRequest)(nil), "pb.ProberResultPushRequest")
-	proto.RegisterType((*ProberResultOne)(nil), "pb.ProberResultOne")
-	proto.RegisterType((*ProberResultPushResponse)(nil), "pb.ProberResultPushResponse")
-	proto.RegisterType((*ProberAgentIpReportRequest)(nil), "pb.ProberAgentIpReportRequest")
-	proto.RegisterType((*ProberAgentIpReportResponse)(nil), "pb.ProberAgentIpReportResponse")
+// ProberAgentDeregister is sent by an agent shutting down
+type ProberAgentDeregisterRequest struct {
+	Ip                   string   `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Region               string   `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("prober.proto", fileDescriptor_802a4ee07f8d018d) }
-
-var fileDescriptor_802a4ee07f8d018d = []byte{
-	// 545 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x94, 0xcf, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0x63, 0x87, 0xfc, 0x9b, 0x24, 0x6d, 0xd8, 0x56, 0x60, 0x52, 0xf2, 0x87, 0xad, 0x90,
-	0x72, 0xca, 0x21, 0xdc, 0x80, 0x4b, 0x10, 0x52, 0xd5, 0x4b, 0x1b, 0x6d, 0x8b, 0x10, 0x5c, 0x2c,
-	0x27, 0x59, 0x05, 0x8b, 0xd8, 0x5e, 0x76, 0xd7, 0xa0, 0xbe, 0x06, 0x27, 0x1e, 0x89, 0x23, 0x8f,
-	0x80, 0xc2, 0x8b, 0x20, 0xef, 0xac, 0x5b, 0x37, 0x49, 0x8f, 0xfb, 0xdb, 0xcf, 0xdf, 0xcc, 0x7e,
-	0x33, 0x09, 0xb4, 0x84, 0x4c, 0xe6, 0x5c, 0x8e, 0x85, 0x4c, 0x74, 0x42, 0x5c, 0x31, 0xa7, 0x1f,
-	0xe1, 0xe9, 0xcc, 0xb0, 0xeb, 0x40, 0xae, 0xb8, 0x56, 0x67, 0x5c, 0x33, 0xfe, 0x2d, 0xe5, 0x4a,
-	0x93, 0x17, 0xd0, 0x5a, 0x27, 0x8b, 0x60, 0xed, 0x4b, 0xbe, 0x0a, 0x93, 0xd8, 0x73, 0x86, 0xce,
-	0xa8, 0xc1, 0x9a, 0x86, 0x31, 0x83, 0xc8, 0x33, 0xa8, 0xa3, 0x24, 0x14, 0x9e, 0x6b, 0xae, 0x6b,
-	0xe6, 0x7c, 0x2e, 0xe8, 0x67, 0xa8, 0x59, 0x4b, 0x32, 0x80, 0x26, 0xd6, 0xf5, 0xf5, 0x8d, 0xe0,
-	0xd6, 0x07, 0x10, 0x5d, 0xdf, 0x08, 0x4e, 0x9e, 0x40, 0xd5, 0xd6, 0x40, 0x13, 0x7b, 0xca, 0xb8,
-	0x36, 0x1e, 0x5e, 0x79, 0x58, 0xce, 0x38, 0x9e, 0xe8, 0x14, 0xbc, 0xdd, 0xa6, 0x95, 0x48, 0x62,
-	0xc5, 0xc9, 0x4b, 0xa8, 0xa1, 0x4a, 0x79, 0xce, 0xb0, 0x3c, 0x6a, 0x4e, 0x9a, 0x63, 0x31, 0x1f,
-	0x5b, 0x21, 0xcb, 0xef, 0xe8, 0x87, 0xfc, 0xdd, 0x8c, 0xab, 0x74, 0xad, 0x67, 0xa9, 0xfa, 0x92,
-	0xbf, 0xfb, 0x35, 0x1c, 0xd8, 0x76, 0xa5, 0xb9, 0xcb, 0x8d, 0x8e, 0x32, 0xa3, 0xe2, 0x47, 0x97,
-	0x31, 0x67, 0x6d, 0x51, 0x00, 0x8a, 0xfe, 0x74, 0xe1, 0x70, 0x4b, 0x92, 0x3d, 0xff, 0x47, 0x22,
-	0xbf, 0x72, 0xe9, 0xc7, 0x41, 0x74, 0xfb, 0x7c, 0x44, 0x17, 0x41, 0x64, 0x04, 0x11, 0xd7, 0x32,
-	0x5c, 0xa0, 0x00, 0x33, 0x00, 0x44, 0xb9, 0x00, 0xfb, 0xf6, 0x83, 0xe5, 0x52, 0x7a, 0x65, 0x14,
-	0x20, 0x9a, 0x2e, 0x97, 0x92, 0x9c, 0x42, 0x5b, 0x25, 0xa9, 0x5c, 0xf0, 0x7c, 0x56, 0x8f, 0x8c,
-	0xa4, 0x85, 0xd0, 0x0e, 0xeb, 0x14, 0xda, 0xd6, 0xc5, 0x8a, 0x2a, 0x28, 0x42, 0x68, 0x45, 0x3d,
-	0xc0, 0xc1, 0xe0, 0xa8, 0xaa, 0x46, 0xd1, 0x30, 0xc4, 0x4c, 0xaa, 0x07, 0xa0, 0xc3, 0x88, 0xfb,
-	0x4a, 0x07, 0x91, 0xf0, 0x6a, 0x43, 0x67, 0x54, 0x66, 0x8d, 0x8c, 0x5c, 0x65, 0x80, 0x1c, 0x43,
-	0xe5, 0x7b, 0xb0, 0x4e, 0xb9, 0x57, 0x1f, 0x3a, 0x23, 0x97, 0xe1, 0x81, 0xbe, 0xc9, 0xc7, 0x55,
-	0xcc, 0xda, 0x8e, 0x6b, 0x00, 0x4d, 0x95, 0x2e, 0x16, 0x5c, 0x29, 0x3f, 0x4e, 0x23, 0x13, 0x4e,
-	0x85, 0x81, 0x45, 0x17, 0x69, 0x44, 0xdf, 0x43, 0x17, 0x3f, 0x9e, 0xae, 0x78, 0xac, 0xcf, 0x05,
-	0xe3, 0x22, 0x91, 0xb7, 0x3b, 0x7a, 0x00, 0x6e, 0x28, 0x6c, 0xa4, 0x6e, 0x28, 0x1e, 0xda, 0x24,
-	0xfa, 0x16, 0x4e, 0xf6, 0xba, 0xd8, 0x2e, 0x7a, 0x00, 0xa1, 0xf2, 0x6d, 0x55, 0x63, 0x57, 0x67,
-	0x8d, 0x50, 0x5d, 0x21, 0x98, 0xcc, 0xe1, 0xf0, 0x8c, 0xeb, 0xe2, 0xca, 0x91, 0x4b, 0xe8, 0x6c,
-	0x21, 0x45, 0x4e, 0xee, 0x16, 0x64, 0xe7, 0xd7, 0xd4, 0x7d, 0xbe, 0xff, 0x12, 0x1b, 0xa0, 0xa5,
-	0xc9, 0x12, 0x3a, 0x59, 0x30, 0xc5, 0xa0, 0xc8, 0x0c, 0x1e, 0x6f, 0xb3, 0x7b, 0x55, 0x76, 0x76,
-	0xb7, 0x58, 0x65, 0x37, 0x6c, 0x5a, 0x9a, 0x08, 0x38, 0xda, 0x93, 0x03, 0xf9, 0x04, 0xc7, 0x7b,
-	0xb0, 0x22, 0xfd, 0x3b, 0xbb, 0x7d, 0xf1, 0x77, 0x07, 0x0f, 0xde, 0xe7, 0x15, 0xdf, 0x75, 0x7e,
-	0x6f, 0xfa, 0xce, 0x9f, 0x4d, 0xdf, 0xf9, 0xbb, 0xe9, 0x3b, 0xbf, 0xfe, 0xf5, 0x4b, 0xf3, 0xaa,
-	0xf9, 0xf7, 0x79, 0xf5, 0x3f, 0x00, 0x00, 0xff, 0xff, 0x20, 0x62, 0x33, 0x1a, 0x8d, 0x04, 0x00,
-	0x00,
+func (m *ProberAgentDeregisterRequest) Reset()         { *m = ProberAgentDeregisterRequest{} }
+func (m *ProberAgentDeregisterRequest) String() string { return proto.CompactTextString(m) }
+func (*ProberAgentDeregisterRequest) ProtoMessage()    {}
+func (*ProberAgentDeregisterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{9}
+}
+func (m *ProberAgentDeregisterRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ProberAgentDeregisterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ProberAgentDeregisterRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ProberAgentDeregisterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProberAgentDeregisterRequest.Merge(m, src)
+}
+func (m *ProberAgentDeregisterRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ProberAgentDeregisterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProberAgentDeregisterRequest.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_ProberAgentDeregisterRequest proto.InternalMessageInfo
 
-// GetProberTargetClient is the client API for GetProberTarget service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type GetProberTargetClient interface {
-	// Sends Get ProberTargets request
-	GetProberTargets(ctx context.Context, in *ProberTargetsGetRequest, opts ...grpc.CallOption) (*ProberTargetsGetResponse, error)
+func (m *ProberAgentDeregisterRequest) GetIp() string {
+	if m != nil {
+		return m.Ip
+	}
+	return ""
 }
 
-type getProberTargetClient struct {
-	cc *grpc.ClientConn
+func (m *ProberAgentDeregisterRequest) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
 }
 
-func NewGetProberTargetClient(cc *grpc.ClientConn) GetProberTargetClient {
-	return &getProberTargetClient{cc}
+// TargetGroup is a named list of targets of one prober type and region
+type TargetGroup struct {
+	Name       string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ProberType string   `protobuf:"bytes,2,opt,name=prober_type,json=proberType,proto3" json:"prober_type,omitempty"`
+	Region     string   `protobuf:"bytes,3,opt,name=region,proto3" json:"region,omitempty"`
+	Target     []string `protobuf:"bytes,4,rep,name=target,proto3" json:"target,omitempty"`
+	// file or admin, file defined groups are read only
+	Source string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	// unix nanos of the last change, the newest copy wins between replicas
+	UpdatedAt            int64    `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *getProberTargetClient) GetProberTargets(ctx context.Context, in *ProberTargetsGetRequest, opts ...grpc.CallOption) (*ProberTargetsGetResponse, error) {
-	out := new(ProberTargetsGetResponse)
-	err := c.cc.Invoke(ctx, "/pb.GetProberTarget/GetProberTargets", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *TargetGroup) Reset()         { *m = TargetGroup{} }
+func (m *TargetGroup) String() string { return proto.CompactTextString(m) }
+func (*TargetGroup) ProtoMessage()    {}
+func (*TargetGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{10}
+}
+func (m *TargetGroup) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TargetGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TargetGroup.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return out, nil
 }
-
-// GetProberTargetServer is the server API for GetProberTarget service.
-type GetProberTargetServer interface {
-	// Sends Get ProberTargets request
-	GetProberTargets(context.Context, *ProberTargetsGetRequest) (*ProberTargetsGetResponse, error)
+func (m *TargetGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TargetGroup.Merge(m, src)
 }
-
-// UnimplementedGetProberTargetServer can be embedded to have forward compatible implementations.
-type UnimplementedGetProberTargetServer struct {
+func (m *TargetGroup) XXX_Size() int {
+	return m.Size()
+}
+func (m *TargetGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_TargetGroup.DiscardUnknown(m)
 }
 
-func (*UnimplementedGetProberTargetServer) GetProberTargets(ctx context.Context, req *ProberTargetsGetRequest) (*ProberTargetsGetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetProberTargets not implemented")
+var xxx_messageInfo_TargetGroup proto.InternalMessageInfo
+
+func (m *TargetGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
 }
 
-func RegisterGetProberTargetServer(s *grpc.Server, srv GetProberTargetServer) {
-	s.RegisterService(&_GetProberTarget_serviceDesc, srv)
+func (m *TargetGroup) GetProberType() string {
+	if m != nil {
+		return m.ProberType
+	}
+	return ""
 }
 
-func _GetProberTarget_GetProberTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ProberTargetsGetRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *TargetGroup) GetRegion() string {
+	if m != nil {
+		return m.Region
 	}
-	if interceptor == nil {
-		return srv.(GetProberTargetServer).GetProberTargets(ctx, in)
+	return ""
+}
+
+func (m *TargetGroup) GetTarget() []string {
+	if m != nil {
+		return m.Target
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.GetProberTarget/GetProberTargets",
+	return nil
+}
+
+func (m *TargetGroup) GetSource() string {
+	if m != nil {
+		return m.Source
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GetProberTargetServer).GetProberTargets(ctx, req.(*ProberTargetsGetRequest))
+	return ""
+}
+
+func (m *TargetGroup) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-var _GetProberTarget_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.GetProberTarget",
-	HandlerType: (*GetProberTargetServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetProberTargets",
-			Handler:    _GetProberTarget_GetProberTargets_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "prober.proto",
+type AdminListTargetGroupsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// PushProberResultClient is the client API for PushProberResult service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type PushProberResultClient interface {
-	// Sends Get ProberTargets request
-	PushProberResults(ctx context.Context, in *ProberResultPushRequest, opts ...grpc.CallOption) (*ProberResultPushResponse, error)
+func (m *AdminListTargetGroupsRequest) Reset()         { *m = AdminListTargetGroupsRequest{} }
+func (m *AdminListTargetGroupsRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminListTargetGroupsRequest) ProtoMessage()    {}
+func (*AdminListTargetGroupsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{11}
 }
-
-type pushProberResultClient struct {
-	cc *grpc.ClientConn
+func (m *AdminListTargetGroupsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminListTargetGroupsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminListTargetGroupsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AdminListTargetGroupsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListTargetGroupsRequest.Merge(m, src)
+}
+func (m *AdminListTargetGroupsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminListTargetGroupsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListTargetGroupsRequest.DiscardUnknown(m)
 }
 
-func NewPushProberResultClient(cc *grpc.ClientConn) PushProberResultClient {
-	return &pushProberResultClient{cc}
+var xxx_messageInfo_AdminListTargetGroupsRequest proto.InternalMessageInfo
+
+type AdminListTargetGroupsResponse struct {
+	Groups               []*TargetGroup `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-func (c *pushProberResultClient) PushProberResults(ctx context.Context, in *ProberResultPushRequest, opts ...grpc.CallOption) (*ProberResultPushResponse, error) {
-	out := new(ProberResultPushResponse)
-	err := c.cc.Invoke(ctx, "/pb.PushProberResult/PushProberResults", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *AdminListTargetGroupsResponse) Reset()         { *m = AdminListTargetGroupsResponse{} }
+func (m *AdminListTargetGroupsResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminListTargetGroupsResponse) ProtoMessage()    {}
+func (*AdminListTargetGroupsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{12}
+}
+func (m *AdminListTargetGroupsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminListTargetGroupsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminListTargetGroupsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return out, nil
 }
-
-// PushProberResultServer is the server API for PushProberResult service.
-type PushProberResultServer interface {
-	// Sends Get ProberTargets request
-	PushProberResults(context.Context, *ProberResultPushRequest) (*ProberResultPushResponse, error)
+func (m *AdminListTargetGroupsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminListTargetGroupsResponse.Merge(m, src)
 }
-
-// UnimplementedPushProberResultServer can be embedded to have forward compatible implementations.
-type UnimplementedPushProberResultServer struct {
+func (m *AdminListTargetGroupsResponse) XXX_Size() int {
+	return m.Size()
 }
-
-func (*UnimplementedPushProberResultServer) PushProberResults(ctx context.Context, req *ProberResultPushRequest) (*ProberResultPushResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PushProberResults not implemented")
+func (m *AdminListTargetGroupsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminListTargetGroupsResponse.DiscardUnknown(m)
 }
 
-func RegisterPushProberResultServer(s *grpc.Server, srv PushProberResultServer) {
-	s.RegisterService(&_PushProberResult_serviceDesc, srv)
-}
+var xxx_messageInfo_AdminListTargetGroupsResponse proto.InternalMessageInfo
 
-func _PushProberResult_PushProberResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ProberResultPushRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(PushProberResultServer).PushProberResults(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.PushProberResult/PushProberResults",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PushProberResultServer).PushProberResults(ctx, req.(*ProberResultPushRequest))
+func (m *AdminListTargetGroupsResponse) GetGroups() []*TargetGroup {
+	if m != nil {
+		return m.Groups
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _PushProberResult_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.PushProberResult",
-	HandlerType: (*PushProberResultServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "PushProberResults",
-			Handler:    _PushProberResult_PushProberResults_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "prober.proto",
+type AdminUpsertTargetGroupRequest struct {
+	Group                *TargetGroup `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-// ProberAgentIpReportClient is the client API for ProberAgentIpReport service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ProberAgentIpReportClient interface {
-	// Sends Get ProberTargets request
-	ProberAgentIpReports(ctx context.Context, in *ProberAgentIpReportRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error)
+func (m *AdminUpsertTargetGroupRequest) Reset()         { *m = AdminUpsertTargetGroupRequest{} }
+func (m *AdminUpsertTargetGroupRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminUpsertTargetGroupRequest) ProtoMessage()    {}
+func (*AdminUpsertTargetGroupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{13}
 }
-
-type proberAgentIpReportClient struct {
-	cc *grpc.ClientConn
+func (m *AdminUpsertTargetGroupRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-func NewProberAgentIpReportClient(cc *grpc.ClientConn) ProberAgentIpReportClient {
-	return &proberAgentIpReportClient{cc}
+func (m *AdminUpsertTargetGroupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminUpsertTargetGroupRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AdminUpsertTargetGroupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminUpsertTargetGroupRequest.Merge(m, src)
+}
+func (m *AdminUpsertTargetGroupRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminUpsertTargetGroupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminUpsertTargetGroupRequest.DiscardUnknown(m)
 }
 
-func (c *proberAgentIpReportClient) ProberAgentIpReports(ctx context.Context, in *ProberAgentIpReportRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error) {
-	out := new(ProberAgentIpReportResponse)
-	err := c.cc.Invoke(ctx, "/pb.ProberAgentIpReport/ProberAgentIpReports", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_AdminUpsertTargetGroupRequest proto.InternalMessageInfo
+
+func (m *AdminUpsertTargetGroupRequest) GetGroup() *TargetGroup {
+	if m != nil {
+		return m.Group
 	}
-	return out, nil
+	return nil
 }
 
-// ProberAgentIpReportServer is the server API for ProberAgentIpReport service.
-type ProberAgentIpReportServer interface {
-	// Sends Get ProberTargets request
-	ProberAgentIpReports(context.Context, *ProberAgentIpReportRequest) (*ProberAgentIpReportResponse, error)
+type AdminDeleteTargetGroupRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// UnimplementedProberAgentIpReportServer can be embedded to have forward compatible implementations.
-type UnimplementedProberAgentIpReportServer struct {
+func (m *AdminDeleteTargetGroupRequest) Reset()         { *m = AdminDeleteTargetGroupRequest{} }
+func (m *AdminDeleteTargetGroupRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminDeleteTargetGroupRequest) ProtoMessage()    {}
+func (*AdminDeleteTargetGroupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{14}
 }
-
-func (*UnimplementedProberAgentIpReportServer) ProberAgentIpReports(ctx context.Context, req *ProberAgentIpReportRequest) (*ProberAgentIpReportResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ProberAgentIpReports not implemented")
+func (m *AdminDeleteTargetGroupRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-func RegisterProberAgentIpReportServer(s *grpc.Server, srv ProberAgentIpReportServer) {
-	s.RegisterService(&_ProberAgentIpReport_serviceDesc, srv)
+func (m *AdminDeleteTargetGroupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminDeleteTargetGroupRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AdminDeleteTargetGroupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminDeleteTargetGroupRequest.Merge(m, src)
+}
+func (m *AdminDeleteTargetGroupRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminDeleteTargetGroupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminDeleteTargetGroupRequest.DiscardUnknown(m)
 }
 
-func _ProberAgentIpReport_ProberAgentIpReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ProberAgentIpReportRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ProberAgentIpReportServer).ProberAgentIpReports(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.ProberAgentIpReport/ProberAgentIpReports",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ProberAgentIpReportServer).ProberAgentIpReports(ctx, req.(*ProberAgentIpReportRequest))
+var xxx_messageInfo_AdminDeleteTargetGroupRequest proto.InternalMessageInfo
+
+func (m *AdminDeleteTargetGroupRequest) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-var _ProberAgentIpReport_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.ProberAgentIpReport",
-	HandlerType: (*ProberAgentIpReportServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "ProberAgentIpReports",
-			Handler:    _ProberAgentIpReport_ProberAgentIpReports_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "prober.proto",
+type AdminTargetsRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Target               []string `protobuf:"bytes,2,rep,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ProberTargetsGetRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *AdminTargetsRequest) Reset()         { *m = AdminTargetsRequest{} }
+func (m *AdminTargetsRequest) String() string { return proto.CompactTextString(m) }
+func (*AdminTargetsRequest) ProtoMessage()    {}
+func (*AdminTargetsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{15}
+}
+func (m *AdminTargetsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminTargetsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminTargetsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return dAtA[:n], nil
 }
-
-func (m *ProberTargetsGetRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *AdminTargetsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminTargetsRequest.Merge(m, src)
+}
+func (m *AdminTargetsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminTargetsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminTargetsRequest.DiscardUnknown(m)
 }
 
-func (m *ProberTargetsGetRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if len(m.LocalIp) > 0 {
-		i -= len(m.LocalIp)
-		copy(dAtA[i:], m.LocalIp)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.LocalIp)))
-		i--
-		dAtA[i] = 0x12
-	}
-	if len(m.LocalRegion) > 0 {
-		i -= len(m.LocalRegion)
-		copy(dAtA[i:], m.LocalRegion)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.LocalRegion)))
-		i--
-		dAtA[i] = 0xa
+var xxx_messageInfo_AdminTargetsRequest proto.InternalMessageInfo
+
+func (m *AdminTargetsRequest) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func (m *Targets) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *AdminTargetsRequest) GetTarget() []string {
+	if m != nil {
+		return m.Target
 	}
-	return dAtA[:n], nil
+	return nil
 }
 
-func (m *Targets) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type AdminResponse struct {
+	IsSuccess            bool     `protobuf:"varint,1,opt,name=is_success,json=isSuccess,proto3" json:"is_success,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Targets) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if len(m.Target) > 0 {
-		for iNdEx := len(m.Target) - 1; iNdEx >= 0; iNdEx-- {
-			i -= len(m.Target[iNdEx])
-			copy(dAtA[i:], m.Target[iNdEx])
-			i = encodeVarintProber(dAtA, i, uint64(len(m.Target[iNdEx])))
-			i--
-			dAtA[i] = 0x1a
+func (m *AdminResponse) Reset()         { *m = AdminResponse{} }
+func (m *AdminResponse) String() string { return proto.CompactTextString(m) }
+func (*AdminResponse) ProtoMessage()    {}
+func (*AdminResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{16}
+}
+func (m *AdminResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdminResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdminResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if len(m.Region) > 0 {
-		i -= len(m.Region)
-		copy(dAtA[i:], m.Region)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
-		i--
-		dAtA[i] = 0x12
-	}
-	if len(m.ProberType) > 0 {
-		i -= len(m.ProberType)
-		copy(dAtA[i:], m.ProberType)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.ProberType)))
-		i--
-		dAtA[i] = 0xa
+}
+func (m *AdminResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdminResponse.Merge(m, src)
+}
+func (m *AdminResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdminResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdminResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AdminResponse proto.InternalMessageInfo
+
+func (m *AdminResponse) GetIsSuccess() bool {
+	if m != nil {
+		return m.IsSuccess
 	}
-	return len(dAtA) - i, nil
+	return false
 }
 
-func (m *ProberTargetsGetResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *AdminResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberTargetsGetResponse) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+// AdhocAgentMessage is sent by the agent on the adhoc stream, the first
+// message only carries ip and region to register the agent
+type AdhocAgentMessage struct {
+	Ip                   string             `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Region               string             `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	JobId                string             `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Results              []*ProberResultOne `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
+	Error                string             `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
-func (m *ProberTargetsGetResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if len(m.Targets) > 0 {
-		for iNdEx := len(m.Targets) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Targets[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
-				i -= size
-				i = encodeVarintProber(dAtA, i, uint64(size))
-			}
-			i--
-			dAtA[i] = 0xa
+func (m *AdhocAgentMessage) Reset()         { *m = AdhocAgentMessage{} }
+func (m *AdhocAgentMessage) String() string { return proto.CompactTextString(m) }
+func (*AdhocAgentMessage) ProtoMessage()    {}
+func (*AdhocAgentMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{17}
+}
+func (m *AdhocAgentMessage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdhocAgentMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdhocAgentMessage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	return len(dAtA) - i, nil
+}
+func (m *AdhocAgentMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdhocAgentMessage.Merge(m, src)
+}
+func (m *AdhocAgentMessage) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdhocAgentMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdhocAgentMessage.DiscardUnknown(m)
 }
 
-func (m *ProberResultPushRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_AdhocAgentMessage proto.InternalMessageInfo
+
+func (m *AdhocAgentMessage) GetIp() string {
+	if m != nil {
+		return m.Ip
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberResultPushRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *AdhocAgentMessage) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
 }
 
-func (m *ProberResultPushRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
+func (m *AdhocAgentMessage) GetJobId() string {
+	if m != nil {
+		return m.JobId
 	}
-	if len(m.ProberResults) > 0 {
-		for iNdEx := len(m.ProberResults) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.ProberResults[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
-				i -= size
-				i = encodeVarintProber(dAtA, i, uint64(size))
-			}
-			i--
-			dAtA[i] = 0xa
-		}
+	return ""
+}
+
+func (m *AdhocAgentMessage) GetResults() []*ProberResultOne {
+	if m != nil {
+		return m.Results
 	}
-	return len(dAtA) - i, nil
+	return nil
 }
 
-func (m *ProberResultOne) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *AdhocAgentMessage) GetError() string {
+	if m != nil {
+		return m.Error
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberResultOne) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+// AdhocProbeJob is a one-shot probe dispatched by the server
+type AdhocProbeJob struct {
+	JobId                string   `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	ProberType           string   `protobuf:"bytes,2,opt,name=prober_type,json=proberType,proto3" json:"prober_type,omitempty"`
+	Target               string   `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	TargetRegion         string   `protobuf:"bytes,4,opt,name=target_region,json=targetRegion,proto3" json:"target_region,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ProberResultOne) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if m.Value != 0 {
-		i -= 4
-		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Value))))
-		i--
-		dAtA[i] = 0x45
-	}
-	if m.TimeStamp != 0 {
-		i = encodeVarintProber(dAtA, i, uint64(m.TimeStamp))
-		i--
-		dAtA[i] = 0x38
-	}
-	if len(m.ProbeType) > 0 {
-		i -= len(m.ProbeType)
-		copy(dAtA[i:], m.ProbeType)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.ProbeType)))
-		i--
-		dAtA[i] = 0x32
-	}
-	if len(m.TargetRegion) > 0 {
-		i -= len(m.TargetRegion)
-		copy(dAtA[i:], m.TargetRegion)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetRegion)))
-		i--
-		dAtA[i] = 0x2a
-	}
-	if len(m.SourceRegion) > 0 {
-		i -= len(m.SourceRegion)
-		copy(dAtA[i:], m.SourceRegion)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.SourceRegion)))
-		i--
-		dAtA[i] = 0x22
-	}
-	if len(m.TargetAddr) > 0 {
-		i -= len(m.TargetAddr)
-		copy(dAtA[i:], m.TargetAddr)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetAddr)))
-		i--
-		dAtA[i] = 0x1a
-	}
-	if len(m.MetricName) > 0 {
-		i -= len(m.MetricName)
-		copy(dAtA[i:], m.MetricName)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.MetricName)))
-		i--
-		dAtA[i] = 0x12
-	}
-	if len(m.WorkerName) > 0 {
-		i -= len(m.WorkerName)
-		copy(dAtA[i:], m.WorkerName)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.WorkerName)))
-		i--
-		dAtA[i] = 0xa
+func (m *AdhocProbeJob) Reset()         { *m = AdhocProbeJob{} }
+func (m *AdhocProbeJob) String() string { return proto.CompactTextString(m) }
+func (*AdhocProbeJob) ProtoMessage()    {}
+func (*AdhocProbeJob) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{18}
+}
+func (m *AdhocProbeJob) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdhocProbeJob) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdhocProbeJob.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return len(dAtA) - i, nil
+}
+func (m *AdhocProbeJob) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdhocProbeJob.Merge(m, src)
+}
+func (m *AdhocProbeJob) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdhocProbeJob) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdhocProbeJob.DiscardUnknown(m)
 }
 
-func (m *ProberResultPushResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_AdhocProbeJob proto.InternalMessageInfo
+
+func (m *AdhocProbeJob) GetJobId() string {
+	if m != nil {
+		return m.JobId
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberResultPushResponse) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *AdhocProbeJob) GetProberType() string {
+	if m != nil {
+		return m.ProberType
+	}
+	return ""
 }
 
-func (m *ProberResultPushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if m.SuccessNum != 0 {
-		i = encodeVarintProber(dAtA, i, uint64(m.SuccessNum))
-		i--
-		dAtA[i] = 0x8
+func (m *AdhocProbeJob) GetTarget() string {
+	if m != nil {
+		return m.Target
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func (m *ProberAgentIpReportRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *AdhocProbeJob) GetTargetRegion() string {
+	if m != nil {
+		return m.TargetRegion
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberAgentIpReportRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type AdhocProbeRequest struct {
+	ProberType string `protobuf:"bytes,1,opt,name=prober_type,json=proberType,proto3" json:"prober_type,omitempty"`
+	Target     string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// agents of this region run the probe, all agents when empty
+	SourceRegion string `protobuf:"bytes,3,opt,name=source_region,json=sourceRegion,proto3" json:"source_region,omitempty"`
+	// only this agent, optional
+	AgentIp              string   `protobuf:"bytes,4,opt,name=agent_ip,json=agentIp,proto3" json:"agent_ip,omitempty"`
+	TargetRegion         string   `protobuf:"bytes,5,opt,name=target_region,json=targetRegion,proto3" json:"target_region,omitempty"`
+	TimeoutSeconds       int32    `protobuf:"varint,6,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ProberAgentIpReportRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if len(m.Region) > 0 {
-		i -= len(m.Region)
-		copy(dAtA[i:], m.Region)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
-		i--
-		dAtA[i] = 0x12
-	}
-	if len(m.Ip) > 0 {
-		i -= len(m.Ip)
-		copy(dAtA[i:], m.Ip)
-		i = encodeVarintProber(dAtA, i, uint64(len(m.Ip)))
-		i--
-		dAtA[i] = 0xa
+func (m *AdhocProbeRequest) Reset()         { *m = AdhocProbeRequest{} }
+func (m *AdhocProbeRequest) String() string { return proto.CompactTextString(m) }
+func (*AdhocProbeRequest) ProtoMessage()    {}
+func (*AdhocProbeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{19}
+}
+func (m *AdhocProbeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdhocProbeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdhocProbeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return len(dAtA) - i, nil
+}
+func (m *AdhocProbeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdhocProbeRequest.Merge(m, src)
+}
+func (m *AdhocProbeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdhocProbeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdhocProbeRequest.DiscardUnknown(m)
 }
 
-func (m *ProberAgentIpReportResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_AdhocProbeRequest proto.InternalMessageInfo
+
+func (m *AdhocProbeRequest) GetProberType() string {
+	if m != nil {
+		return m.ProberType
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *ProberAgentIpReportResponse) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *AdhocProbeRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
 }
 
-func (m *ProberAgentIpReportResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i -= len(m.XXX_unrecognized)
-		copy(dAtA[i:], m.XXX_unrecognized)
-	}
-	if m.IsSuccess {
-		i--
-		if m.IsSuccess {
-			dAtA[i] = 1
-		} else {
-			dAtA[i] = 0
-		}
-		i--
-		dAtA[i] = 0x8
+func (m *AdhocProbeRequest) GetSourceRegion() string {
+	if m != nil {
+		return m.SourceRegion
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func encodeVarintProber(dAtA []byte, offset int, v uint64) int {
-	offset -= sovProber(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *AdhocProbeRequest) GetAgentIp() string {
+	if m != nil {
+		return m.AgentIp
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return ""
 }
-func (m *ProberTargetsGetRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.LocalRegion)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
-	}
-	l = len(m.LocalIp)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+
+func (m *AdhocProbeRequest) GetTargetRegion() string {
+	if m != nil {
+		return m.TargetRegion
 	}
-	return n
+	return ""
 }
 
-func (m *Targets) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.ProberType)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
-	}
-	l = len(m.Region)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+func (m *AdhocProbeRequest) GetTimeoutSeconds() int32 {
+	if m != nil {
+		return m.TimeoutSeconds
 	}
-	if len(m.Target) > 0 {
-		for _, s := range m.Target {
-			l = len(s)
-			n += 1 + l + sovProber(uint64(l))
+	return 0
+}
+
+type AdhocAgentResult struct {
+	Ip                   string             `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Region               string             `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Results              []*ProberResultOne `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	Error                string             `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *AdhocAgentResult) Reset()         { *m = AdhocAgentResult{} }
+func (m *AdhocAgentResult) String() string { return proto.CompactTextString(m) }
+func (*AdhocAgentResult) ProtoMessage()    {}
+func (*AdhocAgentResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{20}
+}
+func (m *AdhocAgentResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdhocAgentResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdhocAgentResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
-	}
-	return n
+}
+func (m *AdhocAgentResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdhocAgentResult.Merge(m, src)
+}
+func (m *AdhocAgentResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdhocAgentResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdhocAgentResult.DiscardUnknown(m)
 }
 
-func (m *ProberTargetsGetResponse) Size() (n int) {
-	if m == nil {
-		return 0
+var xxx_messageInfo_AdhocAgentResult proto.InternalMessageInfo
+
+func (m *AdhocAgentResult) GetIp() string {
+	if m != nil {
+		return m.Ip
 	}
-	var l int
-	_ = l
-	if len(m.Targets) > 0 {
-		for _, e := range m.Targets {
-			l = e.Size()
-			n += 1 + l + sovProber(uint64(l))
-		}
+	return ""
+}
+
+func (m *AdhocAgentResult) GetRegion() string {
+	if m != nil {
+		return m.Region
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	return ""
+}
+
+func (m *AdhocAgentResult) GetResults() []*ProberResultOne {
+	if m != nil {
+		return m.Results
 	}
-	return n
+	return nil
 }
 
-func (m *ProberResultPushRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *AdhocAgentResult) GetError() string {
+	if m != nil {
+		return m.Error
 	}
-	var l int
-	_ = l
-	if len(m.ProberResults) > 0 {
-		for _, e := range m.ProberResults {
-			l = e.Size()
-			n += 1 + l + sovProber(uint64(l))
+	return ""
+}
+
+type AdhocProbeResponse struct {
+	JobId                string              `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Results              []*AdhocAgentResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *AdhocProbeResponse) Reset()         { *m = AdhocProbeResponse{} }
+func (m *AdhocProbeResponse) String() string { return proto.CompactTextString(m) }
+func (*AdhocProbeResponse) ProtoMessage()    {}
+func (*AdhocProbeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{21}
+}
+func (m *AdhocProbeResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AdhocProbeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AdhocProbeResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
-	}
-	return n
+}
+func (m *AdhocProbeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AdhocProbeResponse.Merge(m, src)
+}
+func (m *AdhocProbeResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *AdhocProbeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AdhocProbeResponse.DiscardUnknown(m)
 }
 
-func (m *ProberResultOne) Size() (n int) {
-	if m == nil {
-		return 0
+var xxx_messageInfo_AdhocProbeResponse proto.InternalMessageInfo
+
+func (m *AdhocProbeResponse) GetJobId() string {
+	if m != nil {
+		return m.JobId
 	}
-	var l int
-	_ = l
-	l = len(m.WorkerName)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return ""
+}
+
+func (m *AdhocProbeResponse) GetResults() []*AdhocAgentResult {
+	if m != nil {
+		return m.Results
 	}
-	l = len(m.MetricName)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return nil
+}
+
+type ClusterMember struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Addr                 string   `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterMember) Reset()         { *m = ClusterMember{} }
+func (m *ClusterMember) String() string { return proto.CompactTextString(m) }
+func (*ClusterMember) ProtoMessage()    {}
+func (*ClusterMember) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{22}
+}
+func (m *ClusterMember) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterMember) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterMember.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	l = len(m.TargetAddr)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+}
+func (m *ClusterMember) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterMember.Merge(m, src)
+}
+func (m *ClusterMember) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterMember) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterMember.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterMember proto.InternalMessageInfo
+
+func (m *ClusterMember) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	l = len(m.SourceRegion)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return ""
+}
+
+func (m *ClusterMember) GetAddr() string {
+	if m != nil {
+		return m.Addr
 	}
-	l = len(m.TargetRegion)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return ""
+}
+
+type ClusterAgent struct {
+	// the agent's last inventory report
+	Report         *ProberAgentIpReportRequest `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	LastReport     int64                       `protobuf:"varint,2,opt,name=last_report,json=lastReport,proto3" json:"last_report,omitempty"`
+	LastTargetsGet int64                       `protobuf:"varint,3,opt,name=last_targets_get,json=lastTargetsGet,proto3" json:"last_targets_get,omitempty"`
+	LastPush       int64                       `protobuf:"varint,4,opt,name=last_push,json=lastPush,proto3" json:"last_push,omitempty"`
+	CertIdentity   string                      `protobuf:"bytes,5,opt,name=cert_identity,json=certIdentity,proto3" json:"cert_identity,omitempty"`
+	// unix time the agent deregistered, it is gone unless it reported after
+	Deregistered         int64    `protobuf:"varint,6,opt,name=deregistered,proto3" json:"deregistered,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterAgent) Reset()         { *m = ClusterAgent{} }
+func (m *ClusterAgent) String() string { return proto.CompactTextString(m) }
+func (*ClusterAgent) ProtoMessage()    {}
+func (*ClusterAgent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{23}
+}
+func (m *ClusterAgent) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterAgent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterAgent.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	l = len(m.ProbeType)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+}
+func (m *ClusterAgent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterAgent.Merge(m, src)
+}
+func (m *ClusterAgent) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterAgent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterAgent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterAgent proto.InternalMessageInfo
+
+func (m *ClusterAgent) GetReport() *ProberAgentIpReportRequest {
+	if m != nil {
+		return m.Report
 	}
-	if m.TimeStamp != 0 {
-		n += 1 + sovProber(uint64(m.TimeStamp))
+	return nil
+}
+
+func (m *ClusterAgent) GetLastReport() int64 {
+	if m != nil {
+		return m.LastReport
 	}
-	if m.Value != 0 {
-		n += 5
+	return 0
+}
+
+func (m *ClusterAgent) GetLastTargetsGet() int64 {
+	if m != nil {
+		return m.LastTargetsGet
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	return 0
+}
+
+func (m *ClusterAgent) GetLastPush() int64 {
+	if m != nil {
+		return m.LastPush
 	}
-	return n
+	return 0
 }
 
-func (m *ProberResultPushResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ClusterAgent) GetCertIdentity() string {
+	if m != nil {
+		return m.CertIdentity
 	}
-	var l int
-	_ = l
-	if m.SuccessNum != 0 {
-		n += 1 + sovProber(uint64(m.SuccessNum))
+	return ""
+}
+
+func (m *ClusterAgent) GetDeregistered() int64 {
+	if m != nil {
+		return m.Deregistered
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	return 0
+}
+
+type ClusterTargetGroup struct {
+	Group *TargetGroup `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// tombstone of a deleted group, updated_at is the delete time
+	Deleted              bool     `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterTargetGroup) Reset()         { *m = ClusterTargetGroup{} }
+func (m *ClusterTargetGroup) String() string { return proto.CompactTextString(m) }
+func (*ClusterTargetGroup) ProtoMessage()    {}
+func (*ClusterTargetGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{24}
+}
+func (m *ClusterTargetGroup) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterTargetGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterTargetGroup.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return n
+}
+func (m *ClusterTargetGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterTargetGroup.Merge(m, src)
+}
+func (m *ClusterTargetGroup) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterTargetGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterTargetGroup.DiscardUnknown(m)
 }
 
-func (m *ProberAgentIpReportRequest) Size() (n int) {
-	if m == nil {
-		return 0
+var xxx_messageInfo_ClusterTargetGroup proto.InternalMessageInfo
+
+func (m *ClusterTargetGroup) GetGroup() *TargetGroup {
+	if m != nil {
+		return m.Group
 	}
-	var l int
-	_ = l
-	l = len(m.Ip)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return nil
+}
+
+func (m *ClusterTargetGroup) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
 	}
-	l = len(m.Region)
-	if l > 0 {
-		n += 1 + l + sovProber(uint64(l))
+	return false
+}
+
+// ClusterState is what one replica knows, exchanged both ways on every sync
+type ClusterState struct {
+	Self                 *ClusterMember        `protobuf:"bytes,1,opt,name=self,proto3" json:"self,omitempty"`
+	Members              []*ClusterMember      `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	Agents               []*ClusterAgent       `protobuf:"bytes,3,rep,name=agents,proto3" json:"agents,omitempty"`
+	TargetGroups         []*ClusterTargetGroup `protobuf:"bytes,4,rep,name=target_groups,json=targetGroups,proto3" json:"target_groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ClusterState) Reset()         { *m = ClusterState{} }
+func (m *ClusterState) String() string { return proto.CompactTextString(m) }
+func (*ClusterState) ProtoMessage()    {}
+func (*ClusterState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{25}
+}
+func (m *ClusterState) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterState.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+}
+func (m *ClusterState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterState.Merge(m, src)
+}
+func (m *ClusterState) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterState) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterState proto.InternalMessageInfo
+
+func (m *ClusterState) GetSelf() *ClusterMember {
+	if m != nil {
+		return m.Self
 	}
-	return n
+	return nil
 }
 
-func (m *ProberAgentIpReportResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ClusterState) GetMembers() []*ClusterMember {
+	if m != nil {
+		return m.Members
 	}
-	var l int
-	_ = l
-	if m.IsSuccess {
-		n += 2
+	return nil
+}
+
+func (m *ClusterState) GetAgents() []*ClusterAgent {
+	if m != nil {
+		return m.Agents
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	return nil
+}
+
+func (m *ClusterState) GetTargetGroups() []*ClusterTargetGroup {
+	if m != nil {
+		return m.TargetGroups
 	}
-	return n
+	return nil
 }
 
-func sovProber(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
+type ClusterForwardRequest struct {
+	From                 string             `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	ProberResults        []*ProberResultOne `protobuf:"bytes,2,rep,name=prober_results,json=proberResults,proto3" json:"prober_results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
-func sozProber(x uint64) (n int) {
-	return sovProber(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+func (m *ClusterForwardRequest) Reset()         { *m = ClusterForwardRequest{} }
+func (m *ClusterForwardRequest) String() string { return proto.CompactTextString(m) }
+func (*ClusterForwardRequest) ProtoMessage()    {}
+func (*ClusterForwardRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{26}
 }
-func (m *ProberTargetsGetRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowProber
+func (m *ClusterForwardRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterForwardRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterForwardRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ClusterForwardRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterForwardRequest.Merge(m, src)
+}
+func (m *ClusterForwardRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterForwardRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterForwardRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterForwardRequest proto.InternalMessageInfo
+
+func (m *ClusterForwardRequest) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *ClusterForwardRequest) GetProberResults() []*ProberResultOne {
+	if m != nil {
+		return m.ProberResults
+	}
+	return nil
+}
+
+type ClusterForwardResponse struct {
+	SuccessNum           int32    `protobuf:"varint,1,opt,name=success_num,json=successNum,proto3" json:"success_num,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterForwardResponse) Reset()         { *m = ClusterForwardResponse{} }
+func (m *ClusterForwardResponse) String() string { return proto.CompactTextString(m) }
+func (*ClusterForwardResponse) ProtoMessage()    {}
+func (*ClusterForwardResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_802a4ee07f8d018d, []int{27}
+}
+func (m *ClusterForwardResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ClusterForwardResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ClusterForwardResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ClusterForwardResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterForwardResponse.Merge(m, src)
+}
+func (m *ClusterForwardResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ClusterForwardResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterForwardResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterForwardResponse proto.InternalMessageInfo
+
+func (m *ClusterForwardResponse) GetSuccessNum() int32 {
+	if m != nil {
+		return m.SuccessNum
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*ProberTargetsGetRequest)(nil), "pb.ProberTargetsGetRequest")
+	proto.RegisterType((*Targets)(nil), "pb.Targets")
+	proto.RegisterType((*ProberTargetsGetResponse)(nil), "pb.ProberTargetsGetResponse")
+	proto.RegisterType((*ProberResultPushRequest)(nil), "pb.ProberResultPushRequest")
+	proto.RegisterType((*ProberResultOne)(nil), "pb.ProberResultOne")
+	proto.RegisterType((*ProberResultPushResponse)(nil), "pb.ProberResultPushResponse")
+	proto.RegisterType((*RejectedResult)(nil), "pb.RejectedResult")
+	proto.RegisterType((*ProberAgentIpReportRequest)(nil), "pb.ProberAgentIpReportRequest")
+	proto.RegisterType((*ProberAgentIpReportResponse)(nil), "pb.ProberAgentIpReportResponse")
+	proto.RegisterType((*ProberAgentDeregisterRequest)(nil), "pb.ProberAgentDeregisterRequest")
+	proto.RegisterType((*TargetGroup)(nil), "pb.TargetGroup")
+	proto.RegisterType((*AdminListTargetGroupsRequest)(nil), "pb.AdminListTargetGroupsRequest")
+	proto.RegisterType((*AdminListTargetGroupsResponse)(nil), "pb.AdminListTargetGroupsResponse")
+	proto.RegisterType((*AdminUpsertTargetGroupRequest)(nil), "pb.AdminUpsertTargetGroupRequest")
+	proto.RegisterType((*AdminDeleteTargetGroupRequest)(nil), "pb.AdminDeleteTargetGroupRequest")
+	proto.RegisterType((*AdminTargetsRequest)(nil), "pb.AdminTargetsRequest")
+	proto.RegisterType((*AdminResponse)(nil), "pb.AdminResponse")
+	proto.RegisterType((*AdhocAgentMessage)(nil), "pb.AdhocAgentMessage")
+	proto.RegisterType((*AdhocProbeJob)(nil), "pb.AdhocProbeJob")
+	proto.RegisterType((*AdhocProbeRequest)(nil), "pb.AdhocProbeRequest")
+	proto.RegisterType((*AdhocAgentResult)(nil), "pb.AdhocAgentResult")
+	proto.RegisterType((*AdhocProbeResponse)(nil), "pb.AdhocProbeResponse")
+	proto.RegisterType((*ClusterMember)(nil), "pb.ClusterMember")
+	proto.RegisterType((*ClusterAgent)(nil), "pb.ClusterAgent")
+	proto.RegisterType((*ClusterTargetGroup)(nil), "pb.ClusterTargetGroup")
+	proto.RegisterType((*ClusterState)(nil), "pb.ClusterState")
+	proto.RegisterType((*ClusterForwardRequest)(nil), "pb.ClusterForwardRequest")
+	proto.RegisterType((*ClusterForwardResponse)(nil), "pb.ClusterForwardResponse")
+}
+
+func init() { proto.RegisterFile("prober.proto", fileDescriptor_802a4ee07f8d018d) }
+
+var fileDescriptor_802a4ee07f8d018d = []byte{
+	// 1590 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0x5d, 0x6f, 0x1b, 0x45,
+	0x17, 0xee, 0xda, 0x8e, 0x3f, 0x8e, 0xe3, 0xc4, 0x99, 0x36, 0xe9, 0xd6, 0x4d, 0xd2, 0x74, 0xaa,
+	0xaa, 0x7e, 0xf5, 0xea, 0x8d, 0x5e, 0xa5, 0x12, 0x15, 0x05, 0x2e, 0xdc, 0x96, 0xb6, 0x01, 0xda,
+	0x86, 0x75, 0xab, 0xaa, 0x80, 0x64, 0xad, 0x77, 0xa7, 0xb1, 0x8b, 0xed, 0xdd, 0xcc, 0x8c, 0x53,
+	0x45, 0x42, 0xe2, 0x86, 0x6b, 0xb8, 0x84, 0xbf, 0xc0, 0x7f, 0x80, 0x7b, 0xb8, 0x41, 0x5c, 0x23,
+	0x2e, 0x50, 0xf8, 0x11, 0xdc, 0xa2, 0xf9, 0xda, 0x1d, 0xdb, 0xeb, 0xe2, 0xd2, 0x3b, 0xcf, 0x73,
+	0x9e, 0x39, 0x33, 0xe7, 0x63, 0xce, 0x39, 0x6b, 0x58, 0x8e, 0x69, 0xd4, 0x25, 0x74, 0x37, 0xa6,
+	0x11, 0x8f, 0x50, 0x2e, 0xee, 0xe2, 0xa7, 0x70, 0xfe, 0x40, 0x62, 0x8f, 0x7d, 0x7a, 0x48, 0x38,
+	0xbb, 0x47, 0xb8, 0x47, 0x8e, 0xc6, 0x84, 0x71, 0x74, 0x19, 0x96, 0x07, 0x51, 0xe0, 0x0f, 0x3a,
+	0x94, 0x1c, 0xf6, 0xa3, 0x91, 0xeb, 0xec, 0x38, 0xcd, 0x8a, 0x57, 0x95, 0x98, 0x27, 0x21, 0x74,
+	0x01, 0xca, 0x8a, 0xd2, 0x8f, 0xdd, 0x9c, 0x14, 0x97, 0xe4, 0x7a, 0x3f, 0xc6, 0x9f, 0x40, 0x49,
+	0xab, 0x44, 0x97, 0xa0, 0xaa, 0xce, 0xed, 0xf0, 0x93, 0x98, 0x68, 0x3d, 0xa0, 0xa0, 0xc7, 0x27,
+	0x31, 0x41, 0x1b, 0x50, 0xd4, 0x67, 0x28, 0x25, 0x7a, 0x25, 0x70, 0x2e, 0x75, 0xb8, 0xf9, 0x9d,
+	0xbc, 0xc0, 0xd5, 0x0a, 0xb7, 0xc0, 0x9d, 0xbd, 0x34, 0x8b, 0xa3, 0x11, 0x23, 0xe8, 0x2a, 0x94,
+	0x14, 0x8b, 0xb9, 0xce, 0x4e, 0xbe, 0x59, 0xdd, 0xab, 0xee, 0xc6, 0xdd, 0x5d, 0x4d, 0xf4, 0x8c,
+	0x0c, 0x3f, 0x31, 0x76, 0x7b, 0x84, 0x8d, 0x07, 0xfc, 0x60, 0xcc, 0x7a, 0xc6, 0xee, 0x9b, 0xb0,
+	0xa2, 0xaf, 0x4b, 0xa5, 0xcc, 0x28, 0x3a, 0x2b, 0x14, 0xd9, 0x9b, 0x1e, 0x8d, 0x88, 0x57, 0x8b,
+	0x2d, 0x80, 0xe1, 0xd3, 0x1c, 0xac, 0x4e, 0x51, 0x84, 0xf9, 0x2f, 0x23, 0xfa, 0x39, 0xa1, 0x9d,
+	0x91, 0x3f, 0x4c, 0xcc, 0x57, 0xd0, 0x43, 0x7f, 0x28, 0x09, 0x43, 0xc2, 0x69, 0x3f, 0x50, 0x04,
+	0xe5, 0x03, 0x50, 0x90, 0x21, 0xa8, 0x7b, 0x77, 0xfc, 0x30, 0xa4, 0x6e, 0x5e, 0x11, 0x14, 0xd4,
+	0x0a, 0x43, 0x8a, 0xae, 0x40, 0x8d, 0x45, 0x63, 0x1a, 0x10, 0x13, 0xab, 0x82, 0xa4, 0x2c, 0x2b,
+	0x50, 0x07, 0xeb, 0x0a, 0xd4, 0xb4, 0x16, 0x4d, 0x5a, 0x52, 0x24, 0x05, 0x6a, 0xd2, 0x16, 0xa8,
+	0xc0, 0xa8, 0x50, 0x15, 0x25, 0xa3, 0x22, 0x11, 0x19, 0xa9, 0x2d, 0x00, 0xde, 0x1f, 0x92, 0x0e,
+	0xe3, 0xfe, 0x30, 0x76, 0x4b, 0x3b, 0x4e, 0x33, 0xef, 0x55, 0x04, 0xd2, 0x16, 0x00, 0x3a, 0x07,
+	0x4b, 0xc7, 0xfe, 0x60, 0x4c, 0xdc, 0xf2, 0x8e, 0xd3, 0xcc, 0x79, 0x6a, 0x81, 0xea, 0x90, 0x67,
+	0xe4, 0xc8, 0xad, 0xec, 0x38, 0xcd, 0x82, 0x27, 0x7e, 0xa2, 0x8b, 0x50, 0x61, 0xe4, 0xa8, 0x43,
+	0xe2, 0x28, 0xe8, 0xb9, 0x20, 0xb5, 0x94, 0x19, 0x39, 0x7a, 0x5f, 0xac, 0x93, 0x74, 0x11, 0x87,
+	0x50, 0xee, 0x56, 0xa5, 0x58, 0xdd, 0xaa, 0x2d, 0x10, 0xfc, 0xb5, 0x03, 0xae, 0xed, 0x64, 0x15,
+	0x3c, 0x1d, 0xff, 0x4b, 0x50, 0x65, 0xe3, 0x20, 0x20, 0x8c, 0x75, 0x46, 0xe3, 0xa1, 0xf4, 0xf6,
+	0x92, 0x07, 0x1a, 0x7a, 0x38, 0x1e, 0x8a, 0xb4, 0xa6, 0xe4, 0x05, 0x09, 0x38, 0x09, 0x25, 0x23,
+	0x27, 0x19, 0x55, 0x83, 0x09, 0xca, 0x2e, 0x94, 0xcd, 0x52, 0x66, 0x5e, 0x75, 0x0f, 0x89, 0xd8,
+	0x7b, 0x1a, 0x53, 0xa7, 0x7a, 0x09, 0x07, 0x7f, 0x01, 0x2b, 0x93, 0x32, 0x63, 0xb2, 0x93, 0x9a,
+	0xfc, 0xe6, 0x41, 0x96, 0xaf, 0xc4, 0x67, 0x49, 0x74, 0xf5, 0x0a, 0xff, 0x9e, 0x83, 0x86, 0x72,
+	0x47, 0xeb, 0x90, 0x8c, 0xf8, 0x7e, 0xec, 0x91, 0x38, 0xa2, 0xc9, 0x33, 0x5e, 0x81, 0x5c, 0x3f,
+	0xd6, 0x59, 0x97, 0xeb, 0xc7, 0x73, 0x1f, 0x9b, 0x0b, 0xa5, 0x63, 0x42, 0x99, 0x10, 0xa8, 0xb3,
+	0xcd, 0x12, 0x35, 0xa0, 0xdc, 0x8b, 0x18, 0x97, 0xf7, 0x56, 0x47, 0x27, 0x6b, 0x71, 0xeb, 0xfe,
+	0x88, 0x71, 0x7f, 0x14, 0x90, 0x4e, 0x3f, 0xd4, 0x29, 0x05, 0x06, 0xda, 0x0f, 0xd3, 0x68, 0x8a,
+	0x84, 0x62, 0x6e, 0x51, 0x3e, 0x64, 0x48, 0x32, 0x8a, 0xc9, 0x94, 0x52, 0x76, 0x8b, 0x68, 0x94,
+	0x64, 0x34, 0x2a, 0x0a, 0x11, 0xb1, 0xb8, 0x01, 0xee, 0xc0, 0x67, 0xbc, 0xa3, 0x94, 0x04, 0x27,
+	0xc1, 0x80, 0x74, 0x18, 0x09, 0xa2, 0x51, 0xc8, 0x64, 0x96, 0x39, 0xde, 0xba, 0x90, 0x4b, 0x07,
+	0xdc, 0x16, 0xd2, 0xb6, 0x12, 0x8a, 0x1c, 0x53, 0x1b, 0xc7, 0xac, 0x27, 0x73, 0x2f, 0xef, 0x95,
+	0x25, 0x73, 0xcc, 0x7a, 0xe8, 0x3f, 0x50, 0x0f, 0xa2, 0xd1, 0x48, 0x65, 0x01, 0x23, 0xf4, 0x98,
+	0x50, 0x99, 0x87, 0x15, 0x6f, 0x35, 0xc1, 0xdb, 0x12, 0xc6, 0xef, 0xc2, 0xc5, 0x4c, 0xef, 0xea,
+	0x7c, 0xdb, 0x02, 0xe8, 0xb3, 0x8e, 0xce, 0x2f, 0xe9, 0xe6, 0xb2, 0x57, 0xe9, 0xb3, 0xb6, 0x02,
+	0xf0, 0x5d, 0xd8, 0xb4, 0x76, 0xdf, 0x21, 0xc2, 0xd9, 0x8c, 0x13, 0xfa, 0x9a, 0xd1, 0xc1, 0xdf,
+	0x3b, 0x50, 0x55, 0x45, 0xec, 0x1e, 0x8d, 0xc6, 0x31, 0x42, 0x50, 0xb0, 0xaa, 0x49, 0xc1, 0xc4,
+	0xc2, 0xae, 0xb3, 0xb9, 0x57, 0xd4, 0xd9, 0xfc, 0x9c, 0x3a, 0x5b, 0xb0, 0xeb, 0xac, 0xc0, 0x55,
+	0x05, 0xd1, 0x71, 0xd5, 0x2b, 0x61, 0xf3, 0x38, 0x0e, 0x7d, 0xe1, 0x3b, 0x9f, 0xcb, 0x22, 0x91,
+	0xf7, 0x2a, 0x1a, 0x69, 0x71, 0xbc, 0x0d, 0x9b, 0xad, 0x70, 0xd8, 0x1f, 0x7d, 0xd4, 0x67, 0xdc,
+	0xba, 0x33, 0xd3, 0x36, 0xe3, 0xfb, 0xb0, 0x35, 0x47, 0xae, 0x7d, 0x7a, 0x0d, 0x8a, 0x87, 0x12,
+	0xd1, 0x95, 0x77, 0x35, 0x2d, 0xe1, 0x92, 0xe9, 0x69, 0x31, 0xbe, 0xab, 0x35, 0x3d, 0x89, 0x19,
+	0xa1, 0xb6, 0x2e, 0xe3, 0xde, 0xab, 0xb0, 0x24, 0xa9, 0xd2, 0x4f, 0x19, 0x8a, 0x94, 0x14, 0x5f,
+	0xd7, 0x7a, 0xee, 0x90, 0x01, 0xe1, 0x24, 0x43, 0x4f, 0x86, 0xbb, 0x71, 0x0b, 0xce, 0xca, 0x4d,
+	0xa6, 0xb7, 0xcc, 0xa7, 0x5a, 0x0e, 0xce, 0x4d, 0x34, 0xb2, 0xfb, 0x50, 0x93, 0x2a, 0x16, 0xcc,
+	0x26, 0xf1, 0x46, 0x87, 0x84, 0x31, 0xff, 0xd0, 0x44, 0xd7, 0x2c, 0xf1, 0xb7, 0x0e, 0xac, 0xb5,
+	0xc2, 0x5e, 0x14, 0xc8, 0x3c, 0x7b, 0xa0, 0xd0, 0x85, 0xdf, 0xfe, 0x3a, 0x14, 0x5f, 0x44, 0x5d,
+	0xf1, 0x80, 0x55, 0x62, 0x2c, 0xbd, 0x88, 0xba, 0xfb, 0x21, 0xfa, 0x1f, 0x94, 0x4c, 0x0b, 0x2c,
+	0xcc, 0x6f, 0x81, 0x86, 0x23, 0xaa, 0x3f, 0xa1, 0x34, 0xa2, 0x3a, 0x5b, 0xd4, 0x02, 0x7f, 0xe5,
+	0x08, 0x23, 0x7b, 0x51, 0x20, 0xf7, 0x7d, 0x10, 0x75, 0xad, 0xd3, 0x1c, 0xfb, 0xb4, 0x45, 0xd2,
+	0x37, 0x19, 0x07, 0x1c, 0x2b, 0x4d, 0x67, 0x1a, 0x5b, 0x61, 0xb6, 0xb1, 0xe1, 0xdf, 0x8c, 0x83,
+	0xe4, 0x35, 0x4c, 0xb0, 0x16, 0x19, 0x4d, 0x92, 0xc8, 0x4d, 0x9d, 0x39, 0xd9, 0x71, 0xf3, 0x19,
+	0x1d, 0xf7, 0x02, 0x94, 0x7d, 0x11, 0x0e, 0x31, 0x1e, 0xa9, 0x3b, 0x95, 0x7c, 0x55, 0x44, 0x16,
+	0x6b, 0xc6, 0xd7, 0x60, 0x55, 0xf4, 0xd6, 0x68, 0xcc, 0x93, 0x92, 0x57, 0x94, 0xf5, 0x71, 0x45,
+	0xc3, 0xba, 0xd6, 0xe1, 0x2f, 0xa1, 0x9e, 0x06, 0x5f, 0xb7, 0xa0, 0x45, 0x63, 0x6f, 0x05, 0x39,
+	0xff, 0x3a, 0x41, 0x2e, 0xd8, 0x41, 0xfe, 0x14, 0x90, 0xed, 0x5c, 0x9d, 0xcd, 0x73, 0x02, 0xbd,
+	0x9b, 0x9e, 0x98, 0x93, 0x27, 0x9e, 0x13, 0x27, 0x4e, 0x1b, 0x90, 0x1c, 0x89, 0x6f, 0x40, 0xed,
+	0xf6, 0x60, 0xcc, 0x38, 0xa1, 0x0f, 0xc8, 0xb0, 0x4b, 0x68, 0xe6, 0x13, 0x43, 0x50, 0x90, 0x7d,
+	0x53, 0x19, 0x27, 0x7f, 0xe3, 0xbf, 0x1c, 0x58, 0xd6, 0x3b, 0xa5, 0x62, 0xf4, 0x96, 0xf0, 0x81,
+	0x28, 0xdf, 0xba, 0x1e, 0x6c, 0xa7, 0xa6, 0x66, 0xf5, 0x4e, 0x4f, 0xb3, 0x45, 0x9a, 0xc8, 0x5e,
+	0xa2, 0x37, 0xe7, 0xd4, 0x48, 0x22, 0x20, 0xc5, 0x47, 0x4d, 0xa8, 0x4b, 0x82, 0x1e, 0x2f, 0x3b,
+	0x26, 0x49, 0xf3, 0xde, 0x8a, 0xc0, 0xd3, 0x39, 0x75, 0xb2, 0x2d, 0x15, 0xa6, 0xda, 0xd2, 0x15,
+	0xa8, 0x05, 0x84, 0xf2, 0x4e, 0x3f, 0x24, 0x23, 0xde, 0xe7, 0x27, 0x26, 0x2b, 0x04, 0xb8, 0xaf,
+	0x31, 0x84, 0x61, 0x39, 0x4c, 0xfa, 0x08, 0x09, 0x75, 0xfd, 0x9d, 0xc0, 0xf0, 0x13, 0x40, 0xda,
+	0x70, 0xbb, 0x69, 0x2c, 0x56, 0x0d, 0x45, 0x95, 0x09, 0x65, 0x21, 0x0c, 0xa5, 0xa5, 0x65, 0xcf,
+	0x2c, 0xf1, 0xcf, 0xa9, 0x43, 0xdb, 0xdc, 0xe7, 0x62, 0xda, 0x2e, 0x30, 0x32, 0x78, 0xae, 0x15,
+	0xae, 0x09, 0x85, 0x13, 0xa1, 0xf2, 0xa4, 0x18, 0xfd, 0x57, 0xd4, 0x2d, 0xb1, 0x36, 0x11, 0xcf,
+	0x60, 0x1a, 0x06, 0x6a, 0x42, 0x51, 0xbe, 0x12, 0x93, 0x8f, 0x75, 0x8b, 0xab, 0xf2, 0x43, 0xcb,
+	0xd1, 0x3b, 0xc9, 0x23, 0xd2, 0xed, 0x42, 0x55, 0xa9, 0x0d, 0x6b, 0x83, 0x6d, 0xde, 0x32, 0x4f,
+	0x17, 0x0c, 0x1f, 0xc2, 0xba, 0xe6, 0xdc, 0x8d, 0xe8, 0x4b, 0x9f, 0x86, 0x56, 0x01, 0x7f, 0x4e,
+	0xa3, 0xa1, 0xc9, 0x2e, 0xf1, 0x3b, 0xe3, 0x9b, 0x20, 0xb7, 0xf0, 0x37, 0xc1, 0xdb, 0xb0, 0x31,
+	0x7d, 0xd0, 0x82, 0xb3, 0xea, 0x5e, 0x17, 0x56, 0xef, 0x11, 0x6e, 0x7f, 0xeb, 0xa0, 0x47, 0x50,
+	0x9f, 0x82, 0x18, 0xba, 0x98, 0xde, 0x62, 0xe6, 0x33, 0xae, 0xb1, 0x99, 0x2d, 0x54, 0x57, 0xc0,
+	0x67, 0xf6, 0x7e, 0x74, 0xa0, 0x2e, 0x92, 0xcf, 0xb6, 0x02, 0x1d, 0xc0, 0xda, 0x34, 0x36, 0x71,
+	0xcc, 0xcc, 0x57, 0x53, 0x63, 0x33, 0x5b, 0x68, 0x8e, 0x41, 0x4f, 0x61, 0x63, 0x5a, 0x63, 0x9b,
+	0x53, 0xe2, 0x0f, 0xdf, 0x48, 0x6d, 0xd3, 0xd9, 0xfb, 0xc5, 0x81, 0xb3, 0x19, 0x4f, 0x18, 0x3d,
+	0x83, 0x73, 0x19, 0x30, 0x43, 0xff, 0xf0, 0xe6, 0x1b, 0x97, 0xe6, 0xca, 0x13, 0x5b, 0x3e, 0x83,
+	0xf5, 0xcc, 0xa1, 0x0e, 0xed, 0x4c, 0xed, 0x9d, 0x99, 0xf7, 0x16, 0xd0, 0xbe, 0xf7, 0x43, 0x1e,
+	0xaa, 0x9a, 0x21, 0x66, 0x03, 0xf4, 0x0c, 0xea, 0xd3, 0x93, 0x92, 0x3a, 0xe8, 0x55, 0x43, 0x56,
+	0xe3, 0xf2, 0x2b, 0x18, 0x89, 0x21, 0x1f, 0xc2, 0xda, 0xcc, 0xe8, 0x84, 0xd2, 0x9d, 0xf3, 0xc6,
+	0xaa, 0xc6, 0x5a, 0x42, 0x99, 0x54, 0x36, 0x33, 0x3f, 0x59, 0xca, 0xe6, 0xcd, 0x56, 0xd9, 0xca,
+	0x6e, 0x02, 0xb4, 0xc2, 0xd0, 0x24, 0xf8, 0xf9, 0x84, 0x32, 0x39, 0x6c, 0x65, 0xef, 0x7d, 0x0f,
+	0x6a, 0x1e, 0x19, 0x46, 0xc7, 0xe4, 0xdf, 0x6e, 0x87, 0xb4, 0x97, 0xa1, 0xf5, 0xa4, 0x37, 0xd9,
+	0x83, 0x43, 0x63, 0x63, 0x1a, 0x4e, 0xc2, 0xf7, 0x71, 0x1a, 0xbd, 0x5e, 0x14, 0xa0, 0x5b, 0xba,
+	0x35, 0x1f, 0xa8, 0xef, 0x57, 0x99, 0xf1, 0xeb, 0x93, 0xfd, 0x4e, 0x4f, 0x6b, 0xe6, 0x36, 0xd6,
+	0xa8, 0x24, 0x12, 0xfc, 0xff, 0xce, 0xde, 0x37, 0x0e, 0xd4, 0x94, 0x4e, 0x5d, 0x48, 0xd0, 0x75,
+	0xa8, 0x9a, 0x3a, 0x7c, 0x32, 0x0a, 0x90, 0x5d, 0x22, 0x65, 0x61, 0x6e, 0xcc, 0x20, 0xf8, 0x0c,
+	0xda, 0x87, 0x95, 0xb4, 0x02, 0xc9, 0x17, 0x7d, 0xc1, 0x62, 0x4d, 0x56, 0xc1, 0x46, 0x23, 0x4b,
+	0x64, 0x8c, 0xbc, 0x55, 0xff, 0xe9, 0x74, 0xdb, 0xf9, 0xf5, 0x74, 0xdb, 0xf9, 0xe3, 0x74, 0xdb,
+	0xf9, 0xee, 0xcf, 0xed, 0x33, 0xdd, 0xa2, 0xfc, 0x4f, 0xe9, 0xfa, 0xdf, 0x03, 0x00, 0x18, 0x4b,
+	0x16, 0x7d, 0x63, 0x12, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// GetProberTargetClient is the client API for GetProberTarget service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type GetProberTargetClient interface {
+	// Sends Get ProberTargets request
+	GetProberTargets(ctx context.Context, in *ProberTargetsGetRequest, opts ...grpc.CallOption) (*ProberTargetsGetResponse, error)
+}
+
+type getProberTargetClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGetProberTargetClient(cc *grpc.ClientConn) GetProberTargetClient {
+	return &getProberTargetClient{cc}
+}
+
+func (c *getProberTargetClient) GetProberTargets(ctx context.Context, in *ProberTargetsGetRequest, opts ...grpc.CallOption) (*ProberTargetsGetResponse, error) {
+	out := new(ProberTargetsGetResponse)
+	err := c.cc.Invoke(ctx, "/pb.GetProberTarget/GetProberTargets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetProberTargetServer is the server API for GetProberTarget service.
+type GetProberTargetServer interface {
+	// Sends Get ProberTargets request
+	GetProberTargets(context.Context, *ProberTargetsGetRequest) (*ProberTargetsGetResponse, error)
+}
+
+// UnimplementedGetProberTargetServer can be embedded to have forward compatible implementations.
+type UnimplementedGetProberTargetServer struct {
+}
+
+func (*UnimplementedGetProberTargetServer) GetProberTargets(ctx context.Context, req *ProberTargetsGetRequest) (*ProberTargetsGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProberTargets not implemented")
+}
+
+func RegisterGetProberTargetServer(s *grpc.Server, srv GetProberTargetServer) {
+	s.RegisterService(&_GetProberTarget_serviceDesc, srv)
+}
+
+func _GetProberTarget_GetProberTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProberTargetsGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetProberTargetServer).GetProberTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GetProberTarget/GetProberTargets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetProberTargetServer).GetProberTargets(ctx, req.(*ProberTargetsGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _GetProberTarget_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.GetProberTarget",
+	HandlerType: (*GetProberTargetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProberTargets",
+			Handler:    _GetProberTarget_GetProberTargets_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "prober.proto",
+}
+
+// PushProberResultClient is the client API for PushProberResult service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PushProberResultClient interface {
+	// Sends Get ProberTargets request
+	PushProberResults(ctx context.Context, in *ProberResultPushRequest, opts ...grpc.CallOption) (*ProberResultPushResponse, error)
+	// Streams new results in batches, the response acknowledges all of them
+	PushProberResultStream(ctx context.Context, opts ...grpc.CallOption) (PushProberResult_PushProberResultStreamClient, error)
+}
+
+type pushProberResultClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewPushProberResultClient(cc *grpc.ClientConn) PushProberResultClient {
+	return &pushProberResultClient{cc}
+}
+
+func (c *pushProberResultClient) PushProberResults(ctx context.Context, in *ProberResultPushRequest, opts ...grpc.CallOption) (*ProberResultPushResponse, error) {
+	out := new(ProberResultPushResponse)
+	err := c.cc.Invoke(ctx, "/pb.PushProberResult/PushProberResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushProberResultClient) PushProberResultStream(ctx context.Context, opts ...grpc.CallOption) (PushProberResult_PushProberResultStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PushProberResult_serviceDesc.Streams[0], "/pb.PushProberResult/PushProberResultStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pushProberResultPushProberResultStreamClient{stream}
+	return x, nil
+}
+
+type PushProberResult_PushProberResultStreamClient interface {
+	Send(*ProberResultPushRequest) error
+	CloseAndRecv() (*ProberResultPushResponse, error)
+	grpc.ClientStream
+}
+
+type pushProberResultPushProberResultStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *pushProberResultPushProberResultStreamClient) Send(m *ProberResultPushRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pushProberResultPushProberResultStreamClient) CloseAndRecv() (*ProberResultPushResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ProberResultPushResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PushProberResultServer is the server API for PushProberResult service.
+type PushProberResultServer interface {
+	// Sends Get ProberTargets request
+	PushProberResults(context.Context, *ProberResultPushRequest) (*ProberResultPushResponse, error)
+	// Streams new results in batches, the response acknowledges all of them
+	PushProberResultStream(PushProberResult_PushProberResultStreamServer) error
+}
+
+// UnimplementedPushProberResultServer can be embedded to have forward compatible implementations.
+type UnimplementedPushProberResultServer struct {
+}
+
+func (*UnimplementedPushProberResultServer) PushProberResults(ctx context.Context, req *ProberResultPushRequest) (*ProberResultPushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushProberResults not implemented")
+}
+func (*UnimplementedPushProberResultServer) PushProberResultStream(srv PushProberResult_PushProberResultStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PushProberResultStream not implemented")
+}
+
+func RegisterPushProberResultServer(s *grpc.Server, srv PushProberResultServer) {
+	s.RegisterService(&_PushProberResult_serviceDesc, srv)
+}
+
+func _PushProberResult_PushProberResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProberResultPushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushProberResultServer).PushProberResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PushProberResult/PushProberResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushProberResultServer).PushProberResults(ctx, req.(*ProberResultPushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushProberResult_PushProberResultStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PushProberResultServer).PushProberResultStream(&pushProberResultPushProberResultStreamServer{stream})
+}
+
+type PushProberResult_PushProberResultStreamServer interface {
+	SendAndClose(*ProberResultPushResponse) error
+	Recv() (*ProberResultPushRequest, error)
+	grpc.ServerStream
+}
+
+type pushProberResultPushProberResultStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *pushProberResultPushProberResultStreamServer) SendAndClose(m *ProberResultPushResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pushProberResultPushProberResultStreamServer) Recv() (*ProberResultPushRequest, error) {
+	m := new(ProberResultPushRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _PushProberResult_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.PushProberResult",
+	HandlerType: (*PushProberResultServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PushProberResults",
+			Handler:    _PushProberResult_PushProberResults_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushProberResultStream",
+			Handler:       _PushProberResult_PushProberResultStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "prober.proto",
+}
+
+// ProberAgentIpReportClient is the client API for ProberAgentIpReport service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProberAgentIpReportClient interface {
+	// Sends Get ProberTargets request
+	ProberAgentIpReports(ctx context.Context, in *ProberAgentIpReportRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error)
+	// Removes the agent from the target pool until it reports again
+	ProberAgentDeregister(ctx context.Context, in *ProberAgentDeregisterRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error)
+}
+
+type proberAgentIpReportClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProberAgentIpReportClient(cc *grpc.ClientConn) ProberAgentIpReportClient {
+	return &proberAgentIpReportClient{cc}
+}
+
+func (c *proberAgentIpReportClient) ProberAgentIpReports(ctx context.Context, in *ProberAgentIpReportRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error) {
+	out := new(ProberAgentIpReportResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAgentIpReport/ProberAgentIpReports", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAgentIpReportClient) ProberAgentDeregister(ctx context.Context, in *ProberAgentDeregisterRequest, opts ...grpc.CallOption) (*ProberAgentIpReportResponse, error) {
+	out := new(ProberAgentIpReportResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAgentIpReport/ProberAgentDeregister", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProberAgentIpReportServer is the server API for ProberAgentIpReport service.
+type ProberAgentIpReportServer interface {
+	// Sends Get ProberTargets request
+	ProberAgentIpReports(context.Context, *ProberAgentIpReportRequest) (*ProberAgentIpReportResponse, error)
+	// Removes the agent from the target pool until it reports again
+	ProberAgentDeregister(context.Context, *ProberAgentDeregisterRequest) (*ProberAgentIpReportResponse, error)
+}
+
+// UnimplementedProberAgentIpReportServer can be embedded to have forward compatible implementations.
+type UnimplementedProberAgentIpReportServer struct {
+}
+
+func (*UnimplementedProberAgentIpReportServer) ProberAgentIpReports(ctx context.Context, req *ProberAgentIpReportRequest) (*ProberAgentIpReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProberAgentIpReports not implemented")
+}
+func (*UnimplementedProberAgentIpReportServer) ProberAgentDeregister(ctx context.Context, req *ProberAgentDeregisterRequest) (*ProberAgentIpReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProberAgentDeregister not implemented")
+}
+
+func RegisterProberAgentIpReportServer(s *grpc.Server, srv ProberAgentIpReportServer) {
+	s.RegisterService(&_ProberAgentIpReport_serviceDesc, srv)
+}
+
+func _ProberAgentIpReport_ProberAgentIpReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProberAgentIpReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAgentIpReportServer).ProberAgentIpReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAgentIpReport/ProberAgentIpReports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAgentIpReportServer).ProberAgentIpReports(ctx, req.(*ProberAgentIpReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAgentIpReport_ProberAgentDeregister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProberAgentDeregisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAgentIpReportServer).ProberAgentDeregister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAgentIpReport/ProberAgentDeregister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAgentIpReportServer).ProberAgentDeregister(ctx, req.(*ProberAgentDeregisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ProberAgentIpReport_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ProberAgentIpReport",
+	HandlerType: (*ProberAgentIpReportServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProberAgentIpReports",
+			Handler:    _ProberAgentIpReport_ProberAgentIpReports_Handler,
+		},
+		{
+			MethodName: "ProberAgentDeregister",
+			Handler:    _ProberAgentIpReport_ProberAgentDeregister_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "prober.proto",
+}
+
+// ProberAdminClient is the client API for ProberAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProberAdminClient interface {
+	ListTargetGroups(ctx context.Context, in *AdminListTargetGroupsRequest, opts ...grpc.CallOption) (*AdminListTargetGroupsResponse, error)
+	UpsertTargetGroup(ctx context.Context, in *AdminUpsertTargetGroupRequest, opts ...grpc.CallOption) (*AdminResponse, error)
+	DeleteTargetGroup(ctx context.Context, in *AdminDeleteTargetGroupRequest, opts ...grpc.CallOption) (*AdminResponse, error)
+	AddTargets(ctx context.Context, in *AdminTargetsRequest, opts ...grpc.CallOption) (*AdminResponse, error)
+	RemoveTargets(ctx context.Context, in *AdminTargetsRequest, opts ...grpc.CallOption) (*AdminResponse, error)
+	// run a one-shot probe on the connected agents and wait for the results
+	AdhocProbe(ctx context.Context, in *AdhocProbeRequest, opts ...grpc.CallOption) (*AdhocProbeResponse, error)
+}
+
+type proberAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProberAdminClient(cc *grpc.ClientConn) ProberAdminClient {
+	return &proberAdminClient{cc}
+}
+
+func (c *proberAdminClient) ListTargetGroups(ctx context.Context, in *AdminListTargetGroupsRequest, opts ...grpc.CallOption) (*AdminListTargetGroupsResponse, error) {
+	out := new(AdminListTargetGroupsResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/ListTargetGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAdminClient) UpsertTargetGroup(ctx context.Context, in *AdminUpsertTargetGroupRequest, opts ...grpc.CallOption) (*AdminResponse, error) {
+	out := new(AdminResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/UpsertTargetGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAdminClient) DeleteTargetGroup(ctx context.Context, in *AdminDeleteTargetGroupRequest, opts ...grpc.CallOption) (*AdminResponse, error) {
+	out := new(AdminResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/DeleteTargetGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAdminClient) AddTargets(ctx context.Context, in *AdminTargetsRequest, opts ...grpc.CallOption) (*AdminResponse, error) {
+	out := new(AdminResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/AddTargets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAdminClient) RemoveTargets(ctx context.Context, in *AdminTargetsRequest, opts ...grpc.CallOption) (*AdminResponse, error) {
+	out := new(AdminResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/RemoveTargets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberAdminClient) AdhocProbe(ctx context.Context, in *AdhocProbeRequest, opts ...grpc.CallOption) (*AdhocProbeResponse, error) {
+	out := new(AdhocProbeResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberAdmin/AdhocProbe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProberAdminServer is the server API for ProberAdmin service.
+type ProberAdminServer interface {
+	ListTargetGroups(context.Context, *AdminListTargetGroupsRequest) (*AdminListTargetGroupsResponse, error)
+	UpsertTargetGroup(context.Context, *AdminUpsertTargetGroupRequest) (*AdminResponse, error)
+	DeleteTargetGroup(context.Context, *AdminDeleteTargetGroupRequest) (*AdminResponse, error)
+	AddTargets(context.Context, *AdminTargetsRequest) (*AdminResponse, error)
+	RemoveTargets(context.Context, *AdminTargetsRequest) (*AdminResponse, error)
+	// run a one-shot probe on the connected agents and wait for the results
+	AdhocProbe(context.Context, *AdhocProbeRequest) (*AdhocProbeResponse, error)
+}
+
+// UnimplementedProberAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedProberAdminServer struct {
+}
+
+func (*UnimplementedProberAdminServer) ListTargetGroups(ctx context.Context, req *AdminListTargetGroupsRequest) (*AdminListTargetGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTargetGroups not implemented")
+}
+func (*UnimplementedProberAdminServer) UpsertTargetGroup(ctx context.Context, req *AdminUpsertTargetGroupRequest) (*AdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertTargetGroup not implemented")
+}
+func (*UnimplementedProberAdminServer) DeleteTargetGroup(ctx context.Context, req *AdminDeleteTargetGroupRequest) (*AdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTargetGroup not implemented")
+}
+func (*UnimplementedProberAdminServer) AddTargets(ctx context.Context, req *AdminTargetsRequest) (*AdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTargets not implemented")
+}
+func (*UnimplementedProberAdminServer) RemoveTargets(ctx context.Context, req *AdminTargetsRequest) (*AdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTargets not implemented")
+}
+func (*UnimplementedProberAdminServer) AdhocProbe(ctx context.Context, req *AdhocProbeRequest) (*AdhocProbeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdhocProbe not implemented")
+}
+
+func RegisterProberAdminServer(s *grpc.Server, srv ProberAdminServer) {
+	s.RegisterService(&_ProberAdmin_serviceDesc, srv)
+}
+
+func _ProberAdmin_ListTargetGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListTargetGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).ListTargetGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/ListTargetGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).ListTargetGroups(ctx, req.(*AdminListTargetGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAdmin_UpsertTargetGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUpsertTargetGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).UpsertTargetGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/UpsertTargetGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).UpsertTargetGroup(ctx, req.(*AdminUpsertTargetGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAdmin_DeleteTargetGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminDeleteTargetGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).DeleteTargetGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/DeleteTargetGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).DeleteTargetGroup(ctx, req.(*AdminDeleteTargetGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAdmin_AddTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminTargetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).AddTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/AddTargets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).AddTargets(ctx, req.(*AdminTargetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAdmin_RemoveTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminTargetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).RemoveTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/RemoveTargets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).RemoveTargets(ctx, req.(*AdminTargetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberAdmin_AdhocProbe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdhocProbeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberAdminServer).AdhocProbe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberAdmin/AdhocProbe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberAdminServer).AdhocProbe(ctx, req.(*AdhocProbeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ProberAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ProberAdmin",
+	HandlerType: (*ProberAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTargetGroups",
+			Handler:    _ProberAdmin_ListTargetGroups_Handler,
+		},
+		{
+			MethodName: "UpsertTargetGroup",
+			Handler:    _ProberAdmin_UpsertTargetGroup_Handler,
+		},
+		{
+			MethodName: "DeleteTargetGroup",
+			Handler:    _ProberAdmin_DeleteTargetGroup_Handler,
+		},
+		{
+			MethodName: "AddTargets",
+			Handler:    _ProberAdmin_AddTargets_Handler,
+		},
+		{
+			MethodName: "RemoveTargets",
+			Handler:    _ProberAdmin_RemoveTargets_Handler,
+		},
+		{
+			MethodName: "AdhocProbe",
+			Handler:    _ProberAdmin_AdhocProbe_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "prober.proto",
+}
+
+// ProberAdhocClient is the client API for ProberAdhoc service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProberAdhocClient interface {
+	AdhocProbeStream(ctx context.Context, opts ...grpc.CallOption) (ProberAdhoc_AdhocProbeStreamClient, error)
+}
+
+type proberAdhocClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProberAdhocClient(cc *grpc.ClientConn) ProberAdhocClient {
+	return &proberAdhocClient{cc}
+}
+
+func (c *proberAdhocClient) AdhocProbeStream(ctx context.Context, opts ...grpc.CallOption) (ProberAdhoc_AdhocProbeStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ProberAdhoc_serviceDesc.Streams[0], "/pb.ProberAdhoc/AdhocProbeStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &proberAdhocAdhocProbeStreamClient{stream}
+	return x, nil
+}
+
+type ProberAdhoc_AdhocProbeStreamClient interface {
+	Send(*AdhocAgentMessage) error
+	Recv() (*AdhocProbeJob, error)
+	grpc.ClientStream
+}
+
+type proberAdhocAdhocProbeStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *proberAdhocAdhocProbeStreamClient) Send(m *AdhocAgentMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *proberAdhocAdhocProbeStreamClient) Recv() (*AdhocProbeJob, error) {
+	m := new(AdhocProbeJob)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ProberAdhocServer is the server API for ProberAdhoc service.
+type ProberAdhocServer interface {
+	AdhocProbeStream(ProberAdhoc_AdhocProbeStreamServer) error
+}
+
+// UnimplementedProberAdhocServer can be embedded to have forward compatible implementations.
+type UnimplementedProberAdhocServer struct {
+}
+
+func (*UnimplementedProberAdhocServer) AdhocProbeStream(srv ProberAdhoc_AdhocProbeStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method AdhocProbeStream not implemented")
+}
+
+func RegisterProberAdhocServer(s *grpc.Server, srv ProberAdhocServer) {
+	s.RegisterService(&_ProberAdhoc_serviceDesc, srv)
+}
+
+func _ProberAdhoc_AdhocProbeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ProberAdhocServer).AdhocProbeStream(&proberAdhocAdhocProbeStreamServer{stream})
+}
+
+type ProberAdhoc_AdhocProbeStreamServer interface {
+	Send(*AdhocProbeJob) error
+	Recv() (*AdhocAgentMessage, error)
+	grpc.ServerStream
+}
+
+type proberAdhocAdhocProbeStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *proberAdhocAdhocProbeStreamServer) Send(m *AdhocProbeJob) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *proberAdhocAdhocProbeStreamServer) Recv() (*AdhocAgentMessage, error) {
+	m := new(AdhocAgentMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _ProberAdhoc_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ProberAdhoc",
+	HandlerType: (*ProberAdhocServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AdhocProbeStream",
+			Handler:       _ProberAdhoc_AdhocProbeStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "prober.proto",
+}
+
+// ProberClusterClient is the client API for ProberCluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProberClusterClient interface {
+	ClusterSync(ctx context.Context, in *ClusterState, opts ...grpc.CallOption) (*ClusterState, error)
+	ForwardResults(ctx context.Context, in *ClusterForwardRequest, opts ...grpc.CallOption) (*ClusterForwardResponse, error)
+}
+
+type proberClusterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProberClusterClient(cc *grpc.ClientConn) ProberClusterClient {
+	return &proberClusterClient{cc}
+}
+
+func (c *proberClusterClient) ClusterSync(ctx context.Context, in *ClusterState, opts ...grpc.CallOption) (*ClusterState, error) {
+	out := new(ClusterState)
+	err := c.cc.Invoke(ctx, "/pb.ProberCluster/ClusterSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proberClusterClient) ForwardResults(ctx context.Context, in *ClusterForwardRequest, opts ...grpc.CallOption) (*ClusterForwardResponse, error) {
+	out := new(ClusterForwardResponse)
+	err := c.cc.Invoke(ctx, "/pb.ProberCluster/ForwardResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProberClusterServer is the server API for ProberCluster service.
+type ProberClusterServer interface {
+	ClusterSync(context.Context, *ClusterState) (*ClusterState, error)
+	ForwardResults(context.Context, *ClusterForwardRequest) (*ClusterForwardResponse, error)
+}
+
+// UnimplementedProberClusterServer can be embedded to have forward compatible implementations.
+type UnimplementedProberClusterServer struct {
+}
+
+func (*UnimplementedProberClusterServer) ClusterSync(ctx context.Context, req *ClusterState) (*ClusterState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClusterSync not implemented")
+}
+func (*UnimplementedProberClusterServer) ForwardResults(ctx context.Context, req *ClusterForwardRequest) (*ClusterForwardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForwardResults not implemented")
+}
+
+func RegisterProberClusterServer(s *grpc.Server, srv ProberClusterServer) {
+	s.RegisterService(&_ProberCluster_serviceDesc, srv)
+}
+
+func _ProberCluster_ClusterSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterState)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberClusterServer).ClusterSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberCluster/ClusterSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberClusterServer).ClusterSync(ctx, req.(*ClusterState))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProberCluster_ForwardResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProberClusterServer).ForwardResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ProberCluster/ForwardResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProberClusterServer).ForwardResults(ctx, req.(*ClusterForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ProberCluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ProberCluster",
+	HandlerType: (*ProberClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ClusterSync",
+			Handler:    _ProberCluster_ClusterSync_Handler,
+		},
+		{
+			MethodName: "ForwardResults",
+			Handler:    _ProberCluster_ForwardResults_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "prober.proto",
+}
+
+func (m *ProberTargetsGetRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberTargetsGetRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberTargetsGetRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.LocalIp) > 0 {
+		i -= len(m.LocalIp)
+		copy(dAtA[i:], m.LocalIp)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.LocalIp)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.LocalRegion) > 0 {
+		i -= len(m.LocalRegion)
+		copy(dAtA[i:], m.LocalRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.LocalRegion)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Targets) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Targets) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Targets) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Target) > 0 {
+		for iNdEx := len(m.Target) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Target[iNdEx])
+			copy(dAtA[i:], m.Target[iNdEx])
+			i = encodeVarintProber(dAtA, i, uint64(len(m.Target[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.ProberType) > 0 {
+		i -= len(m.ProberType)
+		copy(dAtA[i:], m.ProberType)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ProberType)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberTargetsGetResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberTargetsGetResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberTargetsGetResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Targets) > 0 {
+		for iNdEx := len(m.Targets) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Targets[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberResultPushRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberResultPushRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberResultPushRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ProberResults) > 0 {
+		for iNdEx := len(m.ProberResults) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ProberResults[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberResultOne) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberResultOne) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberResultOne) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.ProbeStart != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.ProbeStart))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.SeqEpoch != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.SeqEpoch))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.Seq != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.Value != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Value))))
+		i--
+		dAtA[i] = 0x45
+	}
+	if m.TimeStamp != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.TimeStamp))
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.ProbeType) > 0 {
+		i -= len(m.ProbeType)
+		copy(dAtA[i:], m.ProbeType)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ProbeType)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.TargetRegion) > 0 {
+		i -= len(m.TargetRegion)
+		copy(dAtA[i:], m.TargetRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetRegion)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.SourceRegion) > 0 {
+		i -= len(m.SourceRegion)
+		copy(dAtA[i:], m.SourceRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.SourceRegion)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.TargetAddr) > 0 {
+		i -= len(m.TargetAddr)
+		copy(dAtA[i:], m.TargetAddr)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetAddr)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.MetricName) > 0 {
+		i -= len(m.MetricName)
+		copy(dAtA[i:], m.MetricName)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.MetricName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.WorkerName) > 0 {
+		i -= len(m.WorkerName)
+		copy(dAtA[i:], m.WorkerName)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.WorkerName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberResultPushResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberResultPushResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberResultPushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Rejected) > 0 {
+		for iNdEx := len(m.Rejected) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Rejected[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.RejectedNum != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.RejectedNum))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.SuccessNum != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.SuccessNum))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RejectedResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RejectedResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RejectedResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Reason) > 0 {
+		i -= len(m.Reason)
+		copy(dAtA[i:], m.Reason)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Reason)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.TargetAddr) > 0 {
+		i -= len(m.TargetAddr)
+		copy(dAtA[i:], m.TargetAddr)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetAddr)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.MetricName) > 0 {
+		i -= len(m.MetricName)
+		copy(dAtA[i:], m.MetricName)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.MetricName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Seq != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberAgentIpReportRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberAgentIpReportRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberAgentIpReportRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ConnectedServer) > 0 {
+		i -= len(m.ConnectedServer)
+		copy(dAtA[i:], m.ConnectedServer)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ConnectedServer)))
+		i--
+		dAtA[i] = 0x52
+	}
+	if m.LastPush != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.LastPush))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.LastProbeCycleSeconds != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.LastProbeCycleSeconds))))
+		i--
+		dAtA[i] = 0x41
+	}
+	if m.TargetNum != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.TargetNum))
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.ProbeTypes) > 0 {
+		for iNdEx := len(m.ProbeTypes) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ProbeTypes[iNdEx])
+			copy(dAtA[i:], m.ProbeTypes[iNdEx])
+			i = encodeVarintProber(dAtA, i, uint64(len(m.ProbeTypes[iNdEx])))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.InstanceId) > 0 {
+		i -= len(m.InstanceId)
+		copy(dAtA[i:], m.InstanceId)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.InstanceId)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Hostname) > 0 {
+		i -= len(m.Hostname)
+		copy(dAtA[i:], m.Hostname)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Hostname)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Version) > 0 {
+		i -= len(m.Version)
+		copy(dAtA[i:], m.Version)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Version)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Ip) > 0 {
+		i -= len(m.Ip)
+		copy(dAtA[i:], m.Ip)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Ip)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberAgentIpReportResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberAgentIpReportResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberAgentIpReportResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.IsSuccess {
+		i--
+		if m.IsSuccess {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProberAgentDeregisterRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProberAgentDeregisterRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProberAgentDeregisterRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Ip) > 0 {
+		i -= len(m.Ip)
+		copy(dAtA[i:], m.Ip)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Ip)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TargetGroup) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TargetGroup) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TargetGroup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.UpdatedAt != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.UpdatedAt))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Source) > 0 {
+		i -= len(m.Source)
+		copy(dAtA[i:], m.Source)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Source)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Target) > 0 {
+		for iNdEx := len(m.Target) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Target[iNdEx])
+			copy(dAtA[i:], m.Target[iNdEx])
+			i = encodeVarintProber(dAtA, i, uint64(len(m.Target[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ProberType) > 0 {
+		i -= len(m.ProberType)
+		copy(dAtA[i:], m.ProberType)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ProberType)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminListTargetGroupsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminListTargetGroupsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminListTargetGroupsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminListTargetGroupsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminListTargetGroupsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminListTargetGroupsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Groups) > 0 {
+		for iNdEx := len(m.Groups) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Groups[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminUpsertTargetGroupRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminUpsertTargetGroupRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminUpsertTargetGroupRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Group != nil {
+		{
+			size, err := m.Group.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintProber(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminDeleteTargetGroupRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminDeleteTargetGroupRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminDeleteTargetGroupRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminTargetsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminTargetsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminTargetsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Target) > 0 {
+		for iNdEx := len(m.Target) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Target[iNdEx])
+			copy(dAtA[i:], m.Target[iNdEx])
+			i = encodeVarintProber(dAtA, i, uint64(len(m.Target[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdminResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdminResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdminResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.IsSuccess {
+		i--
+		if m.IsSuccess {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdhocAgentMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdhocAgentMessage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdhocAgentMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.JobId) > 0 {
+		i -= len(m.JobId)
+		copy(dAtA[i:], m.JobId)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.JobId)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Ip) > 0 {
+		i -= len(m.Ip)
+		copy(dAtA[i:], m.Ip)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Ip)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdhocProbeJob) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdhocProbeJob) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdhocProbeJob) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.TargetRegion) > 0 {
+		i -= len(m.TargetRegion)
+		copy(dAtA[i:], m.TargetRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetRegion)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Target) > 0 {
+		i -= len(m.Target)
+		copy(dAtA[i:], m.Target)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Target)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ProberType) > 0 {
+		i -= len(m.ProberType)
+		copy(dAtA[i:], m.ProberType)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ProberType)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.JobId) > 0 {
+		i -= len(m.JobId)
+		copy(dAtA[i:], m.JobId)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.JobId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdhocProbeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdhocProbeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdhocProbeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.TimeoutSeconds != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.TimeoutSeconds))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.TargetRegion) > 0 {
+		i -= len(m.TargetRegion)
+		copy(dAtA[i:], m.TargetRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.TargetRegion)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.AgentIp) > 0 {
+		i -= len(m.AgentIp)
+		copy(dAtA[i:], m.AgentIp)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.AgentIp)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.SourceRegion) > 0 {
+		i -= len(m.SourceRegion)
+		copy(dAtA[i:], m.SourceRegion)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.SourceRegion)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Target) > 0 {
+		i -= len(m.Target)
+		copy(dAtA[i:], m.Target)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Target)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.ProberType) > 0 {
+		i -= len(m.ProberType)
+		copy(dAtA[i:], m.ProberType)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.ProberType)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdhocAgentResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdhocAgentResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdhocAgentResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Region) > 0 {
+		i -= len(m.Region)
+		copy(dAtA[i:], m.Region)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Region)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Ip) > 0 {
+		i -= len(m.Ip)
+		copy(dAtA[i:], m.Ip)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Ip)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AdhocProbeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AdhocProbeResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AdhocProbeResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.JobId) > 0 {
+		i -= len(m.JobId)
+		copy(dAtA[i:], m.JobId)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.JobId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterMember) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterMember) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterMember) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Addr) > 0 {
+		i -= len(m.Addr)
+		copy(dAtA[i:], m.Addr)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Addr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterAgent) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterAgent) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterAgent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Deregistered != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.Deregistered))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.CertIdentity) > 0 {
+		i -= len(m.CertIdentity)
+		copy(dAtA[i:], m.CertIdentity)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.CertIdentity)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.LastPush != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.LastPush))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.LastTargetsGet != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.LastTargetsGet))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.LastReport != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.LastReport))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Report != nil {
+		{
+			size, err := m.Report.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintProber(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterTargetGroup) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterTargetGroup) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterTargetGroup) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Deleted {
+		i--
+		if m.Deleted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Group != nil {
+		{
+			size, err := m.Group.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintProber(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterState) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterState) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.TargetGroups) > 0 {
+		for iNdEx := len(m.TargetGroups) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.TargetGroups[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Agents) > 0 {
+		for iNdEx := len(m.Agents) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Agents[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Members) > 0 {
+		for iNdEx := len(m.Members) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Members[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Self != nil {
+		{
+			size, err := m.Self.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintProber(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterForwardRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterForwardRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterForwardRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.ProberResults) > 0 {
+		for iNdEx := len(m.ProberResults) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ProberResults[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintProber(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.From) > 0 {
+		i -= len(m.From)
+		copy(dAtA[i:], m.From)
+		i = encodeVarintProber(dAtA, i, uint64(len(m.From)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ClusterForwardResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ClusterForwardResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ClusterForwardResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.SuccessNum != 0 {
+		i = encodeVarintProber(dAtA, i, uint64(m.SuccessNum))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintProber(dAtA []byte, offset int, v uint64) int {
+	offset -= sovProber(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ProberTargetsGetRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.LocalRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.LocalIp)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Targets) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ProberType)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Target) > 0 {
+		for _, s := range m.Target {
+			l = len(s)
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberTargetsGetResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Targets) > 0 {
+		for _, e := range m.Targets {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberResultPushRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.ProberResults) > 0 {
+		for _, e := range m.ProberResults {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberResultOne) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.WorkerName)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.MetricName)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.TargetAddr)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.SourceRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.TargetRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.ProbeType)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.TimeStamp != 0 {
+		n += 1 + sovProber(uint64(m.TimeStamp))
+	}
+	if m.Value != 0 {
+		n += 5
+	}
+	if m.Seq != 0 {
+		n += 1 + sovProber(uint64(m.Seq))
+	}
+	if m.SeqEpoch != 0 {
+		n += 1 + sovProber(uint64(m.SeqEpoch))
+	}
+	if m.ProbeStart != 0 {
+		n += 1 + sovProber(uint64(m.ProbeStart))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberResultPushResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SuccessNum != 0 {
+		n += 1 + sovProber(uint64(m.SuccessNum))
+	}
+	if m.RejectedNum != 0 {
+		n += 1 + sovProber(uint64(m.RejectedNum))
+	}
+	if len(m.Rejected) > 0 {
+		for _, e := range m.Rejected {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RejectedResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Seq != 0 {
+		n += 1 + sovProber(uint64(m.Seq))
+	}
+	l = len(m.MetricName)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.TargetAddr)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberAgentIpReportRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Ip)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Hostname)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.InstanceId)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.ProbeTypes) > 0 {
+		for _, s := range m.ProbeTypes {
+			l = len(s)
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.TargetNum != 0 {
+		n += 1 + sovProber(uint64(m.TargetNum))
+	}
+	if m.LastProbeCycleSeconds != 0 {
+		n += 9
+	}
+	if m.LastPush != 0 {
+		n += 1 + sovProber(uint64(m.LastPush))
+	}
+	l = len(m.ConnectedServer)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberAgentIpReportResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.IsSuccess {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProberAgentDeregisterRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Ip)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TargetGroup) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.ProberType)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Target) > 0 {
+		for _, s := range m.Target {
+			l = len(s)
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	l = len(m.Source)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.UpdatedAt != 0 {
+		n += 1 + sovProber(uint64(m.UpdatedAt))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminListTargetGroupsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminListTargetGroupsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Groups) > 0 {
+		for _, e := range m.Groups {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminUpsertTargetGroupRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Group != nil {
+		l = m.Group.Size()
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminDeleteTargetGroupRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminTargetsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Target) > 0 {
+		for _, s := range m.Target {
+			l = len(s)
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdminResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.IsSuccess {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdhocAgentMessage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Ip)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.JobId)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdhocProbeJob) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.JobId)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.ProberType)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Target)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.TargetRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdhocProbeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ProberType)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Target)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.SourceRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.AgentIp)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.TargetRegion)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.TimeoutSeconds != 0 {
+		n += 1 + sovProber(uint64(m.TimeoutSeconds))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdhocAgentResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Ip)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Region)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AdhocProbeResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.JobId)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterMember) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	l = len(m.Addr)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterAgent) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Report != nil {
+		l = m.Report.Size()
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.LastReport != 0 {
+		n += 1 + sovProber(uint64(m.LastReport))
+	}
+	if m.LastTargetsGet != 0 {
+		n += 1 + sovProber(uint64(m.LastTargetsGet))
+	}
+	if m.LastPush != 0 {
+		n += 1 + sovProber(uint64(m.LastPush))
+	}
+	l = len(m.CertIdentity)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.Deregistered != 0 {
+		n += 1 + sovProber(uint64(m.Deregistered))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterTargetGroup) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Group != nil {
+		l = m.Group.Size()
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if m.Deleted {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterState) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Self != nil {
+		l = m.Self.Size()
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.Members) > 0 {
+		for _, e := range m.Members {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if len(m.Agents) > 0 {
+		for _, e := range m.Agents {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if len(m.TargetGroups) > 0 {
+		for _, e := range m.TargetGroups {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterForwardRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.From)
+	if l > 0 {
+		n += 1 + l + sovProber(uint64(l))
+	}
+	if len(m.ProberResults) > 0 {
+		for _, e := range m.ProberResults {
+			l = e.Size()
+			n += 1 + l + sovProber(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ClusterForwardResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SuccessNum != 0 {
+		n += 1 + sovProber(uint64(m.SuccessNum))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovProber(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozProber(x uint64) (n int) {
+	return sovProber(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *ProberTargetsGetRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberTargetsGetRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberTargetsGetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LocalRegion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LocalRegion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LocalIp", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LocalIp = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Targets) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Targets: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Targets: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProberType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Region = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Target = append(m.Target, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberTargetsGetResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberTargetsGetResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberTargetsGetResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Targets", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Targets = append(m.Targets, &Targets{})
+			if err := m.Targets[len(m.Targets)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberResultPushRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberResultPushRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberResultPushRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberResults", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProberResults = append(m.ProberResults, &ProberResultOne{})
+			if err := m.ProberResults[len(m.ProberResults)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberResultOne: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberResultOne: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WorkerName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.WorkerName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceRegion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceRegion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetRegion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetRegion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProbeType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProbeType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TimeStamp", wireType)
+			}
+			m.TimeStamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TimeStamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Value = float32(math.Float32frombits(v))
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeqEpoch", wireType)
+			}
+			m.SeqEpoch = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SeqEpoch |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProbeStart", wireType)
+			}
+			m.ProbeStart = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProbeStart |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberResultPushResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberResultPushResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberResultPushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SuccessNum", wireType)
+			}
+			m.SuccessNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SuccessNum |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedNum", wireType)
+			}
+			m.RejectedNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RejectedNum |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rejected", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rejected = append(m.Rejected, &RejectedResult{})
+			if err := m.Rejected[len(m.Rejected)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RejectedResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RejectedResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RejectedResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberAgentIpReportRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberAgentIpReportRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ip", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ip = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Region = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hostname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hostname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InstanceId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.InstanceId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProbeTypes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProbeTypes = append(m.ProbeTypes, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetNum", wireType)
+			}
+			m.TargetNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TargetNum |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastProbeCycleSeconds", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.LastProbeCycleSeconds = float64(math.Float64frombits(v))
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastPush", wireType)
+			}
+			m.LastPush = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastPush |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectedServer", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ConnectedServer = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberAgentIpReportResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberAgentIpReportResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberAgentIpReportResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsSuccess", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsSuccess = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProberAgentDeregisterRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProberAgentDeregisterRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProberAgentDeregisterRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ip", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ip = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Region = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TargetGroup) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TargetGroup: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TargetGroup: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProberType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Region = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Target = append(m.Target, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Source = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UpdatedAt", wireType)
+			}
+			m.UpdatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.UpdatedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminListTargetGroupsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminListTargetGroupsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminListTargetGroupsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminListTargetGroupsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminListTargetGroupsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminListTargetGroupsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Groups", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Groups = append(m.Groups, &TargetGroup{})
+			if err := m.Groups[len(m.Groups)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminUpsertTargetGroupRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminUpsertTargetGroupRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminUpsertTargetGroupRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Group == nil {
+				m.Group = &TargetGroup{}
+			}
+			if err := m.Group.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminDeleteTargetGroupRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminDeleteTargetGroupRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminDeleteTargetGroupRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminTargetsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminTargetsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminTargetsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Target = append(m.Target, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdminResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdminResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdminResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsSuccess", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsSuccess = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdhocAgentMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdhocAgentMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdhocAgentMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ip", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ip = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Region = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field JobId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JobId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &ProberResultOne{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdhocProbeJob) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdhocProbeJob: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdhocProbeJob: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field JobId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JobId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProberType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Target = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetRegion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetRegion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthProber
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AdhocProbeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AdhocProbeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AdhocProbeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProberType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
 			}
-			if iNdEx >= l {
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			m.Target = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceRegion", wireType)
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ProberTargetsGetRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberTargetsGetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceRegion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LocalRegion", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field AgentIp", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1392,11 +7900,11 @@ func (m *ProberTargetsGetRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.LocalRegion = string(dAtA[iNdEx:postIndex])
+			m.AgentIp = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LocalIp", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetRegion", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1424,8 +7932,27 @@ func (m *ProberTargetsGetRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.LocalIp = string(dAtA[iNdEx:postIndex])
+			m.TargetRegion = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TimeoutSeconds", wireType)
+			}
+			m.TimeoutSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TimeoutSeconds |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProber(dAtA[iNdEx:])
@@ -1451,7 +7978,7 @@ func (m *ProberTargetsGetRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Targets) Unmarshal(dAtA []byte) error {
+func (m *AdhocAgentResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1474,15 +8001,15 @@ func (m *Targets) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Targets: wiretype end group for non-group")
+			return fmt.Errorf("proto: AdhocAgentResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Targets: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AdhocAgentResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ProberType", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Ip", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1510,7 +8037,7 @@ func (m *Targets) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ProberType = string(dAtA[iNdEx:postIndex])
+			m.Ip = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
@@ -1546,7 +8073,41 @@ func (m *Targets) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &ProberResultOne{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1574,7 +8135,7 @@ func (m *Targets) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Target = append(m.Target, string(dAtA[iNdEx:postIndex]))
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1601,7 +8162,7 @@ func (m *Targets) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberTargetsGetResponse) Unmarshal(dAtA []byte) error {
+func (m *AdhocProbeResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1624,15 +8185,47 @@ func (m *ProberTargetsGetResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberTargetsGetResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: AdhocProbeResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberTargetsGetResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AdhocProbeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Targets", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field JobId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JobId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1659,8 +8252,8 @@ func (m *ProberTargetsGetResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Targets = append(m.Targets, &Targets{})
-			if err := m.Targets[len(m.Targets)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Results = append(m.Results, &AdhocAgentResult{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1689,7 +8282,7 @@ func (m *ProberTargetsGetResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberResultPushRequest) Unmarshal(dAtA []byte) error {
+func (m *ClusterMember) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1712,17 +8305,17 @@ func (m *ProberResultPushRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberResultPushRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClusterMember: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberResultPushRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClusterMember: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ProberResults", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1732,25 +8325,55 @@ func (m *ProberResultPushRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthProber
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthProber
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ProberResults = append(m.ProberResults, &ProberResultOne{})
-			if err := m.ProberResults[len(m.ProberResults)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1777,7 +8400,7 @@ func (m *ProberResultPushRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
+func (m *ClusterAgent) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1800,17 +8423,17 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberResultOne: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClusterAgent: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberResultOne: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClusterAgent: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field WorkerName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Report", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1820,29 +8443,33 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthProber
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthProber
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.WorkerName = string(dAtA[iNdEx:postIndex])
+			if m.Report == nil {
+				m.Report = &ProberAgentIpReportRequest{}
+			}
+			if err := m.Report.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastReport", wireType)
 			}
-			var stringLen uint64
+			m.LastReport = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1852,29 +8479,16 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.LastReport |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthProber
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthProber
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.MetricName = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TargetAddr", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastTargetsGet", wireType)
 			}
-			var stringLen uint64
+			m.LastTargetsGet = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1884,27 +8498,33 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.LastTargetsGet |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthProber
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthProber
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastPush", wireType)
 			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			m.LastPush = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastPush |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			m.TargetAddr = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 4:
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SourceRegion", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field CertIdentity", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1932,13 +8552,13 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.SourceRegion = string(dAtA[iNdEx:postIndex])
+			m.CertIdentity = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TargetRegion", wireType)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deregistered", wireType)
 			}
-			var stringLen uint64
+			m.Deregistered = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1948,29 +8568,70 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Deregistered |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProber(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthProber
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthProber
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TargetRegion = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 6:
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ClusterTargetGroup) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProber
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ClusterTargetGroup: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ClusterTargetGroup: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ProbeType", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Group", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -1980,29 +8641,33 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthProber
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthProber
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ProbeType = string(dAtA[iNdEx:postIndex])
+			if m.Group == nil {
+				m.Group = &TargetGroup{}
+			}
+			if err := m.Group.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 7:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TimeStamp", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Deleted", wireType)
 			}
-			m.TimeStamp = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -2012,22 +8677,12 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.TimeStamp |= int64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 8:
-			if wireType != 5 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
-			}
-			var v uint32
-			if (iNdEx + 4) > l {
-				return io.ErrUnexpectedEOF
-			}
-			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
-			iNdEx += 4
-			m.Value = float32(math.Float32frombits(v))
+			m.Deleted = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProber(dAtA[iNdEx:])
@@ -2053,7 +8708,7 @@ func (m *ProberResultOne) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberResultPushResponse) Unmarshal(dAtA []byte) error {
+func (m *ClusterState) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2076,17 +8731,17 @@ func (m *ProberResultPushResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberResultPushResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClusterState: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberResultPushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClusterState: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SuccessNum", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Self", wireType)
 			}
-			m.SuccessNum = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -2096,11 +8751,130 @@ func (m *ProberResultPushResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.SuccessNum |= int32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Self == nil {
+				m.Self = &ClusterMember{}
+			}
+			if err := m.Self.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Members = append(m.Members, &ClusterMember{})
+			if err := m.Members[len(m.Members)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Agents", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Agents = append(m.Agents, &ClusterAgent{})
+			if err := m.Agents[len(m.Agents)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetGroups", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProber
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthProber
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthProber
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetGroups = append(m.TargetGroups, &ClusterTargetGroup{})
+			if err := m.TargetGroups[len(m.TargetGroups)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProber(dAtA[iNdEx:])
@@ -2126,7 +8900,7 @@ func (m *ProberResultPushResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
+func (m *ClusterForwardRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2149,15 +8923,15 @@ func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberAgentIpReportRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClusterForwardRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberAgentIpReportRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClusterForwardRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Ip", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2185,13 +8959,13 @@ func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Ip = string(dAtA[iNdEx:postIndex])
+			m.From = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Region", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ProberResults", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -2201,23 +8975,25 @@ func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthProber
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthProber
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Region = string(dAtA[iNdEx:postIndex])
+			m.ProberResults = append(m.ProberResults, &ProberResultOne{})
+			if err := m.ProberResults[len(m.ProberResults)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2244,7 +9020,7 @@ func (m *ProberAgentIpReportRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ProberAgentIpReportResponse) Unmarshal(dAtA []byte) error {
+func (m *ClusterForwardResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2267,17 +9043,17 @@ func (m *ProberAgentIpReportResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ProberAgentIpReportResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClusterForwardResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ProberAgentIpReportResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClusterForwardResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IsSuccess", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SuccessNum", wireType)
 			}
-			var v int
+			m.SuccessNum = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProber
@@ -2287,12 +9063,11 @@ func (m *ProberAgentIpReportResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				m.SuccessNum |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.IsSuccess = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProber(dAtA[iNdEx:])
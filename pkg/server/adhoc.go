@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const (
+	adhocApiPath = `/api/v1/admin/probe`
+
+	defaultAdhocTimeout = 30 * time.Second
+	maxAdhocTimeout     = 5 * time.Minute
+)
+
+var (
+	adhocSessions = make(map[string]*adhocSession)
+	adhocMux      sync.Mutex
+	adhocJobSeq   int64
+)
+
+// adhocSession is one connected agent stream
+type adhocSession struct {
+	ip     string
+	region string
+	jobs   chan *pb.AdhocProbeJob
+
+	mux     sync.Mutex
+	pending map[string]chan *pb.AdhocAgentMessage
+}
+
+func (as *adhocSession) wait(jobId string) chan *pb.AdhocAgentMessage {
+	ch := make(chan *pb.AdhocAgentMessage, 1)
+	as.mux.Lock()
+	as.pending[jobId] = ch
+	as.mux.Unlock()
+	return ch
+}
+
+func (as *adhocSession) done(jobId string) {
+	as.mux.Lock()
+	delete(as.pending, jobId)
+	as.mux.Unlock()
+}
+
+func (as *adhocSession) deliver(msg *pb.AdhocAgentMessage) {
+	as.mux.Lock()
+	ch, ok := as.pending[msg.JobId]
+	as.mux.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+type PAdhoc struct {
+	pb.UnimplementedProberAdhocServer
+	logger log.Logger
+}
+
+// AdhocProbeStream keeps an agent registered for adhoc jobs until the stream breaks
+func (pa *PAdhoc) AdhocProbeStream(stream pb.ProberAdhoc_AdhocProbeStreamServer) error {
+	hello, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if hello.Ip == "" {
+		return status.Error(codes.InvalidArgument, "first adhoc message must carry the agent ip")
+	}
+	as := &adhocSession{
+		ip:      hello.Ip,
+		region:  hello.Region,
+		jobs:    make(chan *pb.AdhocProbeJob, 16),
+		pending: make(map[string]chan *pb.AdhocAgentMessage),
+	}
+	adhocMux.Lock()
+	adhocSessions[as.ip] = as
+	adhocMux.Unlock()
+	level.Info(pa.logger).Log("msg", "adhoc stream connected", "ip", as.ip, "region", as.region)
+	defer func() {
+		adhocMux.Lock()
+		if adhocSessions[as.ip] == as {
+			delete(adhocSessions, as.ip)
+		}
+		adhocMux.Unlock()
+		level.Info(pa.logger).Log("msg", "adhoc stream closed", "ip", as.ip, "region", as.region)
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			as.deliver(msg)
+		}
+	}()
+	for {
+		select {
+		case job := <-as.jobs:
+			if err := stream.Send(job); err != nil {
+				return err
+			}
+		case err := <-errc:
+			return err
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func validateAdhocRequest(req *pb.AdhocProbeRequest) error {
+	if req.ProberType == "" || req.Target == "" {
+		return fmt.Errorf("prober_type and target are required")
+	}
+	if err := common.ValidateTarget(req.ProberType, req.Target); err != nil {
+		return err
+	}
+	if req.TimeoutSeconds < 0 || time.Duration(req.TimeoutSeconds)*time.Second > maxAdhocTimeout {
+		return fmt.Errorf("timeout_seconds must be between 0 and %d", int(maxAdhocTimeout.Seconds()))
+	}
+	return nil
+}
+
+func selectAdhocSessions(req *pb.AdhocProbeRequest) []*adhocSession {
+	adhocMux.Lock()
+	defer adhocMux.Unlock()
+	var res []*adhocSession
+	for _, as := range adhocSessions {
+		if req.SourceRegion != "" && as.region != req.SourceRegion {
+			continue
+		}
+		if req.AgentIp != "" && as.ip != req.AgentIp {
+			continue
+		}
+		res = append(res, as)
+	}
+	return res
+}
+
+// RunAdhocProbe dispatches a one-shot probe to the matching connected agents
+// and waits for every one of them to answer or time out
+func RunAdhocProbe(ctx context.Context, req *pb.AdhocProbeRequest) (*pb.AdhocProbeResponse, error) {
+	if err := validateAdhocRequest(req); err != nil {
+		return nil, err
+	}
+	sessions := selectAdhocSessions(req)
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no connected agent matches source_region=%q agent_ip=%q", req.SourceRegion, req.AgentIp)
+	}
+	timeout := defaultAdhocTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	job := &pb.AdhocProbeJob{
+		JobId:        fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddInt64(&adhocJobSeq, 1)),
+		ProberType:   req.ProberType,
+		Target:       req.Target,
+		TargetRegion: req.TargetRegion,
+	}
+	resp := &pb.AdhocProbeResponse{JobId: job.JobId}
+	results := make([]*pb.AdhocAgentResult, len(sessions))
+	var wg sync.WaitGroup
+	for i, as := range sessions {
+		wg.Add(1)
+		go func(i int, as *adhocSession) {
+			defer wg.Done()
+			r := &pb.AdhocAgentResult{Ip: as.ip, Region: as.region}
+			results[i] = r
+			ch := as.wait(job.JobId)
+			defer as.done(job.JobId)
+			select {
+			case as.jobs <- job:
+			case <-ctx.Done():
+				r.Error = "dispatch timeout"
+				return
+			}
+			select {
+			case msg := <-ch:
+				r.Results = msg.Results
+				r.Error = msg.Error
+			case <-ctx.Done():
+				r.Error = "probe timeout"
+			}
+		}(i, as)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Ip < results[j].Ip })
+	resp.Results = results
+	return resp, nil
+}
+
+func (pa *PAdmin) AdhocProbe(ctx context.Context, in *pb.AdhocProbeRequest) (*pb.AdhocProbeResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := RunAdhocProbe(ctx, in)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return resp, nil
+}
+
+// adhocHandler serves POST /api/v1/admin/probe with an AdhocProbeRequest body
+func adhocHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminHttp(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJsonError(w, http.StatusMethodNotAllowed, "only POST is allowed")
+		return
+	}
+	req := &pb.AdhocProbeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := RunAdhocProbe(r.Context(), req)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJson(w, http.StatusOK, resp)
+}
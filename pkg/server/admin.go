@@ -0,0 +1,451 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const (
+	TargetGroupSourceFile  = `file`
+	TargetGroupSourceAdmin = `admin`
+
+	adminApiPrefix = `/api/v1/admin/target_groups`
+)
+
+var (
+	AdminS *AdminStore
+
+	SupportedProberTypes = []string{"icmp", "http"}
+)
+
+// AdminStore keeps the target groups managed at runtime through the admin
+// api, persisted to a local state file and merged with file defined targets
+type AdminStore struct {
+	logger log.Logger
+	cfg    *AdminConfig
+	tfm    *TargetFlushManager
+
+	mux    sync.RWMutex
+	groups map[string]*pb.TargetGroup
+	// delete time of removed groups in unix nanos, kept for cluster sync
+	deleted map[string]int64
+}
+
+func NewAdminStore(logger log.Logger, cfg *AdminConfig, tfm *TargetFlushManager) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Token == "" {
+		return fmt.Errorf("admin api needs a token")
+	}
+	as := &AdminStore{
+		logger:  log.With(logger, "component", "admin"),
+		cfg:     cfg,
+		tfm:     tfm,
+		groups:  make(map[string]*pb.TargetGroup),
+		deleted: make(map[string]int64),
+	}
+	if err := as.load(); err != nil {
+		return err
+	}
+	AdminS = as
+	return nil
+}
+
+// adminState is the content of the admin state file, older versions wrote
+// only the list of groups
+type adminState struct {
+	Groups  []*pb.TargetGroup `json:"groups"`
+	Deleted map[string]int64  `json:"deleted,omitempty"`
+}
+
+func (as *AdminStore) load() error {
+	if as.cfg.StateFile == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(as.cfg.StateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var st adminState
+	if trimmed := strings.TrimSpace(string(content)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(content, &st.Groups)
+	} else {
+		err = json.Unmarshal(content, &st)
+	}
+	if err != nil {
+		return fmt.Errorf("parse admin state file %s: %v", as.cfg.StateFile, err)
+	}
+	for _, g := range st.Groups {
+		if err := validateTargetGroup(g); err != nil {
+			level.Warn(as.logger).Log("msg", "skip invalid target group in admin state file", "err", err)
+			continue
+		}
+		as.groups[g.Name] = g
+	}
+	expire := time.Now().Add(-clusterTombstoneTTL).UnixNano()
+	for name, t := range st.Deleted {
+		if _, ok := as.groups[name]; ok || t < expire {
+			continue
+		}
+		as.deleted[name] = t
+	}
+	level.Info(as.logger).Log("msg", "admin target groups loaded", "groups", len(as.groups), "deleted", len(as.deleted))
+	return nil
+}
+
+// save must be called with mux held
+func (as *AdminStore) save() error {
+	if as.cfg.StateFile == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(&adminState{Groups: as.sortedGroups(), Deleted: as.deleted}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(as.cfg.StateFile, content)
+}
+
+func (as *AdminStore) sortedGroups() []*pb.TargetGroup {
+	res := make([]*pb.TargetGroup, 0, len(as.groups))
+	for _, g := range as.groups {
+		res = append(res, g)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func (as *AdminStore) mergeInto(rs *regionTargetSet) {
+	if as == nil {
+		return
+	}
+	as.mux.RLock()
+	defer as.mux.RUnlock()
+	for _, g := range as.groups {
+		rs.add(g.ProberType, g.Region, g.Target)
+	}
+}
+
+func (as *AdminStore) CheckToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(as.cfg.Token)) == 1
+}
+
+// ListTargetGroups returns file defined groups first, then the admin ones
+func (as *AdminStore) ListTargetGroups() []*pb.TargetGroup {
+	var res []*pb.TargetGroup
+	if as.tfm != nil {
+		for i, tg := range as.tfm.FileTargets() {
+			res = append(res, &pb.TargetGroup{
+				Name:       fmt.Sprintf("file-%d-%s-%s", i, tg.ProberType, tg.Region),
+				ProberType: tg.ProberType,
+				Region:     tg.Region,
+				Target:     tg.Target,
+				Source:     TargetGroupSourceFile,
+			})
+		}
+	}
+	as.mux.RLock()
+	defer as.mux.RUnlock()
+	return append(res, as.sortedGroups()...)
+}
+
+func validateTargetGroup(g *pb.TargetGroup) error {
+	if g == nil || g.Name == "" {
+		return fmt.Errorf("target group needs a name")
+	}
+	if strings.HasPrefix(g.Name, TargetGroupSourceFile+"-") {
+		return fmt.Errorf("target group name prefix %s- is reserved for file defined groups", TargetGroupSourceFile)
+	}
+	if g.Region == "" {
+		return fmt.Errorf("target group %s needs a region", g.Name)
+	}
+	if !stringIn(g.ProberType, SupportedProberTypes) {
+		return fmt.Errorf("target group %s: unsupported prober_type %q", g.Name, g.ProberType)
+	}
+	return validateTargets(g.Name, g.ProberType, g.Target)
+}
+
+func validateTargets(name, proberType string, targets []string) error {
+	for _, t := range targets {
+		if err := common.ValidateTarget(proberType, t); err != nil {
+			return fmt.Errorf("target group %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// update applies fn to the groups, persists them and rebuilds the target pool
+func (as *AdminStore) update(fn func() error) error {
+	as.mux.Lock()
+	if err := fn(); err != nil {
+		as.mux.Unlock()
+		return err
+	}
+	err := as.save()
+	as.mux.Unlock()
+	if err != nil {
+		level.Error(as.logger).Log("msg", "save admin state file error", "err", err)
+		return err
+	}
+	if as.tfm != nil {
+		go as.tfm.Refresh()
+	}
+	return nil
+}
+
+func (as *AdminStore) UpsertTargetGroup(g *pb.TargetGroup) error {
+	if err := validateTargetGroup(g); err != nil {
+		return err
+	}
+	ng := &pb.TargetGroup{
+		Name:       g.Name,
+		ProberType: g.ProberType,
+		Region:     g.Region,
+		Target:     appendUniq(nil, g.Target...),
+		Source:     TargetGroupSourceAdmin,
+		UpdatedAt:  time.Now().UnixNano(),
+	}
+	level.Info(as.logger).Log("msg", "upsert target group", "name", g.Name, "prober_type", g.ProberType, "region", g.Region, "targets", len(ng.Target))
+	return as.update(func() error {
+		as.groups[ng.Name] = ng
+		delete(as.deleted, ng.Name)
+		return nil
+	})
+}
+
+func (as *AdminStore) DeleteTargetGroup(name string) error {
+	level.Info(as.logger).Log("msg", "delete target group", "name", name)
+	return as.update(func() error {
+		if _, ok := as.groups[name]; !ok {
+			return errTargetGroupNotFound(name)
+		}
+		delete(as.groups, name)
+		as.deleted[name] = time.Now().UnixNano()
+		return nil
+	})
+}
+
+func (as *AdminStore) AddTargets(name string, targets []string) error {
+	level.Info(as.logger).Log("msg", "add targets", "name", name, "targets", strings.Join(targets, ","))
+	return as.update(func() error {
+		g, ok := as.groups[name]
+		if !ok {
+			return errTargetGroupNotFound(name)
+		}
+		if err := validateTargets(name, g.ProberType, targets); err != nil {
+			return err
+		}
+		ng := *g
+		ng.Target = appendUniq(append([]string(nil), g.Target...), targets...)
+		ng.UpdatedAt = time.Now().UnixNano()
+		as.groups[name] = &ng
+		return nil
+	})
+}
+
+func (as *AdminStore) RemoveTargets(name string, targets []string) error {
+	level.Info(as.logger).Log("msg", "remove targets", "name", name, "targets", strings.Join(targets, ","))
+	return as.update(func() error {
+		g, ok := as.groups[name]
+		if !ok {
+			return errTargetGroupNotFound(name)
+		}
+		drop := make(map[string]bool, len(targets))
+		for _, t := range targets {
+			drop[t] = true
+		}
+		ng := *g
+		ng.Target = nil
+		for _, t := range g.Target {
+			if !drop[t] {
+				ng.Target = append(ng.Target, t)
+			}
+		}
+		ng.UpdatedAt = time.Now().UnixNano()
+		as.groups[name] = &ng
+		return nil
+	})
+}
+
+type targetGroupNotFoundError string
+
+func (e targetGroupNotFoundError) Error() string {
+	return "target group not found: " + string(e)
+}
+
+func errTargetGroupNotFound(name string) error {
+	return targetGroupNotFoundError(name)
+}
+
+func bearerToken(auth string) string {
+	const prefix = "Bearer "
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return auth[len(prefix):]
+	}
+	return ""
+}
+
+// checkAdminGrpc validates the `authorization: Bearer <token>` metadata
+func checkAdminGrpc(ctx context.Context) error {
+	if AdminS == nil {
+		return status.Error(codes.Unimplemented, "admin api is disabled")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if AdminS.CheckToken(bearerToken(v)) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid admin token")
+}
+
+func adminGrpcError(err error) error {
+	if _, ok := err.(targetGroupNotFoundError); ok {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+type PAdmin struct {
+	pb.UnimplementedProberAdminServer
+	logger log.Logger
+}
+
+func (pa *PAdmin) ListTargetGroups(ctx context.Context, in *pb.AdminListTargetGroupsRequest) (*pb.AdminListTargetGroupsResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.AdminListTargetGroupsResponse{Groups: AdminS.ListTargetGroups()}, nil
+}
+
+func (pa *PAdmin) UpsertTargetGroup(ctx context.Context, in *pb.AdminUpsertTargetGroupRequest) (*pb.AdminResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	if err := AdminS.UpsertTargetGroup(in.Group); err != nil {
+		return nil, adminGrpcError(err)
+	}
+	return &pb.AdminResponse{IsSuccess: true}, nil
+}
+
+func (pa *PAdmin) DeleteTargetGroup(ctx context.Context, in *pb.AdminDeleteTargetGroupRequest) (*pb.AdminResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	if err := AdminS.DeleteTargetGroup(in.Name); err != nil {
+		return nil, adminGrpcError(err)
+	}
+	return &pb.AdminResponse{IsSuccess: true}, nil
+}
+
+func (pa *PAdmin) AddTargets(ctx context.Context, in *pb.AdminTargetsRequest) (*pb.AdminResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	if err := AdminS.AddTargets(in.Name, in.Target); err != nil {
+		return nil, adminGrpcError(err)
+	}
+	return &pb.AdminResponse{IsSuccess: true}, nil
+}
+
+func (pa *PAdmin) RemoveTargets(ctx context.Context, in *pb.AdminTargetsRequest) (*pb.AdminResponse, error) {
+	if err := checkAdminGrpc(ctx); err != nil {
+		return nil, err
+	}
+	if err := AdminS.RemoveTargets(in.Name, in.Target); err != nil {
+		return nil, adminGrpcError(err)
+	}
+	return &pb.AdminResponse{IsSuccess: true}, nil
+}
+
+/*
+   http admin api, every call needs `Authorization: Bearer <token>`
+   GET    /api/v1/admin/target_groups
+   PUT    /api/v1/admin/target_groups/<name>          body: TargetGroup json
+   DELETE /api/v1/admin/target_groups/<name>
+   POST   /api/v1/admin/target_groups/<name>/targets  body: {"target": [...]}
+   DELETE /api/v1/admin/target_groups/<name>/targets  body: {"target": [...]}
+*/
+// checkAdminHttp writes the error response itself when the call is not allowed
+func checkAdminHttp(w http.ResponseWriter, r *http.Request) bool {
+	if AdminS == nil {
+		writeJsonError(w, http.StatusNotFound, "admin api is disabled")
+		return false
+	}
+	if !AdminS.CheckToken(bearerToken(r.Header.Get("Authorization"))) {
+		writeJsonError(w, http.StatusUnauthorized, "invalid admin token")
+		return false
+	}
+	return true
+}
+
+func adminHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminHttp(w, r) {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, adminApiPrefix), "/")
+	parts := strings.Split(path, "/")
+	var err error
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		writeJson(w, http.StatusOK, AdminS.ListTargetGroups())
+		return
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		g := &pb.TargetGroup{}
+		if err = json.NewDecoder(r.Body).Decode(g); err == nil {
+			g.Name = parts[0]
+			err = AdminS.UpsertTargetGroup(g)
+		}
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		err = AdminS.DeleteTargetGroup(parts[0])
+	case len(parts) == 2 && parts[1] == "targets" && (r.Method == http.MethodPost || r.Method == http.MethodDelete):
+		req := &pb.AdminTargetsRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err == nil {
+			if r.Method == http.MethodPost {
+				err = AdminS.AddTargets(parts[0], req.Target)
+			} else {
+				err = AdminS.RemoveTargets(parts[0], req.Target)
+			}
+		}
+	default:
+		writeJsonError(w, http.StatusNotFound, "unknown admin api: "+r.Method+" "+r.URL.Path)
+		return
+	}
+	if err != nil {
+		code := http.StatusBadRequest
+		if _, ok := err.(targetGroupNotFoundError); ok {
+			code = http.StatusNotFound
+		}
+		writeJsonError(w, code, err.Error())
+		return
+	}
+	writeJson(w, http.StatusOK, &pb.AdminResponse{IsSuccess: true})
+}
+
+func RegisterAdminHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(adminApiPrefix, adminHandler)
+	mux.HandleFunc(adminApiPrefix+"/", adminHandler)
+	mux.HandleFunc(adhocApiPath, adhocHandler)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"xprober/pkg/pb"
+)
+
+func newTestAdminStore(t *testing.T, stateFile string) *AdminStore {
+	as := &AdminStore{
+		logger:  log.NewNopLogger(),
+		cfg:     &AdminConfig{Token: "tok", StateFile: stateFile},
+		groups:  make(map[string]*pb.TargetGroup),
+		deleted: make(map[string]int64),
+	}
+	if err := as.load(); err != nil {
+		t.Fatal(err)
+	}
+	return as
+}
+
+func TestAdminStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xprober-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "targets.json")
+
+	// the old format holds only the groups, bad ones are skipped
+	old := `[
+  {"name": "g1", "prober_type": "icmp", "region": "r1", "target": ["10.0.1.1"]},
+  {"name": "g2", "prober_type": "tcp", "region": "r1", "target": ["10.0.1.1:443"]},
+  {"name": "g3", "prober_type": "icmp", "region": "r1", "target": ["$(id)"]},
+  {"name": "file-0-icmp-r1", "prober_type": "icmp", "region": "r1", "target": ["10.0.1.2"]}
+]`
+	if err := ioutil.WriteFile(stateFile, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	as := newTestAdminStore(t, stateFile)
+	if len(as.groups) != 1 || as.groups["g1"] == nil {
+		t.Fatalf("loaded groups %v, want only g1", as.sortedGroups())
+	}
+
+	now := time.Now().UnixNano()
+	as.groups["g4"] = &pb.TargetGroup{Name: "g4", ProberType: "http", Region: "r2", Target: []string{"http://10.0.1.3/health"}}
+	as.deleted["g5"] = now
+	as.deleted["g6"] = time.Now().Add(-2 * clusterTombstoneTTL).UnixNano()
+	if err := as.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	as = newTestAdminStore(t, stateFile)
+	if len(as.groups) != 2 || as.groups["g1"] == nil || as.groups["g4"] == nil {
+		t.Fatalf("reloaded groups %v, want g1 and g4", as.sortedGroups())
+	}
+	if len(as.deleted) != 1 || as.deleted["g5"] != now {
+		t.Fatalf("reloaded tombstones %v, want only the unexpired g5", as.deleted)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+// agents silent for longer are left out of the agent metrics
+const agentMetricsExpireSeconds = 600
+
+var (
+	agentRegistryMux sync.Mutex
+	agentRegistry    = make(map[string]*api.AgentInfo)
+
+	agentInfoDesc = prometheus.NewDesc(common.MetricsNameAgentInfo,
+		"agent inventory, always 1",
+		[]string{"ip", "region", "version", "hostname", "instance_id", "probe_types"}, nil)
+	agentTargetsDesc = prometheus.NewDesc(common.MetricsNameAgentTargets,
+		"number of local targets the agent probes",
+		[]string{"ip", "region"}, nil)
+	agentProbeCycleDesc = prometheus.NewDesc(common.MetricsNameAgentProbeCycleSeconds,
+		"duration of the agent's last probe cycle",
+		[]string{"ip", "region"}, nil)
+	agentLastPushDesc = prometheus.NewDesc(common.MetricsNameAgentLastPush,
+		"unix time the server last received results from the agent",
+		[]string{"ip", "region"}, nil)
+	agentLastReportDesc = prometheus.NewDesc(common.MetricsNameAgentLastReport,
+		"unix time of the agent's last inventory report",
+		[]string{"ip", "region"}, nil)
+	agentResultFreshnessDesc = prometheus.NewDesc(common.MetricsNameAgentResultFreshnessSeconds,
+		"age of the newest result received from the agent",
+		[]string{"ip", "region"}, nil)
+)
+
+// updateAgentInventory records a report of the agent
+func updateAgentInventory(in *pb.ProberAgentIpReportRequest, identity string) {
+	touchAgent(in.Ip, func(a *api.AgentInfo) {
+		a.CertIdentity = identity
+		a.LastReport = nowUnix()
+		applyAgentReport(a, in)
+	})
+}
+
+func applyAgentReport(a *api.AgentInfo, in *pb.ProberAgentIpReportRequest) {
+	a.Region = in.Region
+	a.Version = in.Version
+	a.Hostname = in.Hostname
+	a.InstanceId = in.InstanceId
+	a.ProbeTypes = append([]string(nil), in.ProbeTypes...)
+	sort.Strings(a.ProbeTypes)
+	a.TargetNum = in.TargetNum
+	a.LastProbeCycleSeconds = in.LastProbeCycleSeconds
+	a.ReportedLastPush = in.LastPush
+	a.ConnectedServer = in.ConnectedServer
+}
+
+// agentReport rebuilds the last report of the agent
+func agentReport(a *api.AgentInfo) *pb.ProberAgentIpReportRequest {
+	return &pb.ProberAgentIpReportRequest{
+		Ip:                    a.Ip,
+		Region:                a.Region,
+		Version:               a.Version,
+		Hostname:              a.Hostname,
+		InstanceId:            a.InstanceId,
+		ProbeTypes:            a.ProbeTypes,
+		TargetNum:             a.TargetNum,
+		LastProbeCycleSeconds: a.LastProbeCycleSeconds,
+		LastPush:              a.ReportedLastPush,
+		ConnectedServer:       a.ConnectedServer,
+	}
+}
+
+// agentGone tells whether the agent deregistered and did not report since
+func agentGone(a *api.AgentInfo) bool {
+	return a.Deregistered > 0 && a.Deregistered >= a.LastReport
+}
+
+// deregisterAgent takes the agent out of the target pool, its entry stays
+// as a tombstone for the cluster
+func deregisterAgent(ip string, at int64) {
+	touchAgent(ip, func(a *api.AgentInfo) {
+		if at > a.Deregistered {
+			a.Deregistered = at
+		}
+	})
+	forgetAgent(ip)
+}
+
+// forgetAgent drops the pool entry, results and sequence state of an agent,
+// results of other agents probing it expire as usual once they stop
+func forgetAgent(ip string) {
+	AgentIpRegionMap.Delete(ip)
+	for _, dm := range []*sync.Map{&IcmpDataMap, &HttpDataMap} {
+		dm.Range(func(k, v interface{}) bool {
+			prr := v.(*pb.ProberResultOne)
+			if prr.WorkerName == ip || prr.TargetAddr == ip {
+				dm.Delete(k)
+			}
+			return true
+		})
+	}
+	seqTrackersMux.Lock()
+	delete(seqTrackers, ip)
+	seqTrackersMux.Unlock()
+	if TFM != nil {
+		go TFM.Refresh()
+	}
+}
+
+// touchAgent applies fn to the agent entry, creating it when needed
+func touchAgent(ip string, fn func(a *api.AgentInfo)) {
+	if ip == "" {
+		return
+	}
+	agentRegistryMux.Lock()
+	defer agentRegistryMux.Unlock()
+	a, ok := agentRegistry[ip]
+	if !ok {
+		a = &api.AgentInfo{Ip: ip}
+		agentRegistry[ip] = a
+	}
+	fn(a)
+}
+
+func ListAgents() []*api.AgentInfo {
+	agentRegistryMux.Lock()
+	res := make([]*api.AgentInfo, 0, len(agentRegistry))
+	for _, a := range agentRegistry {
+		c := *a
+		res = append(res, &c)
+	}
+	agentRegistryMux.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Region != res[j].Region {
+			return res[i].Region < res[j].Region
+		}
+		return res[i].Ip < res[j].Ip
+	})
+	return res
+}
+
+func agentRegion(ip string) string {
+	if v, ok := AgentIpRegionMap.Load(ip); ok {
+		return v.(string)
+	}
+	return ""
+}
+
+func agentsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, ListAgents())
+}
+
+// targetsHandler serves /api/v1/targets?agent=ip or ?region=xxx, the targets
+// handed out to an agent of that region
+func targetsHandler(w http.ResponseWriter, r *http.Request) {
+	region := r.URL.Query().Get("region")
+	if ip := r.URL.Query().Get("agent"); ip != "" {
+		region = agentRegion(ip)
+		if region == "" {
+			writeJsonError(w, http.StatusNotFound, "unknown agent: "+ip)
+			return
+		}
+	}
+	if region == "" {
+		writeJsonError(w, http.StatusBadRequest, "agent or region is required")
+		return
+	}
+	tgs := GetTargetsByRegion(region)
+	if tgs == nil {
+		tgs = []*pb.Targets{}
+	}
+	writeJson(w, http.StatusOK, tgs)
+}
+
+func nowUnix() int64 {
+	return time.Now().Unix()
+}
+
+// agentCollector exports the registry at scrape time so agents that went
+// away or changed version leave no stale series behind
+type agentCollector struct{}
+
+func (agentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- agentInfoDesc
+	ch <- agentTargetsDesc
+	ch <- agentProbeCycleDesc
+	ch <- agentLastPushDesc
+	ch <- agentLastReportDesc
+	ch <- agentResultFreshnessDesc
+}
+
+func (agentCollector) Collect(ch chan<- prometheus.Metric) {
+	now := nowUnix()
+	for _, a := range ListAgents() {
+		lastSeen := a.LastReport
+		if a.LastPush > lastSeen {
+			lastSeen = a.LastPush
+		}
+		if a.LastTargetsGet > lastSeen {
+			lastSeen = a.LastTargetsGet
+		}
+		if now-lastSeen > agentMetricsExpireSeconds || agentGone(a) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(agentInfoDesc, prometheus.GaugeValue, 1,
+			a.Ip, a.Region, a.Version, a.Hostname, a.InstanceId, strings.Join(a.ProbeTypes, ","))
+		ch <- prometheus.MustNewConstMetric(agentTargetsDesc, prometheus.GaugeValue, float64(a.TargetNum), a.Ip, a.Region)
+		ch <- prometheus.MustNewConstMetric(agentProbeCycleDesc, prometheus.GaugeValue, a.LastProbeCycleSeconds, a.Ip, a.Region)
+		ch <- prometheus.MustNewConstMetric(agentLastPushDesc, prometheus.GaugeValue, float64(a.LastPush), a.Ip, a.Region)
+		ch <- prometheus.MustNewConstMetric(agentLastReportDesc, prometheus.GaugeValue, float64(a.LastReport), a.Ip, a.Region)
+		if a.NewestResult > 0 {
+			ch <- prometheus.MustNewConstMetric(agentResultFreshnessDesc, prometheus.GaugeValue, float64(now-a.NewestResult), a.Ip, a.Region)
+		}
+	}
+}
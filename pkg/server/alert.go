@@ -0,0 +1,435 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+)
+
+const (
+	AlertStatePending  = `pending`
+	AlertStateFiring   = `firing`
+	AlertStateResolved = `resolved`
+
+	AlertEvalInterval = 15 * time.Second
+	// aggregates not updated within this window are treated as gone
+	alertSeriesStaleAfter      = 5 * time.Minute
+	defaultAlertRepeatInterval = time.Hour
+	defaultWebhookTimeout      = 10 * time.Second
+)
+
+var (
+	AlertM *AlertManager
+
+	AlertsGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameAlerts,
+		Help: "number of pending or firing alerts per rule",
+	}, []string{"alertname", "state"})
+	AlertNotificationsCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameAlertNotifications,
+		Help: "alert webhook notifications sent",
+	}, []string{"webhook", "status"})
+	AlertNotificationFailuresCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameAlertNotificationFailures,
+		Help: "failed alert webhook notifications",
+	}, []string{"webhook"})
+)
+
+// aggregateSeries is the latest processed value of one region pair or http addr
+type aggregateSeries struct {
+	MetricName string
+	Labels     prometheus.Labels
+	Value      float64
+	UpdatedAt  time.Time
+}
+
+func seriesKey(metricName string, labels prometheus.Labels) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{metricName}
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, MetricUniqueSeparator)
+}
+
+type compiledAlertRule struct {
+	*AlertRule
+	matchers    map[string]*regexp.Regexp
+	annotations map[string]*template.Template
+}
+
+type activeAlert struct {
+	rule       *compiledAlertRule
+	labels     map[string]string
+	value      float64
+	state      string
+	activeAt   time.Time
+	firedAt    time.Time
+	lastSentAt time.Time
+}
+
+type AlertManager struct {
+	logger   log.Logger
+	rules    []*compiledAlertRule
+	webhooks []*WebhookConfig
+	client   *http.Client
+
+	mu     sync.Mutex
+	series map[string]*aggregateSeries
+	active map[string]*activeAlert
+}
+
+func NewAlertManager(logger log.Logger, rules []*AlertRule, webhooks []*WebhookConfig) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	am := &AlertManager{
+		logger:   log.With(logger, "component", "alert"),
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: defaultWebhookTimeout},
+		series:   make(map[string]*aggregateSeries),
+		active:   make(map[string]*activeAlert),
+	}
+	for i, wh := range webhooks {
+		if wh.Name == "" {
+			wh.Name = fmt.Sprintf("webhook-%d", i)
+		}
+	}
+	for _, r := range rules {
+		cr, err := compileAlertRule(r)
+		if err != nil {
+			return err
+		}
+		am.rules = append(am.rules, cr)
+	}
+	AlertM = am
+	return nil
+}
+
+func compileAlertRule(r *AlertRule) (*compiledAlertRule, error) {
+	if r.Name == "" || r.Metric == "" {
+		return nil, fmt.Errorf("alert rule needs name and metric")
+	}
+	if _, ok := compareFuncs[r.Op]; !ok {
+		return nil, fmt.Errorf("alert rule %s: unknown op %q", r.Name, r.Op)
+	}
+	cr := &compiledAlertRule{
+		AlertRule:   r,
+		annotations: make(map[string]*template.Template),
+	}
+	matchers, err := compileLabelMatchers(r.SourceRegion, r.TargetRegion, r.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("alert rule %s: %v", r.Name, err)
+	}
+	cr.matchers = matchers
+	for k, v := range r.Annotations {
+		t, err := template.New(k).Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: bad annotation %s: %v", r.Name, k, err)
+		}
+		cr.annotations[k] = t
+	}
+	return cr, nil
+}
+
+var compareFuncs = map[string]func(a, b float64) bool{
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// compileLabelMatchers builds anchored regexps for the non empty filters
+func compileLabelMatchers(sourceRegion, targetRegion, addr string) (map[string]*regexp.Regexp, error) {
+	matchers := make(map[string]*regexp.Regexp)
+	for label, expr := range map[string]string{"source_region": sourceRegion, "target_region": targetRegion, "addr": addr} {
+		if expr == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("bad %s matcher: %v", label, err)
+		}
+		matchers[label] = re
+	}
+	return matchers, nil
+}
+
+func matchLabels(matchers map[string]*regexp.Regexp, labels map[string]string) bool {
+	for label, re := range matchers {
+		if !re.MatchString(labels[label]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *compiledAlertRule) matches(s *aggregateSeries) bool {
+	return s.MetricName == r.Metric && matchLabels(r.matchers, s.Labels)
+}
+
+// Observe records the latest processed value for rule evaluation
+func (am *AlertManager) Observe(metricName string, labels prometheus.Labels, value float64) {
+	if am == nil {
+		return
+	}
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.series[seriesKey(metricName, labels)] = &aggregateSeries{
+		MetricName: metricName,
+		Labels:     labels,
+		Value:      value,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+func (am *AlertManager) Run(ctx context.Context) error {
+	if am == nil {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(AlertEvalInterval)
+	level.Info(am.logger).Log("msg", "AlertManager start....", "rules", len(am.rules), "webhooks", len(am.webhooks))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			am.eval(time.Now())
+		case <-ctx.Done():
+			level.Info(am.logger).Log("msg", "AlertManager exit....")
+			return nil
+		}
+	}
+}
+
+func (am *AlertManager) eval(now time.Time) {
+	var toSend []*activeAlert
+	am.mu.Lock()
+	seen := make(map[string]bool)
+	for sk, s := range am.series {
+		if now.Sub(s.UpdatedAt) > alertSeriesStaleAfter {
+			delete(am.series, sk)
+			continue
+		}
+		for _, r := range am.rules {
+			if !r.matches(s) || !compareFuncs[r.Op](s.Value, r.Threshold) {
+				continue
+			}
+			key := r.Name + MetricUniqueSeparator + sk
+			seen[key] = true
+			a, ok := am.active[key]
+			if !ok {
+				a = &activeAlert{rule: r, labels: alertLabels(r, s), state: AlertStatePending, activeAt: now}
+				am.active[key] = a
+			}
+			a.value = s.Value
+			if a.state == AlertStatePending && now.Sub(a.activeAt) >= time.Duration(r.For) {
+				a.state = AlertStateFiring
+				a.firedAt = now
+			}
+			if a.state == AlertStateFiring && now.Sub(a.lastSentAt) >= r.repeatInterval() {
+				a.lastSentAt = now
+				toSend = append(toSend, a.copy())
+			}
+		}
+	}
+	counts := make(map[string]map[string]float64)
+	for _, r := range am.rules {
+		counts[r.Name] = map[string]float64{AlertStatePending: 0, AlertStateFiring: 0}
+	}
+	for key, a := range am.active {
+		if !seen[key] {
+			if a.state == AlertStateFiring {
+				a.state = AlertStateResolved
+				toSend = append(toSend, a.copy())
+			}
+			delete(am.active, key)
+			continue
+		}
+		counts[a.rule.Name][a.state]++
+	}
+	am.mu.Unlock()
+
+	for name, m := range counts {
+		for state, v := range m {
+			AlertsGaugeVec.WithLabelValues(name, state).Set(v)
+		}
+	}
+	if len(toSend) > 0 && ClusterC.IsLeader() {
+		am.notify(toSend, now)
+	}
+}
+
+func (a *activeAlert) copy() *activeAlert {
+	c := *a
+	return &c
+}
+
+func (r *compiledAlertRule) repeatInterval() time.Duration {
+	if r.RepeatInterval > 0 {
+		return time.Duration(r.RepeatInterval)
+	}
+	return defaultAlertRepeatInterval
+}
+
+func alertLabels(r *compiledAlertRule, s *aggregateSeries) map[string]string {
+	labels := map[string]string{
+		"alertname": r.Name,
+		"metric":    s.MetricName,
+	}
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	for k, v := range r.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// WebhookMessage is the alertmanager webhook payload, version 4
+type WebhookMessage struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []*WebhookAlert   `json:"alerts"`
+}
+
+type WebhookAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+func (a *activeAlert) webhookAlert(now time.Time) *WebhookAlert {
+	data := struct {
+		Labels map[string]string
+		Value  float64
+	}{a.labels, a.value}
+	annotations := make(map[string]string, len(a.rule.annotations))
+	for k, t := range a.rule.annotations {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			annotations[k] = err.Error()
+			continue
+		}
+		annotations[k] = buf.String()
+	}
+	wa := &WebhookAlert{
+		Status:      AlertStateFiring,
+		Labels:      a.labels,
+		Annotations: annotations,
+		StartsAt:    a.firedAt,
+		Fingerprint: fingerprint(a.labels),
+	}
+	if a.state == AlertStateResolved {
+		wa.Status = AlertStateResolved
+		wa.EndsAt = now
+	}
+	return wa
+}
+
+func fingerprint(labels map[string]string) string {
+	h := sha256.Sum256([]byte(seriesKey("", labels)))
+	return hex.EncodeToString(h[:8])
+}
+
+// notify sends one message per rule and status to every webhook
+func (am *AlertManager) notify(alerts []*activeAlert, now time.Time) {
+	groups := make(map[string][]*WebhookAlert)
+	for _, a := range alerts {
+		wa := a.webhookAlert(now)
+		gk := a.rule.Name + MetricUniqueSeparator + wa.Status
+		groups[gk] = append(groups[gk], wa)
+	}
+	for gk, was := range groups {
+		parts := strings.SplitN(gk, MetricUniqueSeparator, 2)
+		msg := &WebhookMessage{
+			Version:           "4",
+			GroupKey:          fmt.Sprintf("{}:{alertname=%q}", parts[0]),
+			Status:            parts[1],
+			Receiver:          "xprober",
+			GroupLabels:       map[string]string{"alertname": parts[0]},
+			CommonLabels:      commonMap(was, func(wa *WebhookAlert) map[string]string { return wa.Labels }),
+			CommonAnnotations: commonMap(was, func(wa *WebhookAlert) map[string]string { return wa.Annotations }),
+			Alerts:            was,
+		}
+		body, err := json.Marshal(msg)
+		if err != nil {
+			level.Error(am.logger).Log("msg", "marshal webhook message error", "err", err)
+			continue
+		}
+		for _, wh := range am.webhooks {
+			am.send(wh, body, msg.Status)
+		}
+		level.Info(am.logger).Log("msg", "alert notification", "alertname", parts[0], "status", parts[1], "alerts", len(was))
+	}
+}
+
+func (am *AlertManager) send(wh *WebhookConfig, body []byte, status string) {
+	timeout := time.Duration(wh.Timeout)
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var setAuth func(*http.Request)
+	if wh.BearerToken != "" {
+		setAuth = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+wh.BearerToken) }
+	}
+	if err := postSinkBody(ctx, am.client, wh.URL, "application/json", body, setAuth); err != nil {
+		level.Error(am.logger).Log("msg", "send webhook error", "webhook", wh.Name, "err", err)
+		AlertNotificationFailuresCounterVec.WithLabelValues(wh.Name).Inc()
+		return
+	}
+	AlertNotificationsCounterVec.WithLabelValues(wh.Name, status).Inc()
+}
+
+func commonMap(was []*WebhookAlert, get func(*WebhookAlert) map[string]string) map[string]string {
+	res := make(map[string]string)
+	if len(was) == 0 {
+		return res
+	}
+	for k, v := range get(was[0]) {
+		res[k] = v
+	}
+	for _, wa := range was[1:] {
+		m := get(wa)
+		for k, v := range res {
+			if m[k] != v {
+				delete(res, k)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+)
+
+func TestAlertTransitionsAndWebhookPayload(t *testing.T) {
+	msgs := make(chan *WebhookMessage, 4)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer wh-token" {
+			t.Errorf("webhook auth header %q", r.Header.Get("Authorization"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		var msg WebhookMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		msgs <- &msg
+	}))
+	defer ts.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	rule := &AlertRule{
+		Name: "HighLatency", Metric: "m", Op: ">", Threshold: 50, For: model.Duration(time.Minute),
+		SourceRegion: "r1",
+		Labels:       map[string]string{"severity": "critical"},
+		Annotations:  map[string]string{"summary": "{{ .Labels.source_region }} -> {{ .Labels.target_region }} {{ .Value }}"},
+	}
+	webhooks := []*WebhookConfig{
+		{URL: ts.URL + "/send?access_token=secret", BearerToken: "wh-token"},
+		{Name: "down", URL: down.URL + "/send?access_token=secret"},
+	}
+	if err := NewAlertManager(log.NewNopLogger(), []*AlertRule{rule}, webhooks); err != nil {
+		t.Fatal(err)
+	}
+	am := AlertM
+	AlertM = nil
+	if webhooks[0].Name != "webhook-0" {
+		t.Fatalf("default webhook name %q, want webhook-0", webhooks[0].Name)
+	}
+
+	sent := func(status string) float64 {
+		return testutil.ToFloat64(AlertNotificationsCounterVec.WithLabelValues("webhook-0", status))
+	}
+	firingSent, resolvedSent := sent(AlertStateFiring), sent(AlertStateResolved)
+	failed := testutil.ToFloat64(AlertNotificationFailuresCounterVec.WithLabelValues("down"))
+
+	t0 := time.Now()
+	am.Observe("m", prometheus.Labels{"source_region": "r1", "target_region": "r2"}, 80)
+	// filtered out by source_region
+	am.Observe("m", prometheus.Labels{"source_region": "r3", "target_region": "r2"}, 80)
+
+	steps := []struct {
+		name    string
+		at      time.Time
+		value   float64
+		pending float64
+		firing  float64
+		status  string
+	}{
+		{"pending until for", t0, 80, 1, 0, ""},
+		{"firing after for", t0.Add(time.Minute), 80, 0, 1, AlertStateFiring},
+		{"no repeat within repeat_interval", t0.Add(time.Minute + AlertEvalInterval), 80, 0, 1, ""},
+		{"resolved below threshold", t0.Add(2 * time.Minute), 20, 0, 0, AlertStateResolved},
+	}
+	for _, s := range steps {
+		am.Observe("m", prometheus.Labels{"source_region": "r1", "target_region": "r2"}, s.value)
+		am.eval(s.at)
+		pending := testutil.ToFloat64(AlertsGaugeVec.WithLabelValues(rule.Name, AlertStatePending))
+		firing := testutil.ToFloat64(AlertsGaugeVec.WithLabelValues(rule.Name, AlertStateFiring))
+		if pending != s.pending || firing != s.firing {
+			t.Fatalf("%s: pending=%v firing=%v, want %v and %v", s.name, pending, firing, s.pending, s.firing)
+		}
+		if s.status == "" {
+			if len(msgs) != 0 {
+				t.Fatalf("%s: unexpected notification %+v", s.name, <-msgs)
+			}
+			continue
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("%s: got %d notifications, want 1", s.name, len(msgs))
+		}
+		msg := <-msgs
+		if msg.Version != "4" || msg.Status != s.status || msg.Receiver != "xprober" ||
+			msg.GroupKey != `{}:{alertname="HighLatency"}` || msg.GroupLabels["alertname"] != rule.Name {
+			t.Fatalf("%s: bad message header %+v", s.name, msg)
+		}
+		if len(msg.Alerts) != 1 {
+			t.Fatalf("%s: got %d alerts, want 1", s.name, len(msg.Alerts))
+		}
+		a := msg.Alerts[0]
+		wantLabels := map[string]string{
+			"alertname": rule.Name, "metric": "m", "severity": "critical",
+			"source_region": "r1", "target_region": "r2",
+		}
+		for k, v := range wantLabels {
+			if a.Labels[k] != v || msg.CommonLabels[k] != v {
+				t.Fatalf("%s: label %s=%q common=%q, want %q", s.name, k, a.Labels[k], msg.CommonLabels[k], v)
+			}
+		}
+		if a.Status != s.status || a.Fingerprint == "" || !a.StartsAt.Equal(t0.Add(time.Minute)) {
+			t.Fatalf("%s: bad alert %+v", s.name, a)
+		}
+		if a.Annotations["summary"] != "r1 -> r2 80" {
+			t.Fatalf("%s: summary %q", s.name, a.Annotations["summary"])
+		}
+		if s.status == AlertStateFiring && !a.EndsAt.IsZero() {
+			t.Fatalf("%s: firing alert ends at %v", s.name, a.EndsAt)
+		}
+		if s.status == AlertStateResolved && !a.EndsAt.Equal(s.at) {
+			t.Fatalf("%s: resolved alert ends at %v, want %v", s.name, a.EndsAt, s.at)
+		}
+	}
+
+	if n := sent(AlertStateFiring) - firingSent; n != 1 {
+		t.Fatalf("firing notifications grew by %v, want 1", n)
+	}
+	if n := sent(AlertStateResolved) - resolvedSent; n != 1 {
+		t.Fatalf("resolved notifications grew by %v, want 1", n)
+	}
+	if n := testutil.ToFloat64(AlertNotificationFailuresCounterVec.WithLabelValues("down")) - failed; n != 2 {
+		t.Fatalf("failures of the down webhook grew by %v, want 2", n)
+	}
+	err := postSinkBody(context.Background(), http.DefaultClient, webhooks[1].URL, "application/json", nil, nil)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("post error %v must not carry the url", err)
+	}
+}
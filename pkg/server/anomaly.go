@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+)
+
+const (
+	defaultAnomalyAlpha         = 0.05
+	defaultAnomalySeasonalAlpha = 0.1
+	defaultAnomalyThreshold     = 4.0
+	defaultAnomalyMinSamples    = 40
+	anomalySaveInterval         = 5 * time.Minute
+	maxRecentAnomalies          = 200
+)
+
+var (
+	AnomalyD *AnomalyDetector
+
+	AnomalyScoreGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameAnomalyScore,
+		Help: "deviation of the latest value from the learned baseline in standard deviations",
+	}, []string{"metric", "source_region", "target"})
+	AnomalyEventsCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameAnomalyEvents,
+		Help: "anomaly events raised per metric",
+	}, []string{"metric"})
+)
+
+// ewmaStat is an exponentially weighted mean and variance
+type ewmaStat struct {
+	Mean float64 `json:"mean"`
+	Var  float64 `json:"var"`
+	N    int     `json:"n"`
+}
+
+func (s *ewmaStat) update(x, alpha float64) {
+	if s.N == 0 {
+		s.Mean = x
+		s.Var = 0
+		s.N = 1
+		return
+	}
+	diff := x - s.Mean
+	incr := alpha * diff
+	s.Mean += incr
+	s.Var = (1 - alpha) * (s.Var + diff*incr)
+	s.N++
+}
+
+// baseline keeps a global ewma plus one per hour of day for seasonality
+type baseline struct {
+	Global    ewmaStat     `json:"global"`
+	Hourly    [24]ewmaStat `json:"hourly"`
+	Anomalous bool         `json:"anomalous"`
+}
+
+// AnomalyEvent is raised when a series departs from or returns to its baseline
+type AnomalyEvent struct {
+	Time         time.Time `json:"time"`
+	Metric       string    `json:"metric"`
+	SourceRegion string    `json:"source_region"`
+	Target       string    `json:"target"`
+	Value        float64   `json:"value"`
+	Expected     float64   `json:"expected"`
+	Score        float64   `json:"score"`
+	Resolved     bool      `json:"resolved"`
+}
+
+type AnomalyDetector struct {
+	logger  log.Logger
+	cfg     *AnomalyConfig
+	metrics map[string]bool
+
+	mu        sync.Mutex
+	baselines map[string]*baseline
+	recent    []*AnomalyEvent
+}
+
+func NewAnomalyDetector(logger log.Logger, cfg *AnomalyConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha >= 1 {
+		cfg.Alpha = defaultAnomalyAlpha
+	}
+	if cfg.SeasonalAlpha <= 0 || cfg.SeasonalAlpha >= 1 {
+		cfg.SeasonalAlpha = defaultAnomalySeasonalAlpha
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultAnomalyThreshold
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaultAnomalyMinSamples
+	}
+	ad := &AnomalyDetector{
+		logger:    log.With(logger, "component", "anomaly"),
+		cfg:       cfg,
+		metrics:   make(map[string]bool),
+		baselines: make(map[string]*baseline),
+	}
+	for _, m := range cfg.Metrics {
+		ad.metrics[m] = true
+	}
+	ad.load()
+	AnomalyD = ad
+}
+
+func aggregateTarget(labels prometheus.Labels) string {
+	if t, ok := labels["target_region"]; ok {
+		return t
+	}
+	return labels["addr"]
+}
+
+// Observe scores a processed value against its baseline and then learns it
+func (ad *AnomalyDetector) Observe(metricName string, labels prometheus.Labels, value float64) {
+	if ad == nil {
+		return
+	}
+	if len(ad.metrics) > 0 && !ad.metrics[metricName] {
+		return
+	}
+	// -1 marks a failed probe, it is no latency sample
+	if value < 0 {
+		return
+	}
+	now := time.Now()
+	source, target := labels["source_region"], aggregateTarget(labels)
+	key := seriesKey(metricName, labels)
+
+	ad.mu.Lock()
+	b, ok := ad.baselines[key]
+	if !ok {
+		b = &baseline{}
+		ad.baselines[key] = b
+	}
+	hour := &b.Hourly[now.Hour()]
+	expected, std, ready := ad.expected(b, hour)
+	var score float64
+	if ready {
+		score = (value - expected) / std
+	}
+	b.Global.update(value, ad.cfg.Alpha)
+	hour.update(value, ad.cfg.SeasonalAlpha)
+
+	var ev *AnomalyEvent
+	anomalous := math.Abs(score) >= ad.cfg.Threshold
+	if anomalous != b.Anomalous {
+		b.Anomalous = anomalous
+		ev = &AnomalyEvent{
+			Time:         now,
+			Metric:       metricName,
+			SourceRegion: source,
+			Target:       target,
+			Value:        value,
+			Expected:     expected,
+			Score:        score,
+			Resolved:     !anomalous,
+		}
+		ad.recent = append(ad.recent, ev)
+		if len(ad.recent) > maxRecentAnomalies {
+			ad.recent = ad.recent[len(ad.recent)-maxRecentAnomalies:]
+		}
+	}
+	ad.mu.Unlock()
+
+	AnomalyScoreGaugeVec.WithLabelValues(metricName, source, target).Set(score)
+	if ev == nil {
+		return
+	}
+	if ev.Resolved {
+		level.Info(ad.logger).Log("msg", "anomaly resolved", "metric", metricName, "source_region", source, "target", target, "value", value, "expected", expected)
+		return
+	}
+	AnomalyEventsCounterVec.WithLabelValues(metricName).Inc()
+	level.Warn(ad.logger).Log("msg", "anomaly detected", "metric", metricName, "source_region", source, "target", target, "value", value, "expected", expected, "score", score)
+}
+
+// expected prefers the hour of day baseline once it has learned enough
+func (ad *AnomalyDetector) expected(b *baseline, hour *ewmaStat) (mean, std float64, ready bool) {
+	s := &b.Global
+	if hour.N >= ad.cfg.MinSamples {
+		s = hour
+	}
+	if s.N < ad.cfg.MinSamples {
+		return s.Mean, 0, false
+	}
+	std = math.Sqrt(s.Var)
+	// stable series like a 1ms dedicated line would otherwise score huge on
+	// any jitter, never trust a deviation below 5% of the mean
+	if floor := math.Max(0.05*math.Abs(s.Mean), 1e-3); std < floor {
+		std = floor
+	}
+	return s.Mean, std, true
+}
+
+// RecentAnomalies returns the latest anomaly events, oldest first
+func (ad *AnomalyDetector) RecentAnomalies() []*AnomalyEvent {
+	if ad == nil {
+		return nil
+	}
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	res := make([]*AnomalyEvent, len(ad.recent))
+	copy(res, ad.recent)
+	return res
+}
+
+// Run persists the learned baselines so a restart does not forget history
+func (ad *AnomalyDetector) Run(ctx context.Context) error {
+	if ad == nil {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(anomalySaveInterval)
+	level.Info(ad.logger).Log("msg", "AnomalyDetector start....", "state_file", ad.cfg.StateFile)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ad.save()
+		case <-ctx.Done():
+			ad.save()
+			level.Info(ad.logger).Log("msg", "AnomalyDetector exit....")
+			return nil
+		}
+	}
+}
+
+func (ad *AnomalyDetector) load() {
+	if ad.cfg.StateFile == "" {
+		return
+	}
+	content, err := ioutil.ReadFile(ad.cfg.StateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			level.Warn(ad.logger).Log("msg", "read anomaly state file error", "err", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(content, &ad.baselines); err != nil {
+		level.Warn(ad.logger).Log("msg", "parse anomaly state file error", "err", err)
+		ad.baselines = make(map[string]*baseline)
+		return
+	}
+	level.Info(ad.logger).Log("msg", "anomaly baselines loaded", "series", len(ad.baselines))
+}
+
+func (ad *AnomalyDetector) save() {
+	if ad.cfg.StateFile == "" {
+		return
+	}
+	ad.mu.Lock()
+	content, err := json.Marshal(ad.baselines)
+	ad.mu.Unlock()
+	if err != nil {
+		level.Error(ad.logger).Log("msg", "marshal anomaly baselines error", "err", err)
+		return
+	}
+	if err := writeFileAtomic(ad.cfg.StateFile, content); err != nil {
+		level.Error(ad.logger).Log("msg", "write anomaly state file error", "err", err)
+	}
+}
+
+// writeFileAtomic writes to a temp file first so a crash never leaves a torn file
+func writeFileAtomic(filename string, content []byte) error {
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+/*
+   json query api on the metrics listener, computed from the live
+   IcmpDataMap and HttpDataMap so no promql is needed
+*/
+
+var icmpMatrixMetrics = []string{
+	common.MetricsNamePingLatency,
+	common.MetricsNamePingPackageDrop,
+	common.MetricsNamePingTargetSuccess,
+}
+
+type cellBuilder struct {
+	cell   *api.MatrixCell
+	values []float64
+	agents map[string]bool
+}
+
+func (cb *cellBuilder) add(prr *pb.ProberResultOne) {
+	cb.values = append(cb.values, float64(prr.Value))
+	cb.agents[prr.WorkerName] = true
+	if prr.TimeStamp > cb.cell.TimeStamp {
+		cb.cell.TimeStamp = prr.TimeStamp
+	}
+}
+
+// finish computes the value the same way DataProcess does for prometheus
+func (cb *cellBuilder) finish(metricName string, withAgents bool) *api.MatrixCell {
+	c := cb.cell
+	c.SampleNum = len(cb.values)
+	c.AgentNum = len(cb.agents)
+	if metricName == common.MetricsNamePingTargetSuccess {
+		for _, v := range cb.values {
+			if v != -1 {
+				c.Value = 1
+				break
+			}
+		}
+	} else {
+		var sum float64
+		for _, v := range cb.values {
+			sum += v
+		}
+		c.Value = sum / float64(len(cb.values))
+	}
+	if withAgents {
+		for a := range cb.agents {
+			c.Agents = append(c.Agents, a)
+		}
+		sort.Strings(c.Agents)
+	}
+	return c
+}
+
+func newCellBuilder(c *api.MatrixCell) *cellBuilder {
+	return &cellBuilder{cell: c, agents: make(map[string]bool)}
+}
+
+// freshResults returns the not expired results of a data map
+func freshResults(m *sync.Map) []*pb.ProberResultOne {
+	var res []*pb.ProberResultOne
+	now := time.Now().Unix()
+	m.Range(func(k, v interface{}) bool {
+		va := v.(*pb.ProberResultOne)
+		if now-va.TimeStamp <= ResultExpireSeconds {
+			res = append(res, va)
+		}
+		return true
+	})
+	return res
+}
+
+// BuildIcmpMatrix returns the region by region matrix of one ping metric
+func BuildIcmpMatrix(metricName string, withAgents bool) *api.MatrixResponse {
+	builders := make(map[string]map[string]*cellBuilder)
+	regionSet := make(map[string]bool)
+	for _, prr := range freshResults(&IcmpDataMap) {
+		if prr.MetricName != metricName {
+			continue
+		}
+		regionSet[prr.SourceRegion] = true
+		regionSet[prr.TargetRegion] = true
+		row, ok := builders[prr.SourceRegion]
+		if !ok {
+			row = make(map[string]*cellBuilder)
+			builders[prr.SourceRegion] = row
+		}
+		cb, ok := row[prr.TargetRegion]
+		if !ok {
+			cb = newCellBuilder(&api.MatrixCell{SourceRegion: prr.SourceRegion, TargetRegion: prr.TargetRegion})
+			row[prr.TargetRegion] = cb
+		}
+		cb.add(prr)
+	}
+
+	resp := &api.MatrixResponse{Metric: metricName, Regions: []string{}, Matrix: make(map[string]map[string]*api.MatrixCell)}
+	for r := range regionSet {
+		resp.Regions = append(resp.Regions, r)
+	}
+	sort.Strings(resp.Regions)
+	for src, row := range builders {
+		resp.Matrix[src] = make(map[string]*api.MatrixCell)
+		for dst, cb := range row {
+			resp.Matrix[src][dst] = cb.finish(metricName, withAgents)
+		}
+	}
+	return resp
+}
+
+// BuildHttpTargets returns every http addr with all stage metrics per source region
+func BuildHttpTargets(sourceRegion string, withAgents bool) []*api.HttpTargetResponse {
+	builders := make(map[string]map[string]*cellBuilder)
+	for _, prr := range freshResults(&HttpDataMap) {
+		if sourceRegion != "" && prr.SourceRegion != sourceRegion {
+			continue
+		}
+		key := prr.SourceRegion + MetricUniqueSeparator + prr.TargetAddr
+		row, ok := builders[key]
+		if !ok {
+			row = make(map[string]*cellBuilder)
+			builders[key] = row
+		}
+		cb, ok := row[prr.MetricName]
+		if !ok {
+			cb = newCellBuilder(&api.MatrixCell{SourceRegion: prr.SourceRegion, Addr: prr.TargetAddr})
+			row[prr.MetricName] = cb
+		}
+		cb.add(prr)
+	}
+
+	res := make([]*api.HttpTargetResponse, 0, len(builders))
+	for key, row := range builders {
+		parts := strings.SplitN(key, MetricUniqueSeparator, 2)
+		ht := &api.HttpTargetResponse{SourceRegion: parts[0], Addr: parts[1], Metrics: make(map[string]*api.MatrixCell)}
+		for metricName, cb := range row {
+			ht.Metrics[metricName] = cb.finish(metricName, withAgents)
+		}
+		res = append(res, ht)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].SourceRegion != res[j].SourceRegion {
+			return res[i].SourceRegion < res[j].SourceRegion
+		}
+		return res[i].Addr < res[j].Addr
+	})
+	return res
+}
+
+func writeJson(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeJsonError(w http.ResponseWriter, code int, msg string) {
+	writeJson(w, code, map[string]string{"status": "error", "error": msg})
+}
+
+func isValidIcmpMatrixMetric(metricName string) bool {
+	for _, m := range icmpMatrixMetrics {
+		if m == metricName {
+			return true
+		}
+	}
+	return false
+}
+
+// matrixHandler serves /api/v1/matrix?metric=xxx&agents=true, without metric
+// all ping metrics are returned
+func matrixHandler(w http.ResponseWriter, r *http.Request) {
+	withAgents := r.URL.Query().Get("agents") == "true"
+	metricName := r.URL.Query().Get("metric")
+	if metricName != "" {
+		if !isValidIcmpMatrixMetric(metricName) {
+			writeJsonError(w, http.StatusBadRequest, "unknown metric: "+metricName)
+			return
+		}
+		writeJson(w, http.StatusOK, BuildIcmpMatrix(metricName, withAgents))
+		return
+	}
+	res := make([]*api.MatrixResponse, 0, len(icmpMatrixMetrics))
+	for _, m := range icmpMatrixMetrics {
+		res = append(res, BuildIcmpMatrix(m, withAgents))
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+// httpTargetsHandler serves /api/v1/http?source_region=xxx&agents=true
+func httpTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	withAgents := r.URL.Query().Get("agents") == "true"
+	writeJson(w, http.StatusOK, BuildHttpTargets(r.URL.Query().Get("source_region"), withAgents))
+}
+
+func RegisterApiHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/matrix", matrixHandler)
+	mux.HandleFunc("/api/v1/http", httpTargetsHandler)
+	mux.HandleFunc("/api/v1/samples", samplesHandler)
+	mux.HandleFunc("/api/v1/agents", agentsHandler)
+	mux.HandleFunc("/api/v1/targets", targetsHandler)
+	mux.HandleFunc("/api/v1/grafana/dashboard", grafanaDashboardHandler)
+	mux.HandleFunc("/api/v1/query_range", queryRangeHandler)
+	mux.HandleFunc("/api/v1/reports/sla", slaReportHandler)
+	mux.HandleFunc("/api/v1/slos", sloHandler)
+	mux.HandleFunc("/api/v1/cluster", clusterHandler)
+	mux.HandleFunc("/-/healthy", healthyHandler)
+	mux.HandleFunc("/-/ready", readyHandler)
+	mux.HandleFunc("/ui/", uiHandler)
+	mux.HandleFunc("/", rootHandler)
+}
+
+// samplesHandler serves /api/v1/samples?source_region=a&target_region=b&metric=xxx,
+// the raw per agent results behind one matrix cell
+func samplesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	source, target, addr, metricName := q.Get("source_region"), q.Get("target_region"), q.Get("addr"), q.Get("metric")
+	if source == "" || (target == "" && addr == "") {
+		writeJsonError(w, http.StatusBadRequest, "source_region and target_region or addr are required")
+		return
+	}
+	dm := &IcmpDataMap
+	if addr != "" {
+		dm = &HttpDataMap
+	}
+	res := make([]*pb.ProberResultOne, 0)
+	for _, prr := range freshResults(dm) {
+		if prr.SourceRegion != source || (metricName != "" && prr.MetricName != metricName) {
+			continue
+		}
+		if (addr != "" && prr.TargetAddr != addr) || (addr == "" && prr.TargetRegion != target) {
+			continue
+		}
+		res = append(res, prr)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].MetricName != res[j].MetricName {
+			return res[i].MetricName < res[j].MetricName
+		}
+		if res[i].WorkerName != res[j].WorkerName {
+			return res[i].WorkerName < res[j].WorkerName
+		}
+		return res[i].TargetAddr < res[j].TargetAddr
+	})
+	writeJson(w, http.StatusOK, res)
+}
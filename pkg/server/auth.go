@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+	"xprober/pkg/tlsutil"
+)
+
+/*
+   agent rpc authentication. every call to an agent service must carry a
+   configured token or come over mtls with a configured certificate identity,
+   and may only claim the regions allowed for that credential. admin rpcs
+   keep their own token check
+*/
+
+const (
+	rejectUnauthenticated = `unauthenticated`
+	rejectRegion          = `region_not_allowed`
+
+	anyRegion = `*`
+)
+
+var (
+	AgentA *AgentAuth
+
+	// services called by agents, everything else is left alone
+	agentRpcServices = map[string]bool{
+		"pb.GetProberTarget":     true,
+		"pb.PushProberResult":    true,
+		"pb.ProberAgentIpReport": true,
+		"pb.ProberAdhoc":         true,
+	}
+
+	RpcRejectedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameRpcRejected,
+		Help: "agent rpc calls rejected by authentication or region authorization",
+	}, []string{"method", "reason", "credential"})
+)
+
+type AgentAuth struct {
+	logger      log.Logger
+	credentials []*AgentCredential
+}
+
+func NewAgentAuth(logger log.Logger, cfg *AgentAuthConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if len(cfg.Credentials) == 0 {
+		return fmt.Errorf("agent auth needs at least one credential")
+	}
+	for _, c := range cfg.Credentials {
+		if c.Name == "" {
+			return fmt.Errorf("agent credential needs a name")
+		}
+		if c.Token == "" && c.CertIdentity == "" {
+			return fmt.Errorf("agent credential %s needs a token or cert_identity", c.Name)
+		}
+		if len(c.Regions) == 0 {
+			return fmt.Errorf("agent credential %s allows no region", c.Name)
+		}
+	}
+	AgentA = &AgentAuth{logger: log.With(logger, "component", "agent_auth"), credentials: cfg.Credentials}
+	level.Info(AgentA.logger).Log("msg", "agent rpc authentication enabled", "credentials", len(cfg.Credentials))
+	return nil
+}
+
+// authenticate returns the first credential whose token and cert identity,
+// when set, both match the call
+func (aa *AgentAuth) authenticate(ctx context.Context) *AgentCredential {
+	var tokens []string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if t := bearerToken(v); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	names := tlsutil.PeerNames(ctx)
+	for _, c := range aa.credentials {
+		if c.Token != "" && !tokenIn(c.Token, tokens) {
+			continue
+		}
+		if c.CertIdentity != "" && !stringIn(c.CertIdentity, names) {
+			continue
+		}
+		return c
+	}
+	return nil
+}
+
+func tokenIn(token string, tokens []string) bool {
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func stringIn(s string, ss []string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *AgentCredential) allowsRegion(region string) bool {
+	return stringIn(anyRegion, c.Regions) || stringIn(region, c.Regions)
+}
+
+// claimedRegions returns the regions an agent request speaks for
+func claimedRegions(req interface{}) []string {
+	switch r := req.(type) {
+	case *pb.ProberTargetsGetRequest:
+		return []string{r.LocalRegion}
+	case *pb.ProberAgentIpReportRequest:
+		return []string{r.Region}
+	case *pb.ProberAgentDeregisterRequest:
+		return []string{r.Region}
+	case *pb.ProberResultPushRequest:
+		var regions []string
+		for _, prr := range r.ProberResults {
+			regions = append(regions, prr.SourceRegion)
+		}
+		return regions
+	case *pb.AdhocAgentMessage:
+		return []string{r.Region}
+	}
+	return nil
+}
+
+func (aa *AgentAuth) reject(ctx context.Context, method, reason string, c *AgentCredential, region string) error {
+	name := ""
+	if c != nil {
+		name = c.Name
+	}
+	RpcRejectedCounterVec.WithLabelValues(method, reason, name).Inc()
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	level.Warn(aa.logger).Log("msg", "agent rpc rejected", "method", method, "reason", reason, "credential", name, "region", region, "peer", addr)
+	if reason == rejectUnauthenticated {
+		return status.Error(codes.Unauthenticated, "invalid agent credential")
+	}
+	return status.Errorf(codes.PermissionDenied, "credential %s may not claim region %q", name, region)
+}
+
+func (aa *AgentAuth) authorize(ctx context.Context, method string, c *AgentCredential, req interface{}) error {
+	for _, region := range claimedRegions(req) {
+		if !c.allowsRegion(region) {
+			return aa.reject(ctx, method, rejectRegion, c, region)
+		}
+	}
+	return nil
+}
+
+func isAgentRpc(fullMethod string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	return agentRpcServices[parts[0]]
+}
+
+func agentAuthUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if AgentA == nil || !isAgentRpc(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	c := AgentA.authenticate(ctx)
+	if c == nil {
+		return nil, AgentA.reject(ctx, info.FullMethod, rejectUnauthenticated, nil, "")
+	}
+	if err := AgentA.authorize(ctx, info.FullMethod, c, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func agentAuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if AgentA == nil || !isAgentRpc(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	c := AgentA.authenticate(ss.Context())
+	if c == nil {
+		return AgentA.reject(ss.Context(), info.FullMethod, rejectUnauthenticated, nil, "")
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, method: info.FullMethod, credential: c})
+}
+
+// authorizedStream checks the regions claimed by every received message
+type authorizedStream struct {
+	grpc.ServerStream
+	method     string
+	credential *AgentCredential
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return AgentA.authorize(s.Context(), s.method, s.credential, m)
+}
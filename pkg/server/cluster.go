@@ -0,0 +1,654 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+	"xprober/pkg/tlsutil"
+)
+
+/*
+   server replicas form a cluster by push-pull syncing their state with every
+   peer over the rpc port: members, the agent registry and the admin target
+   groups, newest copy wins. results pushed by agents are forwarded to all
+   peers so every replica aggregates the same data and serves the same
+   /metrics. the live member with the lowest name is the leader, only it
+   sends alert notifications and writes aggregates to the output sinks
+*/
+
+const (
+	defaultClusterSyncInterval = 5 * time.Second
+	clusterForwardInterval     = time.Second
+	clusterRpcTimeout          = 5 * time.Second
+	// results waiting to be forwarded, the oldest are dropped beyond it
+	clusterForwardQueueSize = 20000
+	// members learned from peers are forgotten after being dead this long
+	clusterMemberForgetAfter = 10 * time.Minute
+	clusterTombstoneTTL      = 24 * time.Hour
+)
+
+var (
+	ClusterC *Cluster
+
+	ClusterPeerUpGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameClusterPeerUp,
+		Help: "1 when the last sync with the cluster peer succeeded",
+	}, []string{"peer"})
+	ClusterLeaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: common.MetricsNameClusterLeader,
+		Help: "1 when this replica is the cluster leader",
+	})
+	ClusterForwardedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameClusterForwarded,
+		Help: "results forwarded to the cluster peer",
+	}, []string{"peer"})
+	ClusterForwardDroppedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameClusterForwardDropped,
+		Help: "results never forwarded to a peer because a forward queue was full",
+	})
+)
+
+type clusterPeer struct {
+	addr     string
+	name     string
+	static   bool
+	conn     *grpc.ClientConn
+	lastSeen time.Time
+	lastErr  string
+	// results not yet forwarded to the peer, guarded by Cluster.queueMux
+	pending []*pb.ProberResultOne
+}
+
+// ClusterMemberStatus is one member as seen by this replica
+type ClusterMemberStatus struct {
+	Name     string `json:"name"`
+	Addr     string `json:"addr"`
+	Self     bool   `json:"self"`
+	Alive    bool   `json:"alive"`
+	Leader   bool   `json:"leader"`
+	LastSeen int64  `json:"last_seen"`
+	LastErr  string `json:"last_error,omitempty"`
+}
+
+type Cluster struct {
+	logger log.Logger
+	self   pb.ClusterMember
+	secret string
+	// client certificate names peers may present
+	identities []string
+	interval   time.Duration
+	dialOpts   []grpc.DialOption
+
+	mux   sync.Mutex
+	peers map[string]*clusterPeer
+
+	queueMux sync.Mutex
+	queue    []*pb.ProberResultOne
+}
+
+// NewCluster needs rpcTls to verify client certificates when peers
+// authenticate with cert_identities
+func NewCluster(logger log.Logger, cfg *ClusterConfig, rpcTls *RpcTlsConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.AdvertiseAddr == "" {
+		return fmt.Errorf("cluster needs an advertise_addr")
+	}
+	// the cluster rpcs write agents, target groups and results
+	if cfg.Secret == "" && len(cfg.CertIdentities) == 0 {
+		return fmt.Errorf("cluster needs a secret or cert_identities")
+	}
+	if len(cfg.CertIdentities) > 0 {
+		if rpcTls == nil || rpcTls.CAFile == "" || rpcTls.ClientAuth == "none" {
+			return fmt.Errorf("cluster cert_identities need rpc_tls with a ca_file verifying client certificates")
+		}
+		if cfg.Tls == nil || cfg.Tls.CertFile == "" {
+			return fmt.Errorf("cluster cert_identities need a client certificate in cluster.tls")
+		}
+	}
+	c := &Cluster{
+		logger:     log.With(logger, "component", "cluster"),
+		self:       pb.ClusterMember{Name: cfg.Name, Addr: cfg.AdvertiseAddr},
+		secret:     cfg.Secret,
+		identities: cfg.CertIdentities,
+		interval:   time.Duration(cfg.SyncInterval),
+		peers:      make(map[string]*clusterPeer),
+	}
+	if c.self.Name == "" {
+		c.self.Name = cfg.AdvertiseAddr
+	}
+	if c.interval <= 0 {
+		c.interval = defaultClusterSyncInterval
+	}
+	if cfg.Tls != nil {
+		r, err := tlsutil.NewReloader(logger, tlsutil.Files{CAFile: cfg.Tls.CAFile, CertFile: cfg.Tls.CertFile, KeyFile: cfg.Tls.KeyFile})
+		if err != nil {
+			return err
+		}
+		c.dialOpts = append(c.dialOpts, grpc.WithTransportCredentials(r.ClientCredentials(cfg.Tls.ServerName)))
+	} else {
+		c.dialOpts = append(c.dialOpts, grpc.WithInsecure())
+	}
+	if c.secret != "" {
+		c.dialOpts = append(c.dialOpts, grpc.WithPerRPCCredentials(clusterSecret(c.secret)))
+	}
+	for _, addr := range cfg.Peers {
+		if err := c.addPeer(addr, true); err != nil {
+			return err
+		}
+	}
+	ClusterC = c
+	level.Info(c.logger).Log("msg", "cluster enabled", "name", c.self.Name, "addr", c.self.Addr, "peers", len(c.peers))
+	return nil
+}
+
+// clusterSecret authenticates a replica to its peers
+type clusterSecret string
+
+func (s clusterSecret) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(s)}, nil
+}
+
+func (s clusterSecret) RequireTransportSecurity() bool {
+	return false
+}
+
+// addPeer must be called with mux held unless during init
+func (c *Cluster) addPeer(addr string, static bool) error {
+	if addr == c.self.Addr {
+		return nil
+	}
+	if _, ok := c.peers[addr]; ok {
+		return nil
+	}
+	conn, err := grpc.Dial(addr, c.dialOpts...)
+	if err != nil {
+		return fmt.Errorf("dial cluster peer %s: %v", addr, err)
+	}
+	c.peers[addr] = &clusterPeer{addr: addr, static: static, conn: conn}
+	return nil
+}
+
+func (c *Cluster) alive(p *clusterPeer, now time.Time) bool {
+	return !p.lastSeen.IsZero() && now.Sub(p.lastSeen) <= 3*c.interval
+}
+
+// IsLeader reports whether this replica does the cluster wide side effects,
+// always true without a cluster
+func (c *Cluster) IsLeader() bool {
+	if c == nil {
+		return true
+	}
+	now := time.Now()
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, p := range c.peers {
+		if p.name != "" && c.alive(p, now) && p.name < c.self.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// state returns what this replica knows to send to a peer
+func (c *Cluster) state() *pb.ClusterState {
+	st := &pb.ClusterState{Self: &pb.ClusterMember{Name: c.self.Name, Addr: c.self.Addr}}
+	now := time.Now()
+	c.mux.Lock()
+	for _, p := range c.peers {
+		if p.static || c.alive(p, now) {
+			st.Members = append(st.Members, &pb.ClusterMember{Name: p.name, Addr: p.addr})
+		}
+	}
+	c.mux.Unlock()
+	for _, a := range ListAgents() {
+		st.Agents = append(st.Agents, &pb.ClusterAgent{
+			Report:         agentReport(a),
+			LastReport:     a.LastReport,
+			LastTargetsGet: a.LastTargetsGet,
+			LastPush:       a.LastPush,
+			CertIdentity:   a.CertIdentity,
+			Deregistered:   a.Deregistered,
+		})
+	}
+	st.TargetGroups = AdminS.clusterGroups()
+	return st
+}
+
+// merge applies the state of a peer, sender is the peer that was called or
+// that called us
+func (c *Cluster) merge(st *pb.ClusterState) {
+	if st.Self != nil && st.Self.Name == c.self.Name && st.Self.Addr != c.self.Addr {
+		level.Warn(c.logger).Log("msg", "cluster peer uses our name", "peer", st.Self.Addr, "name", st.Self.Name)
+	}
+	c.mux.Lock()
+	if st.Self != nil {
+		if err := c.addPeer(st.Self.Addr, false); err != nil {
+			level.Error(c.logger).Log("msg", "add cluster peer error", "err", err)
+		}
+		if p, ok := c.peers[st.Self.Addr]; ok {
+			p.name = st.Self.Name
+			p.lastSeen = time.Now()
+			p.lastErr = ""
+		}
+	}
+	for _, m := range st.Members {
+		if _, ok := c.peers[m.Addr]; !ok && m.Addr != c.self.Addr {
+			level.Info(c.logger).Log("msg", "cluster member learned", "member", m.Addr, "from", st.Self.GetAddr())
+			if err := c.addPeer(m.Addr, false); err != nil {
+				level.Error(c.logger).Log("msg", "add cluster peer error", "err", err)
+			}
+		}
+	}
+	c.mux.Unlock()
+	for _, ca := range st.Agents {
+		mergeClusterAgent(ca)
+	}
+	AdminS.mergeClusterGroups(st.TargetGroups)
+}
+
+// mergeClusterAgent keeps the newest report and the latest activity times
+func mergeClusterAgent(ca *pb.ClusterAgent) {
+	if ca.Report == nil {
+		return
+	}
+	forget := false
+	touchAgent(ca.Report.Ip, func(a *api.AgentInfo) {
+		if ca.LastReport > a.LastReport {
+			a.LastReport = ca.LastReport
+			a.CertIdentity = ca.CertIdentity
+			applyAgentReport(a, ca.Report)
+			if a.Region != "" && !agentGone(a) {
+				AgentIpRegionMap.Store(a.Ip, a.Region)
+			}
+		}
+		if ca.Deregistered > a.Deregistered {
+			a.Deregistered = ca.Deregistered
+			// deregistered on a peer
+			forget = agentGone(a)
+		}
+		if ca.LastTargetsGet > a.LastTargetsGet {
+			a.LastTargetsGet = ca.LastTargetsGet
+		}
+		if ca.LastPush > a.LastPush {
+			a.LastPush = ca.LastPush
+		}
+	})
+	if forget {
+		forgetAgent(ca.Report.Ip)
+	}
+}
+
+func (as *AdminStore) clusterGroups() []*pb.ClusterTargetGroup {
+	if as == nil {
+		return nil
+	}
+	as.mux.Lock()
+	defer as.mux.Unlock()
+	var res []*pb.ClusterTargetGroup
+	for _, g := range as.groups {
+		res = append(res, &pb.ClusterTargetGroup{Group: g})
+	}
+	expire := time.Now().Add(-clusterTombstoneTTL).UnixNano()
+	for name, t := range as.deleted {
+		if t < expire {
+			delete(as.deleted, name)
+			continue
+		}
+		res = append(res, &pb.ClusterTargetGroup{Group: &pb.TargetGroup{Name: name, UpdatedAt: t}, Deleted: true})
+	}
+	return res
+}
+
+// mergeClusterGroups applies groups and deletes newer than the local copy
+func (as *AdminStore) mergeClusterGroups(cgs []*pb.ClusterTargetGroup) {
+	if as == nil || len(cgs) == 0 {
+		return
+	}
+	changed := false
+	err := as.update(func() error {
+		for _, cg := range cgs {
+			g := cg.Group
+			if g == nil || g.Name == "" {
+				continue
+			}
+			local := int64(-1)
+			if lg, ok := as.groups[g.Name]; ok {
+				local = lg.UpdatedAt
+			}
+			if t, ok := as.deleted[g.Name]; ok && t > local {
+				local = t
+			}
+			if g.UpdatedAt <= local {
+				continue
+			}
+			if cg.Deleted {
+				changed = true
+				delete(as.groups, g.Name)
+				as.deleted[g.Name] = g.UpdatedAt
+				level.Info(as.logger).Log("msg", "target group deleted by cluster peer", "name", g.Name)
+				continue
+			}
+			// peers are checked like the admin api
+			if err := validateTargetGroup(g); err != nil {
+				level.Warn(as.logger).Log("msg", "drop invalid target group from cluster peer", "name", g.Name, "err", err)
+				continue
+			}
+			changed = true
+			as.groups[g.Name] = g
+			delete(as.deleted, g.Name)
+			level.Info(as.logger).Log("msg", "target group updated by cluster peer", "name", g.Name)
+		}
+		if !changed {
+			return errNothingChanged
+		}
+		return nil
+	})
+	if err != nil && err != errNothingChanged {
+		level.Error(as.logger).Log("msg", "merge cluster target groups error", "err", err)
+	}
+}
+
+var errNothingChanged = fmt.Errorf("nothing changed")
+
+// Forward queues results pushed by an agent for the peers
+func (c *Cluster) Forward(results []*pb.ProberResultOne) {
+	if c == nil || len(results) == 0 {
+		return
+	}
+	c.queueMux.Lock()
+	defer c.queueMux.Unlock()
+	c.queue = append(c.queue, results...)
+	if over := len(c.queue) - clusterForwardQueueSize; over > 0 {
+		ClusterForwardDroppedCounter.Add(float64(over))
+		c.queue = c.queue[over:]
+	}
+}
+
+// requeue keeps results a peer did not take for the next flush, the oldest
+// are dropped beyond the queue limit
+func (c *Cluster) requeue(p *clusterPeer, results []*pb.ProberResultOne) {
+	c.queueMux.Lock()
+	defer c.queueMux.Unlock()
+	if over := len(results) - clusterForwardQueueSize; over > 0 {
+		ClusterForwardDroppedCounter.Add(float64(over))
+		results = results[over:]
+	}
+	p.pending = results
+}
+
+// flush sends every peer its pending results plus the newly queued ones,
+// peers that are down or fail keep them for the next flush
+func (c *Cluster) flush() {
+	c.queueMux.Lock()
+	results := c.queue
+	c.queue = nil
+	c.queueMux.Unlock()
+
+	now := time.Now()
+	c.mux.Lock()
+	peers := make([]*clusterPeer, 0, len(c.peers))
+	alive := make(map[*clusterPeer]bool, len(c.peers))
+	for _, p := range c.peers {
+		peers = append(peers, p)
+		alive[p] = c.alive(p, now)
+	}
+	c.mux.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		c.queueMux.Lock()
+		batch := append(p.pending[:len(p.pending):len(p.pending)], results...)
+		p.pending = nil
+		c.queueMux.Unlock()
+		if len(batch) == 0 {
+			continue
+		}
+		if !alive[p] {
+			c.requeue(p, batch)
+			continue
+		}
+		wg.Add(1)
+		go func(p *clusterPeer, batch []*pb.ProberResultOne) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), clusterRpcTimeout)
+			defer cancel()
+			req := &pb.ClusterForwardRequest{From: c.self.Addr, ProberResults: batch}
+			if _, err := pb.NewProberClusterClient(p.conn).ForwardResults(ctx, req); err != nil {
+				level.Warn(c.logger).Log("msg", "forward results error, retry on next flush", "peer", p.addr, "results", len(batch), "err", err)
+				c.requeue(p, batch)
+				return
+			}
+			ClusterForwardedCounterVec.WithLabelValues(p.addr).Add(float64(len(batch)))
+		}(p, batch)
+	}
+	wg.Wait()
+}
+
+// sync exchanges state with every peer and drops long dead learned members
+func (c *Cluster) sync() {
+	st := c.state()
+	c.mux.Lock()
+	peers := make([]*clusterPeer, 0, len(c.peers))
+	for _, p := range c.peers {
+		peers = append(peers, p)
+	}
+	c.mux.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p *clusterPeer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), clusterRpcTimeout)
+			defer cancel()
+			resp, err := pb.NewProberClusterClient(p.conn).ClusterSync(ctx, st)
+			if err != nil {
+				c.mux.Lock()
+				p.lastErr = err.Error()
+				c.mux.Unlock()
+				level.Debug(c.logger).Log("msg", "cluster sync error", "peer", p.addr, "err", err)
+				return
+			}
+			c.merge(resp)
+		}(p)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	c.mux.Lock()
+	for addr, p := range c.peers {
+		up := c.alive(p, now)
+		if !p.static && !up && now.Sub(p.lastSeen) > clusterMemberForgetAfter {
+			level.Info(c.logger).Log("msg", "cluster member forgotten", "member", addr)
+			c.queueMux.Lock()
+			ClusterForwardDroppedCounter.Add(float64(len(p.pending)))
+			p.pending = nil
+			c.queueMux.Unlock()
+			p.conn.Close()
+			delete(c.peers, addr)
+			ClusterPeerUpGaugeVec.DeleteLabelValues(addr)
+			continue
+		}
+		ClusterPeerUpGaugeVec.WithLabelValues(addr).Set(boolGauge(up))
+	}
+	c.mux.Unlock()
+	ClusterLeaderGauge.Set(boolGauge(c.IsLeader()))
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (c *Cluster) Run(ctx context.Context) error {
+	if c == nil {
+		<-ctx.Done()
+		return nil
+	}
+	syncTicker := time.NewTicker(c.interval)
+	forwardTicker := time.NewTicker(clusterForwardInterval)
+	level.Info(c.logger).Log("msg", "Cluster start....")
+	defer syncTicker.Stop()
+	defer forwardTicker.Stop()
+	c.sync()
+	for {
+		select {
+		case <-syncTicker.C:
+			c.sync()
+		case <-forwardTicker.C:
+			c.flush()
+		case <-ctx.Done():
+			c.mux.Lock()
+			for _, p := range c.peers {
+				p.conn.Close()
+			}
+			c.mux.Unlock()
+			level.Info(c.logger).Log("msg", "Cluster exit....")
+			return nil
+		}
+	}
+}
+
+// Members returns this replica and its peers
+func (c *Cluster) Members() []*ClusterMemberStatus {
+	if c == nil {
+		return nil
+	}
+	leader := c.IsLeader()
+	now := time.Now()
+	res := []*ClusterMemberStatus{{Name: c.self.Name, Addr: c.self.Addr, Self: true, Alive: true, Leader: leader, LastSeen: now.Unix()}}
+	c.mux.Lock()
+	for _, p := range c.peers {
+		m := &ClusterMemberStatus{Name: p.name, Addr: p.addr, Alive: c.alive(p, now), LastErr: p.lastErr}
+		if !p.lastSeen.IsZero() {
+			m.LastSeen = p.lastSeen.Unix()
+		}
+		res = append(res, m)
+	}
+	c.mux.Unlock()
+	if !leader {
+		lowest := -1
+		for i, m := range res {
+			if m.Alive && m.Name != "" && (lowest < 0 || m.Name < res[lowest].Name) {
+				lowest = i
+			}
+		}
+		if lowest >= 0 {
+			res[lowest].Leader = true
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Addr < res[j].Addr })
+	return res
+}
+
+// checkSecret lets only peers through, every configured factor must match
+func (c *Cluster) checkSecret(ctx context.Context) error {
+	if c.secret == "" && len(c.identities) == 0 {
+		return status.Error(codes.Unauthenticated, "cluster authentication is not configured")
+	}
+	if c.secret != "" {
+		ok := false
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if tokenIn(c.secret, []string{bearerToken(v)}) {
+				ok = true
+			}
+		}
+		if !ok {
+			return status.Error(codes.Unauthenticated, "invalid cluster secret")
+		}
+	}
+	if len(c.identities) > 0 {
+		ok := false
+		for _, n := range tlsutil.PeerNames(ctx) {
+			if stringIn(n, c.identities) {
+				ok = true
+			}
+		}
+		if !ok {
+			return status.Error(codes.Unauthenticated, "client certificate is not a cluster peer")
+		}
+	}
+	return nil
+}
+
+// storeResult keeps the newest result per uid, replayed results of an
+// agent spool or of a replica may be older than the stored one
+func storeResult(prr *pb.ProberResultOne) bool {
+	var dm *sync.Map
+	switch prr.ProbeType {
+	case `icmp`:
+		dm = &IcmpDataMap
+	case `http`:
+		dm = &HttpDataMap
+	default:
+		return false
+	}
+	uid := GetProbeResultUid(prr)
+	if v, ok := dm.Load(uid); ok && v.(*pb.ProberResultOne).TimeStamp > prr.TimeStamp {
+		return false
+	}
+	dm.Store(uid, prr)
+	return true
+}
+
+type PCluster struct {
+	pb.UnimplementedProberClusterServer
+	logger log.Logger
+}
+
+func (pc *PCluster) ClusterSync(ctx context.Context, in *pb.ClusterState) (*pb.ClusterState, error) {
+	if ClusterC == nil {
+		return nil, status.Error(codes.Unimplemented, "cluster is disabled")
+	}
+	if err := ClusterC.checkSecret(ctx); err != nil {
+		return nil, err
+	}
+	ClusterC.merge(in)
+	return ClusterC.state(), nil
+}
+
+func (pc *PCluster) ForwardResults(ctx context.Context, in *pb.ClusterForwardRequest) (*pb.ClusterForwardResponse, error) {
+	if ClusterC == nil {
+		return nil, status.Error(codes.Unimplemented, "cluster is disabled")
+	}
+	if err := ClusterC.checkSecret(ctx); err != nil {
+		return nil, err
+	}
+	var stored []*pb.ProberResultOne
+	for _, prr := range IngestV.filterForwarded(in.ProberResults) {
+		if storeResult(prr) {
+			stored = append(stored, prr)
+		}
+	}
+	touchNewestResult(stored)
+	return &pb.ClusterForwardResponse{SuccessNum: int32(len(stored))}, nil
+}
+
+// clusterHandler serves /api/v1/cluster with the members seen by this replica
+func clusterHandler(w http.ResponseWriter, r *http.Request) {
+	if ClusterC == nil {
+		writeJsonError(w, http.StatusNotFound, "cluster is disabled")
+		return
+	}
+	writeJson(w, http.StatusOK, ClusterC.Members())
+}
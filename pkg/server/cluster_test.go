@@ -0,0 +1,353 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+func newTestCluster(t *testing.T, name string) *Cluster {
+	return &Cluster{
+		logger:   log.NewNopLogger(),
+		self:     pb.ClusterMember{Name: name, Addr: "127.0.0.1:1" + name},
+		interval: time.Second,
+		dialOpts: []grpc.DialOption{grpc.WithInsecure()},
+		peers:    make(map[string]*clusterPeer),
+	}
+}
+
+// addTestPeer adds a peer whose rpcs fail right away
+func addTestPeer(t *testing.T, c *Cluster, name string, lastSeen time.Time) *clusterPeer {
+	conn, err := grpc.Dial("127.0.0.1:1", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &clusterPeer{addr: "10.0.0.1:" + name, name: name, conn: conn, lastSeen: lastSeen}
+	c.peers[p.addr] = p
+	return p
+}
+
+func closeTestCluster(c *Cluster) {
+	for _, p := range c.peers {
+		p.conn.Close()
+	}
+}
+
+func TestClusterLeader(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-time.Minute)
+	cases := []struct {
+		name  string
+		self  string
+		peers map[string]time.Time
+		want  bool
+	}{
+		{"alone", "b", nil, true},
+		{"lowest name", "a", map[string]time.Time{"b": now, "c": now}, true},
+		{"lower peer alive", "b", map[string]time.Time{"a": now, "c": now}, false},
+		{"lower peer dead", "b", map[string]time.Time{"a": stale, "c": now}, true},
+		{"lower peer never seen", "b", map[string]time.Time{"a": {}}, true},
+	}
+	for _, tc := range cases {
+		c := newTestCluster(t, tc.self)
+		for name, seen := range tc.peers {
+			addTestPeer(t, c, name, seen)
+		}
+		if got := c.IsLeader(); got != tc.want {
+			t.Errorf("%s: IsLeader=%v, want %v", tc.name, got, tc.want)
+		}
+		closeTestCluster(c)
+	}
+	var none *Cluster
+	if !none.IsLeader() {
+		t.Errorf("without a cluster the replica must lead")
+	}
+}
+
+func tlsPeerContext(ctx context.Context, cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 6001}, AuthInfo: info})
+}
+
+func bearerContext(ctx context.Context, token string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestClusterCheckSecret(t *testing.T) {
+	bg := context.Background()
+	cases := []struct {
+		name       string
+		secret     string
+		identities []string
+		ctx        context.Context
+		ok         bool
+	}{
+		{"nothing configured", "", nil, bearerContext(bg, ""), false},
+		{"secret matches", "s3", nil, bearerContext(bg, "s3"), true},
+		{"secret wrong", "s3", nil, bearerContext(bg, "s4"), false},
+		{"secret missing", "s3", nil, bg, false},
+		{"cert matches", "", []string{"server-1"}, tlsPeerContext(bg, "server-1"), true},
+		{"cert unknown", "", []string{"server-1"}, tlsPeerContext(bg, "agent-1"), false},
+		{"cert missing", "", []string{"server-1"}, bearerContext(bg, "s3"), false},
+		{"both match", "s3", []string{"server-1"}, bearerContext(tlsPeerContext(bg, "server-1"), "s3"), true},
+		{"both but secret wrong", "s3", []string{"server-1"}, bearerContext(tlsPeerContext(bg, "server-1"), "x"), false},
+		{"both but cert missing", "s3", []string{"server-1"}, bearerContext(bg, "s3"), false},
+	}
+	for _, tc := range cases {
+		c := newTestCluster(t, "a")
+		c.secret = tc.secret
+		c.identities = tc.identities
+		err := c.checkSecret(tc.ctx)
+		if tc.ok && err != nil {
+			t.Errorf("%s: rejected: %v", tc.name, err)
+		}
+		if !tc.ok && status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: got %v, want Unauthenticated", tc.name, err)
+		}
+	}
+}
+
+func TestNewClusterRequiresAuthentication(t *testing.T) {
+	defer func() { ClusterC = nil }()
+	cases := []struct {
+		name string
+		cfg  ClusterConfig
+		tls  *RpcTlsConfig
+		ok   bool
+	}{
+		{"no secret", ClusterConfig{AdvertiseAddr: "127.0.0.1:6001"}, nil, false},
+		{"secret", ClusterConfig{AdvertiseAddr: "127.0.0.1:6001", Secret: "s3"}, nil, true},
+		{"identities without rpc_tls", ClusterConfig{AdvertiseAddr: "127.0.0.1:6001", CertIdentities: []string{"a"}}, nil, false},
+		{"identities without client cert", ClusterConfig{AdvertiseAddr: "127.0.0.1:6001", CertIdentities: []string{"a"}},
+			&RpcTlsConfig{CAFile: "ca.pem"}, false},
+	}
+	for _, tc := range cases {
+		ClusterC = nil
+		err := NewCluster(log.NewNopLogger(), &tc.cfg, tc.tls)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err=%v, want ok=%v", tc.name, err, tc.ok)
+		}
+		if ClusterC != nil {
+			closeTestCluster(ClusterC)
+		}
+	}
+}
+
+func TestClusterMergeAgents(t *testing.T) {
+	const ip = "10.0.0.97"
+	defer func() {
+		forgetAgent(ip)
+		agentRegistryMux.Lock()
+		delete(agentRegistry, ip)
+		agentRegistryMux.Unlock()
+	}()
+	c := newTestCluster(t, "b")
+	defer closeTestCluster(c)
+
+	report := func(region string) *pb.ProberAgentIpReportRequest {
+		return &pb.ProberAgentIpReportRequest{Ip: ip, Region: region}
+	}
+	steps := []struct {
+		name         string
+		agent        *pb.ClusterAgent
+		region       string
+		lastPush     int64
+		inTargetPool bool
+	}{
+		{"first report", &pb.ClusterAgent{Report: report("r1"), LastReport: 100, LastPush: 90}, "r1", 90, true},
+		{"older report ignored", &pb.ClusterAgent{Report: report("r2"), LastReport: 50, LastPush: 95}, "r1", 95, true},
+		{"newer report wins", &pb.ClusterAgent{Report: report("r2"), LastReport: 200, LastPush: 80}, "r2", 95, true},
+		{"deregistered on a peer", &pb.ClusterAgent{Report: report("r2"), LastReport: 200, Deregistered: 300}, "r2", 95, false},
+		{"stale report keeps it gone", &pb.ClusterAgent{Report: report("r2"), LastReport: 250}, "r2", 95, false},
+		{"reported after deregistering", &pb.ClusterAgent{Report: report("r3"), LastReport: 400}, "r3", 95, true},
+	}
+	for _, s := range steps {
+		c.merge(&pb.ClusterState{Agents: []*pb.ClusterAgent{s.agent}})
+		var a api.AgentInfo
+		touchAgent(ip, func(ai *api.AgentInfo) { a = *ai })
+		if a.Region != s.region || a.LastPush != s.lastPush {
+			t.Fatalf("%s: region=%s last_push=%d, want %s and %d", s.name, a.Region, a.LastPush, s.region, s.lastPush)
+		}
+		_, inPool := AgentIpRegionMap.Load(ip)
+		if inPool != s.inTargetPool {
+			t.Fatalf("%s: in target pool=%v, want %v", s.name, inPool, s.inTargetPool)
+		}
+	}
+}
+
+func TestClusterMergeMembers(t *testing.T) {
+	c := newTestCluster(t, "b")
+	defer closeTestCluster(c)
+	c.merge(&pb.ClusterState{
+		Self:    &pb.ClusterMember{Name: "a", Addr: "10.0.0.1:6001"},
+		Members: []*pb.ClusterMember{{Name: "c", Addr: "10.0.0.3:6001"}, {Name: "b", Addr: c.self.Addr}},
+	})
+	if len(c.peers) != 2 {
+		t.Fatalf("got %d peers, want the sender and the learned member", len(c.peers))
+	}
+	sender := c.peers["10.0.0.1:6001"]
+	if sender == nil || sender.name != "a" || sender.lastSeen.IsZero() {
+		t.Fatalf("sender not recorded as a live peer: %+v", sender)
+	}
+	if learned := c.peers["10.0.0.3:6001"]; learned == nil || !learned.lastSeen.IsZero() {
+		t.Fatalf("learned member must wait for its own sync: %+v", learned)
+	}
+	if c.IsLeader() {
+		t.Fatalf("peer a is alive, b must not lead")
+	}
+}
+
+func TestClusterMergeGroups(t *testing.T) {
+	as := &AdminStore{
+		logger:  log.NewNopLogger(),
+		cfg:     &AdminConfig{},
+		groups:  map[string]*pb.TargetGroup{"g1": {Name: "g1", ProberType: "icmp", Region: "r1", Target: []string{"10.0.1.1"}, UpdatedAt: 100}},
+		deleted: map[string]int64{"g2": 200},
+	}
+	group := func(name string, at int64, targets ...string) *pb.TargetGroup {
+		return &pb.TargetGroup{Name: name, ProberType: "icmp", Region: "r1", Target: targets, UpdatedAt: at}
+	}
+	as.mergeClusterGroups([]*pb.ClusterTargetGroup{
+		// older than the local copy
+		{Group: group("g1", 50, "10.0.1.9")},
+		// older than the local delete
+		{Group: group("g2", 150, "10.0.2.1")},
+		{Group: group("g3", 10, "10.0.3.1")},
+		// fails validation
+		{Group: group("g4", 10, "$(id)")},
+		{Group: &pb.TargetGroup{Name: "g5", ProberType: "tcp", Region: "r1", Target: []string{"10.0.5.1:443"}, UpdatedAt: 10}},
+	})
+	if got := as.groups["g1"].Target[0]; got != "10.0.1.1" {
+		t.Fatalf("older peer copy replaced g1: %s", got)
+	}
+	for _, name := range []string{"g2", "g4", "g5"} {
+		if _, ok := as.groups[name]; ok {
+			t.Fatalf("group %s must not be merged", name)
+		}
+	}
+	if _, ok := as.groups["g3"]; !ok {
+		t.Fatalf("new group g3 not merged")
+	}
+
+	as.mergeClusterGroups([]*pb.ClusterTargetGroup{
+		{Group: &pb.TargetGroup{Name: "g1", UpdatedAt: 300}, Deleted: true},
+		{Group: group("g2", 250, "10.0.2.1")},
+	})
+	if _, ok := as.groups["g1"]; ok || as.deleted["g1"] != 300 {
+		t.Fatalf("newer delete from a peer not applied")
+	}
+	if _, ok := as.groups["g2"]; !ok {
+		t.Fatalf("group recreated after the delete not merged")
+	}
+	if _, ok := as.deleted["g2"]; ok {
+		t.Fatalf("tombstone of the recreated group kept")
+	}
+}
+
+func TestClusterForwardResults(t *testing.T) {
+	const agent = "10.0.0.96"
+	if err := NewIngestValidator(log.NewNopLogger(), nil); err != nil {
+		t.Fatal(err)
+	}
+	ClusterC = newTestCluster(t, "b")
+	ClusterC.secret = "s3"
+	defer func() {
+		ClusterC = nil
+		IngestV = nil
+		forgetAgent(agent)
+		agentRegistryMux.Lock()
+		delete(agentRegistry, agent)
+		agentRegistryMux.Unlock()
+	}()
+
+	now := time.Now().Unix()
+	result := func(addr string, ts int64, v float32) *pb.ProberResultOne {
+		return &pb.ProberResultOne{
+			WorkerName: agent, MetricName: common.MetricsNamePingLatency, ProbeType: "icmp",
+			SourceRegion: "r1", TargetRegion: "r2", TargetAddr: addr, TimeStamp: ts, Value: v,
+		}
+	}
+	newer := result("10.0.1.2", now-10, 5)
+	storeResult(newer)
+
+	pc := &PCluster{logger: log.NewNopLogger()}
+	in := &pb.ClusterForwardRequest{From: "10.0.0.1:6001", ProberResults: []*pb.ProberResultOne{
+		result("10.0.1.1", now-20, 10),
+		// loses to the newer stored sample
+		result("10.0.1.2", now-5, 6),
+		// out of range
+		result("10.0.1.3", now, 1e9),
+	}}
+	in.ProberResults[1].TimeStamp = now - 30
+
+	if _, err := pc.ForwardResults(bearerContext(context.Background(), "wrong"), in); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("forward with a wrong secret: %v", err)
+	}
+	resp, err := pc.ForwardResults(bearerContext(context.Background(), "s3"), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SuccessNum != 1 {
+		t.Fatalf("stored %d forwarded results, want 1", resp.SuccessNum)
+	}
+	if v, _ := IcmpDataMap.Load(GetProbeResultUid(newer)); v.(*pb.ProberResultOne) != newer {
+		t.Fatalf("older forwarded result replaced the stored one")
+	}
+	if _, ok := IcmpDataMap.Load(GetProbeResultUid(in.ProberResults[2])); ok {
+		t.Fatalf("invalid forwarded result stored")
+	}
+	var a api.AgentInfo
+	touchAgent(agent, func(ai *api.AgentInfo) { a = *ai })
+	if a.NewestResult != now-20 {
+		t.Fatalf("freshness from %d, want the stored result at %d", a.NewestResult, now-20)
+	}
+}
+
+func TestClusterFlushKeepsFailedForwards(t *testing.T) {
+	c := newTestCluster(t, "a")
+	defer closeTestCluster(c)
+	up := addTestPeer(t, c, "b", time.Now())
+	down := addTestPeer(t, c, "c", time.Time{})
+
+	c.Forward([]*pb.ProberResultOne{{TargetAddr: "1"}, {TargetAddr: "2"}})
+	c.flush()
+	if len(up.pending) != 2 || len(down.pending) != 2 {
+		t.Fatalf("pending after a failed flush: up=%d down=%d, want 2 and 2", len(up.pending), len(down.pending))
+	}
+
+	dropped := testutil.ToFloat64(ClusterForwardDroppedCounter)
+	big := make([]*pb.ProberResultOne, clusterForwardQueueSize)
+	for i := range big {
+		big[i] = &pb.ProberResultOne{}
+	}
+	c.Forward(big)
+	c.flush()
+	if len(down.pending) != clusterForwardQueueSize {
+		t.Fatalf("down peer keeps %d results, want the queue limit", len(down.pending))
+	}
+	if down.pending[0] == nil || down.pending[0].TargetAddr == "1" {
+		t.Fatalf("the oldest results must be dropped first")
+	}
+	// two results over the limit for each of the two peers
+	if n := testutil.ToFloat64(ClusterForwardDroppedCounter) - dropped; n != 4 {
+		t.Fatalf("dropped counter grew by %v, want 4", n)
+	}
+}
@@ -1,11 +1,13 @@
 package server
 
 import (
+	"fmt"
 	"io/ioutil"
 
 	"gopkg.in/yaml.v2"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/model"
 )
 
 type Targets struct {
@@ -13,10 +15,190 @@ type Targets struct {
 	Region     string   `yaml:"region"`
 	Target     []string `yaml:"target"`
 }
+
+// SinkConfig is one output sink for processed or raw results
+type SinkConfig struct {
+	Name          string         `yaml:"name,omitempty"`
+	Type          string         `yaml:"type"`
+	URL           string         `yaml:"url"`
+	Username      string         `yaml:"username,omitempty"`
+	Password      string         `yaml:"password,omitempty"`
+	RawResults    bool           `yaml:"raw_results,omitempty"`
+	BatchSize     int            `yaml:"batch_size,omitempty"`
+	FlushInterval model.Duration `yaml:"flush_interval,omitempty"`
+	MaxRetries    int            `yaml:"max_retries,omitempty"`
+	Timeout       model.Duration `yaml:"timeout,omitempty"`
+}
+
+// StreamConfig publishes every raw result to a message bus
+type StreamConfig struct {
+	Type          string         `yaml:"type"`
+	URL           string         `yaml:"url"`
+	Subject       string         `yaml:"subject,omitempty"`
+	Encoding      string         `yaml:"encoding,omitempty"`
+	BatchSize     int            `yaml:"batch_size,omitempty"`
+	FlushInterval model.Duration `yaml:"flush_interval,omitempty"`
+	QueueSize     int            `yaml:"queue_size,omitempty"`
+}
+
+// OtlpConfig exports all server metrics to an opentelemetry collector
+type OtlpConfig struct {
+	Endpoint           string            `yaml:"endpoint"`
+	Protocol           string            `yaml:"protocol,omitempty"`
+	Insecure           bool              `yaml:"insecure,omitempty"`
+	Headers            map[string]string `yaml:"headers,omitempty"`
+	Interval           model.Duration    `yaml:"interval,omitempty"`
+	Timeout            model.Duration    `yaml:"timeout,omitempty"`
+	ResourceAttributes map[string]string `yaml:"resource_attributes,omitempty"`
+}
+
+// AlertRule fires when a processed region-pair or http value compares true
+// against threshold for longer than for, region and addr filters are regexps
+type AlertRule struct {
+	Name           string            `yaml:"name"`
+	Metric         string            `yaml:"metric"`
+	Op             string            `yaml:"op"`
+	Threshold      float64           `yaml:"threshold"`
+	For            model.Duration    `yaml:"for,omitempty"`
+	SourceRegion   string            `yaml:"source_region,omitempty"`
+	TargetRegion   string            `yaml:"target_region,omitempty"`
+	Addr           string            `yaml:"addr,omitempty"`
+	RepeatInterval model.Duration    `yaml:"repeat_interval,omitempty"`
+	Labels         map[string]string `yaml:"labels,omitempty"`
+	Annotations    map[string]string `yaml:"annotations,omitempty"`
+}
+
+// WebhookConfig receives alert notifications in alertmanager webhook format,
+// name is used in metrics and logs instead of the url, default webhook-<index>
+type WebhookConfig struct {
+	Name        string         `yaml:"name,omitempty"`
+	URL         string         `yaml:"url"`
+	BearerToken string         `yaml:"bearer_token,omitempty"`
+	Timeout     model.Duration `yaml:"timeout,omitempty"`
+}
+
+// AnomalyConfig learns a baseline per region pair and metric, empty metrics means all
+type AnomalyConfig struct {
+	Alpha         float64  `yaml:"alpha,omitempty"`
+	SeasonalAlpha float64  `yaml:"seasonal_alpha,omitempty"`
+	Threshold     float64  `yaml:"threshold,omitempty"`
+	MinSamples    int      `yaml:"min_samples,omitempty"`
+	Metrics       []string `yaml:"metrics,omitempty"`
+	StateFile     string   `yaml:"state_file,omitempty"`
+}
+
+// AdminConfig enables the runtime target admin api
+type AdminConfig struct {
+	Token     string `yaml:"token"`
+	StateFile string `yaml:"state_file,omitempty"`
+}
+
+// HistoryConfig keeps the aggregated series in an embedded store for range queries
+type HistoryConfig struct {
+	Dir           string         `yaml:"dir"`
+	Retention     model.Duration `yaml:"retention,omitempty"`
+	BlockDuration model.Duration `yaml:"block_duration,omitempty"`
+}
+
+// SlaReportConfig writes sla reports of every finished period to dir
+type SlaReportConfig struct {
+	Dir            string   `yaml:"dir"`
+	Periods        []string `yaml:"periods,omitempty"`
+	Formats        []string `yaml:"formats,omitempty"`
+	WorstIntervals int      `yaml:"worst_intervals,omitempty"`
+}
+
+// SloConfig is one service level objective over the processed values, a
+// window sli counts windows whose mean compares true against threshold, a
+// ratio sli averages a 0..1 success metric, objective is a percent
+type SloConfig struct {
+	Name         string         `yaml:"name"`
+	Metric       string         `yaml:"metric"`
+	Sli          string         `yaml:"sli,omitempty"`
+	Op           string         `yaml:"op,omitempty"`
+	Threshold    float64        `yaml:"threshold,omitempty"`
+	Objective    float64        `yaml:"objective"`
+	Window       model.Duration `yaml:"window,omitempty"`
+	Period       model.Duration `yaml:"period,omitempty"`
+	SourceRegion string         `yaml:"source_region,omitempty"`
+	TargetRegion string         `yaml:"target_region,omitempty"`
+	Addr         string         `yaml:"addr,omitempty"`
+}
+
+// RpcTlsConfig serves the agent rpc over tls, client_auth is none, request
+// or require and defaults to require when ca_file is set
+type RpcTlsConfig struct {
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	CAFile     string `yaml:"ca_file,omitempty"`
+	ClientAuth string `yaml:"client_auth,omitempty"`
+}
+
+// AgentCredential lets agents presenting token, or a client certificate with
+// cert_identity among its names, claim the listed regions, `*` allows any,
+// a credential with both set needs both
+type AgentCredential struct {
+	Name         string   `yaml:"name"`
+	Token        string   `yaml:"token,omitempty"`
+	CertIdentity string   `yaml:"cert_identity,omitempty"`
+	Regions      []string `yaml:"regions"`
+}
+
+// AgentAuthConfig requires a credential on every agent rpc
+type AgentAuthConfig struct {
+	Credentials []*AgentCredential `yaml:"credentials"`
+}
+
+// ClusterConfig joins server replicas sharing agents, admin target groups
+// and results, peers may list every replica including this one. peers
+// authenticate with secret, or over mtls with a client certificate named in
+// cert_identities, both are needed when both are set
+type ClusterConfig struct {
+	Name           string            `yaml:"name,omitempty"`
+	AdvertiseAddr  string            `yaml:"advertise_addr"`
+	Peers          []string          `yaml:"peers"`
+	Secret         string            `yaml:"secret,omitempty"`
+	CertIdentities []string          `yaml:"cert_identities,omitempty"`
+	SyncInterval   model.Duration    `yaml:"sync_interval,omitempty"`
+	Tls            *ClusterTlsConfig `yaml:"tls,omitempty"`
+}
+
+// ClusterTlsConfig is the client side tls to reach peers serving rpc_tls
+type ClusterTlsConfig struct {
+	CAFile     string `yaml:"ca_file,omitempty"`
+	CertFile   string `yaml:"cert_file,omitempty"`
+	KeyFile    string `yaml:"key_file,omitempty"`
+	ServerName string `yaml:"server_name,omitempty"`
+}
+
+// IngestConfig bounds what agents may push, rate_limit is results per
+// second per agent and 0 disables it, rate_burst defaults to one minute
+// of rate_limit
+type IngestConfig struct {
+	MaxResultAge model.Duration `yaml:"max_result_age,omitempty"`
+	MaxClockSkew model.Duration `yaml:"max_clock_skew,omitempty"`
+	RateLimit    float64        `yaml:"rate_limit,omitempty"`
+	RateBurst    int            `yaml:"rate_burst,omitempty"`
+}
+
 type Config struct {
-	RpcListenAddr     string     `yaml:"rpc_listen_addr"`
-	MetricsListenAddr string     `yaml:"metrics_listen_addr"`
-	ProberTargets     []*Targets `yaml:"prober_targets"`
+	RpcListenAddr     string           `yaml:"rpc_listen_addr"`
+	MetricsListenAddr string           `yaml:"metrics_listen_addr"`
+	RpcTls            *RpcTlsConfig    `yaml:"rpc_tls,omitempty"`
+	AgentAuth         *AgentAuthConfig `yaml:"agent_auth,omitempty"`
+	ProberTargets     []*Targets       `yaml:"prober_targets"`
+	OutputSinks       []*SinkConfig    `yaml:"output_sinks,omitempty"`
+	ResultStream      *StreamConfig    `yaml:"result_stream,omitempty"`
+	OtlpExporter      *OtlpConfig      `yaml:"otlp_exporter,omitempty"`
+	AlertRules        []*AlertRule     `yaml:"alert_rules,omitempty"`
+	AlertWebhooks     []*WebhookConfig `yaml:"alert_webhooks,omitempty"`
+	AnomalyDetection  *AnomalyConfig   `yaml:"anomaly_detection,omitempty"`
+	Admin             *AdminConfig     `yaml:"admin,omitempty"`
+	History           *HistoryConfig   `yaml:"history,omitempty"`
+	SlaReports        *SlaReportConfig `yaml:"sla_reports,omitempty"`
+	Slos              []*SloConfig     `yaml:"slos,omitempty"`
+	Cluster           *ClusterConfig   `yaml:"cluster,omitempty"`
+	Ingest            *IngestConfig    `yaml:"ingest,omitempty"`
 }
 
 func Load(s string) (*Config, error) {
@@ -26,6 +208,15 @@ func Load(s string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	// only periodically probed types the server stores are accepted
+	for i, tg := range cfg.ProberTargets {
+		if !stringIn(tg.ProberType, SupportedProberTypes) {
+			return nil, fmt.Errorf("prober_targets[%d]: unsupported prober_type %q", i, tg.ProberType)
+		}
+		if err := validateTargets(fmt.Sprintf("prober_targets[%d]", i), tg.ProberType, tg.Target); err != nil {
+			return nil, err
+		}
+	}
 	return cfg, nil
 }
 
@@ -37,6 +228,7 @@ func LoadFile(filename string, logger log.Logger) (*Config, error) {
 	cfg, err := Load(string(content))
 	if err != nil {
 		level.Error(logger).Log("msg", "parsing YAML file errr...", "error", err)
+		return nil, err
 	}
 	return cfg, nil
 }
@@ -19,6 +19,8 @@ const (
 	TargetFlushManagerInterval = 60 * time.Second
 	MetricOriginSeparator      = `_`
 	MetricUniqueSeparator      = `#`
+	// results older than this are dropped from the data maps
+	ResultExpireSeconds = 300
 )
 
 // rpc receive data
@@ -80,6 +82,31 @@ func NewMetrics() {
 	prometheus.DefaultRegisterer.MustRegister(HttpConnectDurationMillonsecondsGaugeVec)
 	prometheus.DefaultRegisterer.MustRegister(HttpProcessingDurationMillonsecondsGaugeVec)
 	prometheus.DefaultRegisterer.MustRegister(HttpTransferDurationMillonsecondsGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(SinkPointsWrittenCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(SinkPointsDroppedCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(SinkWriteFailuresCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(StreamResultsPublishedCounter)
+	prometheus.DefaultRegisterer.MustRegister(StreamResultsDroppedCounter)
+	prometheus.DefaultRegisterer.MustRegister(StreamPublishFailuresCounter)
+	prometheus.DefaultRegisterer.MustRegister(StreamQueueLengthGauge)
+	prometheus.DefaultRegisterer.MustRegister(AlertsGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(AlertNotificationsCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(AlertNotificationFailuresCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(AnomalyScoreGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(AnomalyEventsCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(agentCollector{})
+	prometheus.DefaultRegisterer.MustRegister(SloObjectiveGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(SloSliGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(SloErrorBudgetGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(SloBurnRateGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(RpcRejectedCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(ClusterPeerUpGaugeVec)
+	prometheus.DefaultRegisterer.MustRegister(ClusterLeaderGauge)
+	prometheus.DefaultRegisterer.MustRegister(ClusterForwardedCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(ClusterForwardDroppedCounter)
+	prometheus.DefaultRegisterer.MustRegister(IngestRejectedCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(IngestDuplicatesCounterVec)
+	prometheus.DefaultRegisterer.MustRegister(IngestSequenceGapsCounterVec)
 }
 
 func DataProcess(ctx context.Context, logger log.Logger) error {
@@ -99,8 +126,6 @@ func DataProcess(ctx context.Context, logger log.Logger) error {
 			return nil
 		}
 	}
-
-	return nil
 }
 
 func HttpDataProcess(logger log.Logger) {
@@ -119,7 +144,7 @@ func HttpDataProcess(logger log.Logger) {
 
 		// check item expire
 		now := time.Now().Unix()
-		if now-va.TimeStamp > 300 {
+		if now-va.TimeStamp > ResultExpireSeconds {
 			expireds = append(expireds, key)
 		} else {
 			if strings.Contains(va.MetricName, MetricOriginSeparator) {
@@ -209,7 +234,7 @@ func IcmpDataProcess(logger log.Logger) {
 
 		// check item expire
 		now := time.Now().Unix()
-		if now-va.TimeStamp > 300 {
+		if now-va.TimeStamp > ResultExpireSeconds {
 			expireds = append(expireds, key)
 		} else {
 			if strings.Contains(va.MetricName, MetricOriginSeparator) {
@@ -259,9 +284,23 @@ func IcmpDataProcess(logger log.Logger) {
 
 }
 
+// onAggregate hands every processed value to the consumers after prometheus
+func onAggregate(metricName string, labels prometheus.Labels, value float64) {
+	// every replica aggregates the same data, only the leader writes it out
+	if ClusterC.IsLeader() {
+		SinkM.AddAggregate(metricName, labels, value)
+	}
+	AlertM.Observe(metricName, labels, value)
+	AnomalyD.Observe(metricName, labels, value)
+	SloM.Observe(metricName, labels, value)
+	if HistoryDB != nil {
+		HistoryDB.Append(metricName, labels, time.Now().Unix(), value)
+	}
+}
+
 func dealWithDataMapAvg(dataM map[string][]float64, promeVec *prometheus.GaugeVec, pType string) {
 	for uniqueKey, datas := range dataM {
-		//MetricName := strings.Split(uniqueKey, MetricUniqueSeparator)[0]
+		MetricName := strings.Split(uniqueKey, MetricUniqueSeparator)[0]
 		SourceRegion := strings.Split(uniqueKey, MetricUniqueSeparator)[1]
 		TargetRegionOrAddr := strings.Split(uniqueKey, MetricUniqueSeparator)[2]
 		var sum, avg float64
@@ -270,12 +309,15 @@ func dealWithDataMapAvg(dataM map[string][]float64, promeVec *prometheus.GaugeVe
 			sum += ds
 		}
 		avg = sum / float64(num)
+		var labels prometheus.Labels
 		switch pType {
 		case "http":
-			promeVec.With(prometheus.Labels{"source_region": SourceRegion, "addr": TargetRegionOrAddr}).Set(avg)
+			labels = prometheus.Labels{"source_region": SourceRegion, "addr": TargetRegionOrAddr}
 		case "icmp":
-			promeVec.With(prometheus.Labels{"source_region": SourceRegion, "target_region": TargetRegionOrAddr}).Set(avg)
+			labels = prometheus.Labels{"source_region": SourceRegion, "target_region": TargetRegionOrAddr}
 		}
+		promeVec.With(labels).Set(avg)
+		onAggregate(MetricName, labels, avg)
 
 	}
 }
@@ -283,7 +325,7 @@ func dealWithDataMapAvg(dataM map[string][]float64, promeVec *prometheus.GaugeVe
 func dealWithDataMapBool(dataM map[string][]float64, promeVec *prometheus.GaugeVec, pType string) {
 
 	for uniqueKey, datas := range dataM {
-		//MetricName := strings.Split(uniqueKey, MetricUniqueSeparator)[0]
+		MetricName := strings.Split(uniqueKey, MetricUniqueSeparator)[0]
 		SourceRegion := strings.Split(uniqueKey, MetricUniqueSeparator)[1]
 		TargetRegionOrAddr := strings.Split(uniqueKey, MetricUniqueSeparator)[2]
 		//var sum, avg float64
@@ -297,11 +339,13 @@ func dealWithDataMapBool(dataM map[string][]float64, promeVec *prometheus.GaugeV
 			}
 		}
 
-		if thisFailNum == len(datas) {
-			promeVec.With(prometheus.Labels{"source_region": SourceRegion, "target_region": TargetRegionOrAddr}).Set(0)
-		} else {
-			promeVec.With(prometheus.Labels{"source_region": SourceRegion, "target_region": TargetRegionOrAddr}).Set(1)
+		labels := prometheus.Labels{"source_region": SourceRegion, "target_region": TargetRegionOrAddr}
+		var value float64
+		if thisFailNum != len(datas) {
+			value = 1
 		}
+		promeVec.With(labels).Set(value)
+		onAggregate(MetricName, labels, value)
 
 	}
 }
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"xprober/pkg/common"
+)
+
+/*
+   grafana dashboard generator, the panels follow the metric names and labels
+   exported in data_process.go so the dashboard never drifts from the server
+*/
+
+const (
+	defaultDashboardTitle      = `xprober`
+	defaultDashboardDatasource = `Prometheus`
+	dashboardSchemaVersion     = 22
+)
+
+type jsonMap map[string]interface{}
+
+type dashboardBuilder struct {
+	panels []jsonMap
+	nextId int
+	y      int
+}
+
+func (db *dashboardBuilder) add(p jsonMap, w, h int, x int) {
+	db.nextId++
+	p["id"] = db.nextId
+	p["datasource"] = "$datasource"
+	p["gridPos"] = jsonMap{"h": h, "w": w, "x": x, "y": db.y}
+	db.panels = append(db.panels, p)
+}
+
+func (db *dashboardBuilder) row(title string) {
+	db.nextId++
+	db.panels = append(db.panels, jsonMap{
+		"id":        db.nextId,
+		"type":      "row",
+		"title":     title,
+		"collapsed": false,
+		"panels":    []jsonMap{},
+		"gridPos":   jsonMap{"h": 1, "w": 24, "x": 0, "y": db.y},
+	})
+	db.y++
+}
+
+func promTarget(expr, legend, refId string) jsonMap {
+	return jsonMap{"expr": expr, "legendFormat": legend, "refId": refId, "interval": "", "format": "time_series"}
+}
+
+// heatmapPanel draws one row per region pair, colored by value
+func heatmapPanel(title, metricName, unit string) jsonMap {
+	return jsonMap{
+		"type":       "heatmap",
+		"title":      title,
+		"dataFormat": "tsbuckets",
+		"targets": []jsonMap{
+			promTarget(fmt.Sprintf(`avg by (source_region, target_region) (%s{source_region=~"$source_region",target_region=~"$target_region"})`, metricName),
+				"{{source_region}} -> {{target_region}}", "A"),
+		},
+		"color":           jsonMap{"mode": "spectrum", "colorScheme": "interpolateRdYlGn", "exponent": 0.5, "cardColor": "#b4ff00", "colorScale": "sqrt"},
+		"yAxis":           jsonMap{"show": true, "format": "short", "decimals": 0},
+		"xAxis":           jsonMap{"show": true},
+		"yBucketBound":    "auto",
+		"reverseYBuckets": false,
+		"hideZeroBuckets": false,
+		"highlightCards":  true,
+		"cards":           jsonMap{"cardPadding": nil, "cardRound": nil},
+		"tooltip":         jsonMap{"show": true, "showHistogram": false},
+		"legend":          jsonMap{"show": true},
+		"options":         jsonMap{},
+		"description":     "unit: " + unit,
+	}
+}
+
+func graphPanel(title, unit string, stack bool, targets ...jsonMap) jsonMap {
+	return jsonMap{
+		"type":          "graph",
+		"title":         title,
+		"targets":       targets,
+		"stack":         stack,
+		"fill":          map[bool]int{true: 5, false: 1}[stack],
+		"linewidth":     1,
+		"lines":         true,
+		"nullPointMode": "null",
+		"legend":        jsonMap{"show": true, "values": true, "current": true, "avg": true, "max": true, "alignAsTable": true, "rightSide": false},
+		"tooltip":       jsonMap{"shared": true, "sort": 2, "value_type": "individual"},
+		"xaxis":         jsonMap{"mode": "time", "show": true},
+		"yaxes": []jsonMap{
+			{"format": unit, "show": true, "min": 0, "logBase": 1},
+			{"format": "short", "show": false, "logBase": 1},
+		},
+		"yaxis":   jsonMap{"align": false},
+		"options": jsonMap{"dataLinks": []jsonMap{}},
+	}
+}
+
+func queryVariable(name, label, query string) jsonMap {
+	return jsonMap{
+		"name":       name,
+		"label":      label,
+		"type":       "query",
+		"datasource": "$datasource",
+		"query":      query,
+		"refresh":    2,
+		"multi":      true,
+		"includeAll": true,
+		"allValue":   ".*",
+		"current":    jsonMap{"text": "All", "value": "$__all"},
+		"sort":       1,
+		"options":    []jsonMap{},
+	}
+}
+
+var httpStageExprs = []struct {
+	metricName string
+	legend     string
+}{
+	{common.MetricsNameHttpResolvedurationMillonseconds, "resolve"},
+	{common.MetricsNameHttpConnectDurationMillonseconds, "connect"},
+	{common.MetricsNameHttpTlsDurationMillonseconds, "tls"},
+	{common.MetricsNameHttpProcessingDurationMillonseconds, "processing"},
+	{common.MetricsNameHttpTransferDurationMillonseconds, "transfer"},
+}
+
+// BuildGrafanaDashboard returns a grafana dashboard model for the server metrics
+func BuildGrafanaDashboard(title, datasource string) jsonMap {
+	if title == "" {
+		title = defaultDashboardTitle
+	}
+	if datasource == "" {
+		datasource = defaultDashboardDatasource
+	}
+	db := &dashboardBuilder{}
+
+	db.row("Region matrix")
+	db.add(heatmapPanel("Ping latency by region pair", common.MetricsNamePingLatency, "ms"), 12, 10, 0)
+	db.add(heatmapPanel("Ping package drop by region pair", common.MetricsNamePingPackageDrop, "percent"), 12, 10, 12)
+	db.y += 10
+
+	db.row("Latency and loss per source region")
+	latency := graphPanel("$source_region latency", "ms", false,
+		promTarget(fmt.Sprintf(`%s{source_region=~"$source_region",target_region=~"$target_region"} >= 0`, common.MetricsNamePingLatency), "-> {{target_region}}", "A"))
+	latency["repeat"] = "source_region"
+	latency["repeatDirection"] = "h"
+	latency["maxPerRow"] = 2
+	db.add(latency, 12, 8, 0)
+	db.y += 8
+	loss := graphPanel("$source_region package drop", "percent", false,
+		promTarget(fmt.Sprintf(`%s{source_region=~"$source_region",target_region=~"$target_region"}`, common.MetricsNamePingPackageDrop), "-> {{target_region}}", "A"))
+	loss["repeat"] = "source_region"
+	loss["repeatDirection"] = "h"
+	loss["maxPerRow"] = 2
+	db.add(loss, 12, 8, 0)
+	db.y += 8
+
+	db.row("HTTP targets")
+	success := graphPanel("http success", "short", false,
+		promTarget(fmt.Sprintf(`%s{source_region=~"$source_region",addr=~"$addr"}`, common.MetricsNameHttpInterfaceSuccess), "{{source_region}} {{addr}}", "A"))
+	db.add(success, 24, 6, 0)
+	db.y += 6
+	var stages []jsonMap
+	for i, s := range httpStageExprs {
+		stages = append(stages, promTarget(fmt.Sprintf(`avg(%s{source_region=~"$source_region",addr=~"$addr"})`, s.metricName), s.legend, string(rune('A'+i))))
+	}
+	breakdown := graphPanel("$addr stage breakdown", "ms", true, stages...)
+	breakdown["repeat"] = "addr"
+	breakdown["repeatDirection"] = "h"
+	breakdown["maxPerRow"] = 2
+	db.add(breakdown, 12, 8, 0)
+
+	return jsonMap{
+		"title":         title,
+		"uid":           nil,
+		"id":            nil,
+		"tags":          []string{"xprober"},
+		"timezone":      "browser",
+		"editable":      true,
+		"schemaVersion": dashboardSchemaVersion,
+		"version":       1,
+		"refresh":       "30s",
+		"time":          jsonMap{"from": "now-6h", "to": "now"},
+		"panels":        db.panels,
+		"templating": jsonMap{"list": []jsonMap{
+			{
+				"name":    "datasource",
+				"label":   "datasource",
+				"type":    "datasource",
+				"query":   "prometheus",
+				"current": jsonMap{"text": datasource, "value": datasource},
+				"options": []jsonMap{},
+			},
+			queryVariable("source_region", "source region", fmt.Sprintf("label_values(%s, source_region)", common.MetricsNamePingLatency)),
+			queryVariable("target_region", "target region", fmt.Sprintf(`label_values(%s{source_region=~"$source_region"}, target_region)`, common.MetricsNamePingLatency)),
+			queryVariable("addr", "http addr", fmt.Sprintf(`label_values(%s{source_region=~"$source_region"}, addr)`, common.MetricsNameHttpInterfaceSuccess)),
+		}},
+	}
+}
+
+// grafanaDashboardHandler serves /api/v1/grafana/dashboard?title=xx&datasource=xx,
+// with import=true the model is wrapped for grafana's POST /api/dashboards/db
+func grafanaDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	d := BuildGrafanaDashboard(q.Get("title"), q.Get("datasource"))
+	if q.Get("import") == "true" {
+		writeJson(w, http.StatusOK, jsonMap{"dashboard": d, "overwrite": true})
+		return
+	}
+	writeJson(w, http.StatusOK, d)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+/*
+   the server is healthy while it runs, it is ready once the target pool was
+   built from a loaded config and holds targets, so agents asking for targets
+   get some. /-/healthy and /-/ready serve load balancers and kubernetes
+   probes, the grpc health service reports the same readiness for the whole
+   server and every registered service
+*/
+
+const readinessCheckInterval = 5 * time.Second
+
+// set once the target pool was built from a loaded config
+var targetPoolLoaded int32
+
+// Ready tells whether the server should get traffic and why not
+func Ready() (bool, string) {
+	if atomic.LoadInt32(&targetPoolLoaded) == 0 {
+		return false, "config not loaded"
+	}
+	if knownRegions() == nil {
+		return false, "target pool is empty"
+	}
+	return true, ""
+}
+
+func healthyHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "xprober-server is Healthy.\n")
+}
+
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	if ok, reason := Ready(); !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "xprober-server is not ready: %s.\n", reason)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "xprober-server is Ready.\n")
+}
+
+// watchReadiness keeps the grpc health status in line with Ready until ctx
+// is done, then reports every service as not serving
+func watchReadiness(ctx context.Context, s *grpc.Server, hs *health.Server) {
+	services := []string{""}
+	for name := range s.GetServiceInfo() {
+		services = append(services, name)
+	}
+	set := func() {
+		st := healthpb.HealthCheckResponse_NOT_SERVING
+		if ok, _ := Ready(); ok {
+			st = healthpb.HealthCheckResponse_SERVING
+		}
+		for _, name := range services {
+			hs.SetServingStatus(name, st)
+		}
+	}
+	set()
+	ticker := time.NewTicker(readinessCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			set()
+		case <-ctx.Done():
+			hs.Shutdown()
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/model"
+
+	"xprober/pkg/tsdb"
+)
+
+const (
+	defaultQueryRange = time.Hour
+	minQueryStep      = 15
+	maxQueryPoints    = 11000
+)
+
+var (
+	HistoryDB *tsdb.DB
+)
+
+func NewHistory(logger log.Logger, cfg *HistoryConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	db, err := tsdb.Open(log.With(logger, "component", "tsdb"), tsdb.Options{
+		Dir:           cfg.Dir,
+		Retention:     time.Duration(cfg.Retention),
+		BlockDuration: time.Duration(cfg.BlockDuration),
+	})
+	if err != nil {
+		return err
+	}
+	HistoryDB = db
+	return nil
+}
+
+func RunHistory(ctx context.Context, logger log.Logger) error {
+	if HistoryDB == nil {
+		<-ctx.Done()
+		return nil
+	}
+	level.Info(logger).Log("msg", "History tsdb start....")
+	return HistoryDB.Run(ctx)
+}
+
+// parseTime accepts unix seconds or rfc3339
+func parseTime(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(int64(f), 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// parseStep accepts seconds or a prometheus duration like 5m
+func parseStep(s string) (int64, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(f), nil
+	}
+	d, err := model.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(time.Duration(d).Seconds()), nil
+}
+
+// QueryHistory returns the stored series of a metric filtered by labels in
+// [start, end], averaged per step seconds, step 0 returns the raw samples
+func QueryHistory(metricName string, match tsdb.Labels, start, end time.Time, step int64) ([]*tsdb.Series, error) {
+	return HistoryDB.Query(metricName, match, start.Unix(), end.Unix(), step)
+}
+
+// queryRangeHandler serves /api/v1/query_range?metric=xx&source_region=xx&target_region=xx&addr=xx&start=xx&end=xx&step=xx
+func queryRangeHandler(w http.ResponseWriter, r *http.Request) {
+	if HistoryDB == nil {
+		writeJsonError(w, http.StatusNotFound, "history is disabled")
+		return
+	}
+	q := r.URL.Query()
+	metricName := q.Get("metric")
+	if metricName == "" {
+		writeJsonError(w, http.StatusBadRequest, "metric is required")
+		return
+	}
+	end, err := parseTime(q.Get("end"), time.Now())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, "invalid end: "+err.Error())
+		return
+	}
+	start, err := parseTime(q.Get("start"), end.Add(-defaultQueryRange))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, "invalid start: "+err.Error())
+		return
+	}
+	if !end.After(start) {
+		writeJsonError(w, http.StatusBadRequest, "end must be after start")
+		return
+	}
+	rangeSeconds := int64(end.Sub(start).Seconds())
+	step := rangeSeconds / 250
+	if step < minQueryStep {
+		step = minQueryStep
+	}
+	if s := q.Get("step"); s != "" {
+		if step, err = parseStep(s); err != nil || step < 0 {
+			writeJsonError(w, http.StatusBadRequest, "invalid step: "+s)
+			return
+		}
+	}
+	// raw samples are written every MetricCollectInterval
+	pointStep := step
+	if pointStep == 0 {
+		pointStep = int64(MetricCollectInterval.Seconds())
+	}
+	if rangeSeconds/pointStep > maxQueryPoints {
+		writeJsonError(w, http.StatusBadRequest, "too many points, increase step or narrow the range")
+		return
+	}
+	match := tsdb.Labels{
+		"source_region": q.Get("source_region"),
+		"target_region": q.Get("target_region"),
+		"addr":          q.Get("addr"),
+	}
+	res, err := QueryHistory(metricName, match, start, end, step)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJson(w, http.StatusOK, res)
+}
@@ -0,0 +1,453 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/api"
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+/*
+   pushed results are validated first, a result of an unknown probe type or
+   metric, region, with a timestamp out of bounds or a value out of range is
+   rejected and reported back to the agent. every agent may push at most
+   rate_limit results per second, a push over the limit fails as a whole and
+   the agent retries it later.
+
+   result sequence tracking. every agent numbers its results, the server
+   keeps a window of the sequences seen per agent to drop results it already
+   has, a sequence leaving the window unseen is counted as a gap. results
+   may arrive a little out of order within the window, a push collects the
+   newest result of every target while probes keep running
+*/
+
+const (
+	// sequences tracked per agent, more than the results of one push cycle
+	seqWindowSize = 1 << 16
+
+	DefaultIngestMaxResultAge = model.Duration(24 * time.Hour)
+	DefaultIngestMaxClockSkew = model.Duration(5 * time.Minute)
+
+	// rejected results listed in a push response, all are counted
+	maxRejectedInResponse = 100
+
+	rejectUnknownProbeType    = `unknown_probe_type`
+	rejectUnknownMetric       = `unknown_metric`
+	rejectInvalidWorker       = `invalid_worker_name`
+	rejectMissingTarget       = `missing_target`
+	rejectUnknownSourceRegion = `unknown_source_region`
+	rejectUnknownTargetRegion = `unknown_target_region`
+	rejectTimestampTooOld     = `timestamp_too_old`
+	rejectTimestampInFuture   = `timestamp_in_future`
+	rejectInvalidValue        = `invalid_value`
+	rejectRateLimited         = `rate_limited`
+	// not a rejection, the push fails until the agent reported its region
+	rejectUnregistered = `unregistered_agent`
+)
+
+// metricSpec is what an agent may push for one metric, failed probes
+// report -1
+type metricSpec struct {
+	probeType string
+	min, max  float64
+}
+
+// one hour in milliseconds
+const maxDurationMillis = 3600 * 1000
+
+var ingestMetrics = map[string]metricSpec{
+	common.MetricsNamePingLatency:                         {"icmp", -1, maxDurationMillis},
+	common.MetricsNamePingPackageDrop:                     {"icmp", -1, 100},
+	common.MetricsNamePingTargetSuccess:                   {"icmp", -1, 1},
+	common.MetricsNameHttpResolvedurationMillonseconds:    {"http", -1, maxDurationMillis},
+	common.MetricsNameHttpTlsDurationMillonseconds:        {"http", -1, maxDurationMillis},
+	common.MetricsNameHttpConnectDurationMillonseconds:    {"http", -1, maxDurationMillis},
+	common.MetricsNameHttpProcessingDurationMillonseconds: {"http", -1, maxDurationMillis},
+	common.MetricsNameHttpTransferDurationMillonseconds:   {"http", -1, maxDurationMillis},
+	common.MetricsNameHttpInterfaceSuccess:                {"http", -1, 1},
+}
+
+var (
+	IngestV *IngestValidator
+
+	IngestRejectedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameIngestRejected,
+		Help: "pushed results rejected by validation or the rate limit",
+	}, []string{"agent", "reason"})
+	IngestDuplicatesCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameIngestDuplicates,
+		Help: "pushed results dropped because the server already had them",
+	}, []string{"agent"})
+	IngestSequenceGapsCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameIngestSequenceGaps,
+		Help: "result sequence numbers never received from the agent",
+	}, []string{"agent"})
+
+	seqTrackersMux sync.Mutex
+	seqTrackers    = make(map[string]*seqWindow)
+)
+
+// seqWindow remembers the sequences in (max-seqWindowSize, max]
+type seqWindow struct {
+	epoch int64
+	// sequences below are decided, 0 before the first result
+	floor uint64
+	// lowest sequence seen, nothing earlier counts as a gap
+	start uint64
+	max   uint64
+	seen  [seqWindowSize / 64]uint64
+}
+
+func (w *seqWindow) bit(seq uint64) (int, uint64) {
+	i := seq % seqWindowSize
+	return int(i / 64), 1 << (i % 64)
+}
+
+// observe records seq, it returns whether seq was seen before and how many
+// sequences were given up as gaps
+func (w *seqWindow) observe(seq uint64) (dup bool, gaps uint64) {
+	if w.floor == 0 {
+		// first result of the epoch, a batch may carry lower sequences
+		// after it so the window reaches back below it
+		w.floor = 1
+		if seq >= seqWindowSize {
+			w.floor = seq - seqWindowSize + 1
+		}
+		w.start = seq
+		w.max = seq
+		i, b := w.bit(seq)
+		w.seen[i] |= b
+		return false, 0
+	}
+	if seq < w.floor {
+		return true, 0
+	}
+	if seq < w.start {
+		w.start = seq
+	}
+	if seq > w.max {
+		gaps = w.advance(seq)
+	}
+	i, b := w.bit(seq)
+	if w.seen[i]&b != 0 {
+		return true, gaps
+	}
+	w.seen[i] |= b
+	return false, gaps
+}
+
+// advance moves the window to end at seq, deciding the sequences leaving it
+func (w *seqWindow) advance(seq uint64) (gaps uint64) {
+	if seq >= seqWindowSize {
+		newFloor := seq - seqWindowSize + 1
+		s := w.floor
+		if w.start > s {
+			// never seen below start
+			s = w.start
+		}
+		for ; s < newFloor; s++ {
+			if s > w.max {
+				// never in the window
+				gaps += newFloor - s
+				break
+			}
+			i, b := w.bit(s)
+			if w.seen[i]&b == 0 {
+				gaps++
+			}
+			w.seen[i] &^= b
+		}
+		if newFloor > w.floor {
+			w.floor = newFloor
+		}
+	}
+	w.max = seq
+	return gaps
+}
+
+// dedupResults drops the results the server already got from the agent
+func dedupResults(prs []*pb.ProberResultOne) []*pb.ProberResultOne {
+	seqTrackersMux.Lock()
+	defer seqTrackersMux.Unlock()
+	res := prs[:0:0]
+	for _, prr := range prs {
+		if prr.Seq == 0 {
+			res = append(res, prr)
+			continue
+		}
+		w, ok := seqTrackers[prr.WorkerName]
+		if !ok || prr.SeqEpoch > w.epoch {
+			// new agent or restarted agent
+			w = &seqWindow{epoch: prr.SeqEpoch}
+			seqTrackers[prr.WorkerName] = w
+		} else if prr.SeqEpoch < w.epoch {
+			// spooled before the agent restarted, keep-newest storing is
+			// all the protection left
+			res = append(res, prr)
+			continue
+		}
+		dup, gaps := w.observe(prr.Seq)
+		if gaps > 0 {
+			IngestSequenceGapsCounterVec.WithLabelValues(prr.WorkerName).Add(float64(gaps))
+		}
+		if dup {
+			IngestDuplicatesCounterVec.WithLabelValues(prr.WorkerName).Inc()
+			continue
+		}
+		res = append(res, prr)
+	}
+	return res
+}
+
+// touchNewestResult records the probe time of the newest result per agent
+func touchNewestResult(prs []*pb.ProberResultOne) {
+	newest := make(map[string]int64)
+	for _, prr := range prs {
+		if prr.TimeStamp > newest[prr.WorkerName] {
+			newest[prr.WorkerName] = prr.TimeStamp
+		}
+	}
+	for ip, ts := range newest {
+		touchAgent(ip, func(a *api.AgentInfo) {
+			if ts > a.NewestResult {
+				a.NewestResult = ts
+			}
+		})
+	}
+}
+
+type IngestValidator struct {
+	logger       log.Logger
+	maxResultAge time.Duration
+	maxClockSkew time.Duration
+	rateLimit    float64
+	rateBurst    float64
+
+	mux      sync.Mutex
+	limiters map[string]*tokenBucket
+}
+
+// NewIngestValidator validates with the defaults when cfg is nil
+func NewIngestValidator(logger log.Logger, cfg *IngestConfig) error {
+	if cfg == nil {
+		cfg = &IngestConfig{}
+	}
+	if cfg.MaxResultAge == 0 {
+		cfg.MaxResultAge = DefaultIngestMaxResultAge
+	}
+	if cfg.MaxClockSkew == 0 {
+		cfg.MaxClockSkew = DefaultIngestMaxClockSkew
+	}
+	if cfg.RateLimit < 0 || cfg.RateBurst < 0 {
+		return fmt.Errorf("ingest rate_limit and rate_burst must not be negative")
+	}
+	burst := float64(cfg.RateBurst)
+	if burst == 0 {
+		burst = cfg.RateLimit * 60
+	}
+	IngestV = &IngestValidator{
+		logger:       log.With(logger, "component", "ingest"),
+		maxResultAge: time.Duration(cfg.MaxResultAge),
+		maxClockSkew: time.Duration(cfg.MaxClockSkew),
+		rateLimit:    cfg.RateLimit,
+		rateBurst:    burst,
+		limiters:     make(map[string]*tokenBucket),
+	}
+	return nil
+}
+
+// knownRegions are the regions of the target pool, nil before it is loaded
+func knownRegions() map[string]bool {
+	var regions map[string]bool
+	f := func(k, v interface{}) bool {
+		if regions == nil {
+			regions = make(map[string]bool)
+		}
+		regions[k.(string)] = true
+		return true
+	}
+	IcmpRegionProberMap.Range(f)
+	OtherRegionProberMap.Range(f)
+	return regions
+}
+
+// check returns why prr is rejected, empty when it is valid
+func (iv *IngestValidator) check(prr *pb.ProberResultOne, regions map[string]bool, now time.Time) string {
+	if net.ParseIP(prr.WorkerName) == nil {
+		return rejectInvalidWorker
+	}
+	// only types storeResult keeps, tcp is probed ad-hoc only
+	if !stringIn(prr.ProbeType, SupportedProberTypes) {
+		return rejectUnknownProbeType
+	}
+	spec, ok := ingestMetrics[prr.MetricName]
+	if !ok || spec.probeType != prr.ProbeType {
+		return rejectUnknownMetric
+	}
+	if prr.TargetAddr == "" {
+		return rejectMissingTarget
+	}
+	if regions != nil {
+		if !regions[prr.SourceRegion] {
+			// an agent may sit in a region without targets, its own report
+			// tells the region
+			switch agentRegion(prr.WorkerName) {
+			case prr.SourceRegion:
+			case "":
+				return rejectUnregistered
+			default:
+				return rejectUnknownSourceRegion
+			}
+		}
+		if !regions[prr.TargetRegion] {
+			return rejectUnknownTargetRegion
+		}
+	}
+	ts := time.Unix(prr.TimeStamp, 0)
+	if ts.Before(now.Add(-iv.maxResultAge)) {
+		return rejectTimestampTooOld
+	}
+	if ts.After(now.Add(iv.maxClockSkew)) {
+		return rejectTimestampInFuture
+	}
+	v := float64(prr.Value)
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < spec.min || v > spec.max {
+		return rejectInvalidValue
+	}
+	return ""
+}
+
+// validate splits prs into valid and rejected results, the push fails when
+// an agent is over its rate limit
+func (iv *IngestValidator) validate(prs []*pb.ProberResultOne, resp *pb.ProberResultPushResponse) ([]*pb.ProberResultOne, error) {
+	if iv == nil {
+		return prs, nil
+	}
+	regions := knownRegions()
+	now := time.Now()
+	valid := prs[:0:0]
+	var rejected []*pb.ProberResultOne
+	reasons := make(map[string]int)
+	for _, prr := range prs {
+		reason := iv.check(prr, regions, now)
+		if reason == "" {
+			valid = append(valid, prr)
+			continue
+		}
+		if reason == rejectUnregistered {
+			IngestRejectedCounterVec.WithLabelValues(prr.WorkerName, reason).Add(float64(len(prs)))
+			return nil, status.Errorf(codes.FailedPrecondition, "agent %s has not reported its region yet", prr.WorkerName)
+		}
+		agent := prr.WorkerName
+		if reason == rejectInvalidWorker {
+			agent = ""
+		} else {
+			rejected = append(rejected, prr)
+		}
+		IngestRejectedCounterVec.WithLabelValues(agent, reason).Inc()
+		reasons[reason]++
+		resp.RejectedNum++
+		if len(resp.Rejected) < maxRejectedInResponse {
+			resp.Rejected = append(resp.Rejected, &pb.RejectedResult{
+				Seq:        prr.Seq,
+				MetricName: prr.MetricName,
+				TargetAddr: prr.TargetAddr,
+				Reason:     reason,
+			})
+		}
+	}
+	if len(reasons) > 0 {
+		level.Warn(iv.logger).Log("msg", "rejected pushed results", "agent", prs[0].WorkerName, "results", len(prs), "reasons", fmt.Sprint(reasons))
+	}
+	if err := iv.limit(valid); err != nil {
+		return nil, err
+	}
+	// rejected results used up their sequence too, they are no gap
+	dedupResults(rejected)
+	return valid, nil
+}
+
+// filterForwarded drops forwarded results failing validation, the replica
+// the agent pushed to checked its registration and rate already
+func (iv *IngestValidator) filterForwarded(prs []*pb.ProberResultOne) []*pb.ProberResultOne {
+	if iv == nil {
+		return prs
+	}
+	regions := knownRegions()
+	now := time.Now()
+	valid := prs[:0:0]
+	reasons := make(map[string]int)
+	for _, prr := range prs {
+		reason := iv.check(prr, regions, now)
+		if reason == "" || reason == rejectUnregistered {
+			valid = append(valid, prr)
+			continue
+		}
+		agent := prr.WorkerName
+		if reason == rejectInvalidWorker {
+			agent = ""
+		}
+		IngestRejectedCounterVec.WithLabelValues(agent, reason).Inc()
+		reasons[reason]++
+	}
+	if len(reasons) > 0 {
+		level.Warn(iv.logger).Log("msg", "rejected forwarded results", "results", len(prs), "reasons", fmt.Sprint(reasons))
+	}
+	return valid
+}
+
+// limit takes a token per result from the bucket of every agent
+func (iv *IngestValidator) limit(prs []*pb.ProberResultOne) error {
+	if iv.rateLimit <= 0 || len(prs) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, prr := range prs {
+		counts[prr.WorkerName]++
+	}
+	now := time.Now()
+	iv.mux.Lock()
+	defer iv.mux.Unlock()
+	for agent, n := range counts {
+		tb, ok := iv.limiters[agent]
+		if !ok {
+			tb = &tokenBucket{tokens: iv.rateBurst, last: now}
+			iv.limiters[agent] = tb
+		}
+		if !tb.take(float64(n), iv.rateLimit, iv.rateBurst, now) {
+			IngestRejectedCounterVec.WithLabelValues(agent, rejectRateLimited).Add(float64(n))
+			level.Warn(iv.logger).Log("msg", "agent over the rate limit", "agent", agent, "results", n)
+			return status.Errorf(codes.ResourceExhausted, "agent %s is over the rate limit of %g results per second", agent, iv.rateLimit)
+		}
+	}
+	return nil
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and takes n tokens when there are enough, a
+// batch larger than the burst passes once the bucket is full
+func (tb *tokenBucket) take(n, rate, burst float64, now time.Time) bool {
+	tb.tokens = math.Min(burst, tb.tokens+now.Sub(tb.last).Seconds()*rate)
+	tb.last = now
+	if n > tb.tokens && tb.tokens < burst {
+		return false
+	}
+	tb.tokens -= n
+	return true
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+func TestSeqWindowOutOfOrderFirstBatch(t *testing.T) {
+	w := &seqWindow{epoch: 1}
+	for _, seq := range []uint64{7, 3, 5, 1, 2, 4, 6} {
+		if dup, gaps := w.observe(seq); dup || gaps != 0 {
+			t.Fatalf("seq %d: dup=%v gaps=%d, want a new sequence", seq, dup, gaps)
+		}
+	}
+	for _, seq := range []uint64{1, 4, 7} {
+		if dup, _ := w.observe(seq); !dup {
+			t.Fatalf("seq %d seen twice is not a duplicate", seq)
+		}
+	}
+}
+
+func TestSeqWindowGaps(t *testing.T) {
+	w := &seqWindow{epoch: 1}
+	for _, seq := range []uint64{1, 2, 4} {
+		w.observe(seq)
+	}
+	// pushes 1..4 out of the window, 3 never arrived
+	if dup, gaps := w.observe(4 + seqWindowSize); dup || gaps != 1 {
+		t.Fatalf("dup=%v gaps=%d, want 1 gap", dup, gaps)
+	}
+	if dup, _ := w.observe(3); !dup {
+		t.Fatalf("seq below the window is not a duplicate")
+	}
+}
+
+func TestSeqWindowStartsMidStream(t *testing.T) {
+	// the server came up while the agent was already numbering results
+	w := &seqWindow{epoch: 1}
+	first := uint64(100000)
+	w.observe(first)
+	if dup, _ := w.observe(first - 10); dup {
+		t.Fatalf("lower sequence of the first batch is a duplicate")
+	}
+	if _, gaps := w.observe(first + seqWindowSize); gaps != 9 {
+		t.Fatalf("gaps=%d, want only the 9 missing sequences after the lowest seen", gaps)
+	}
+}
+
+func TestDedupResultsOutOfOrder(t *testing.T) {
+	const agent = "10.0.0.99"
+	defer func() {
+		seqTrackersMux.Lock()
+		delete(seqTrackers, agent)
+		seqTrackersMux.Unlock()
+	}()
+	batch := func(seqs ...uint64) []*pb.ProberResultOne {
+		var prs []*pb.ProberResultOne
+		for _, s := range seqs {
+			prs = append(prs, &pb.ProberResultOne{WorkerName: agent, Seq: s, SeqEpoch: 1})
+		}
+		return prs
+	}
+	if res := dedupResults(batch(7, 3, 5, 1, 2, 4, 6)); len(res) != 7 {
+		t.Fatalf("kept %d of the first batch, want 7", len(res))
+	}
+	// a retried push after a lost response
+	if res := dedupResults(batch(6, 7, 8)); len(res) != 1 || res[0].Seq != 8 {
+		t.Fatalf("retry kept %v, want only seq 8", res)
+	}
+	// a restarted agent numbers from 1 again
+	prs := batch(1, 2)
+	for _, prr := range prs {
+		prr.SeqEpoch = 2
+	}
+	if res := dedupResults(prs); len(res) != 2 {
+		t.Fatalf("kept %d after a restart, want 2", len(res))
+	}
+}
+
+func TestAcceptResultsStoresValidResults(t *testing.T) {
+	if err := NewIngestValidator(log.NewNopLogger(), nil); err != nil {
+		t.Fatal(err)
+	}
+	const agent = "10.0.0.98"
+	defer func() {
+		IngestV = nil
+		forgetAgent(agent)
+		agentRegistryMux.Lock()
+		delete(agentRegistry, agent)
+		agentRegistryMux.Unlock()
+	}()
+	now := time.Now().Unix()
+	icmp := &pb.ProberResultOne{
+		WorkerName: agent, MetricName: common.MetricsNamePingLatency, ProbeType: "icmp",
+		SourceRegion: "r1", TargetRegion: "r2", TargetAddr: "10.0.1.1", TimeStamp: now, Value: 12,
+	}
+	tcp := &pb.ProberResultOne{
+		WorkerName: agent, MetricName: common.MetricsNameTcpConnectSuccess, ProbeType: "tcp",
+		SourceRegion: "r1", TargetRegion: "r2", TargetAddr: "10.0.1.1:443", TimeStamp: now, Value: 1,
+	}
+	resp := &pb.ProberResultPushResponse{}
+	if err := acceptResults([]*pb.ProberResultOne{icmp, tcp}, resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.SuccessNum != 1 || resp.RejectedNum != 1 {
+		t.Fatalf("success=%d rejected=%d, want 1 and 1", resp.SuccessNum, resp.RejectedNum)
+	}
+	if resp.Rejected[0].Reason != rejectUnknownProbeType {
+		t.Fatalf("tcp rejected with %s, want %s", resp.Rejected[0].Reason, rejectUnknownProbeType)
+	}
+	v, ok := IcmpDataMap.Load(GetProbeResultUid(icmp))
+	if !ok || v.(*pb.ProberResultOne) != icmp {
+		t.Fatalf("accepted icmp result is not in IcmpDataMap")
+	}
+}
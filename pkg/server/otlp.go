@@ -0,0 +1,41 @@
+package server
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/otlp"
+)
+
+var (
+	OtlpE *otlp.Exporter
+)
+
+// NewOtlpExporter exports everything registered in the default prometheus registry
+func NewOtlpExporter(logger log.Logger, c *OtlpConfig, rpcListenAddr string) error {
+	if c == nil {
+		return nil
+	}
+	resource := map[string]string{
+		"xprober.rpc_listen_addr": rpcListenAddr,
+	}
+	for k, v := range c.ResourceAttributes {
+		resource[k] = v
+	}
+	e, err := otlp.NewExporter(logger, otlp.Config{
+		Endpoint: c.Endpoint,
+		Protocol: c.Protocol,
+		Insecure: c.Insecure,
+		Headers:  c.Headers,
+		Interval: time.Duration(c.Interval),
+		Timeout:  time.Duration(c.Timeout),
+		Resource: resource,
+	}, "xprober-server", prometheus.DefaultGatherer)
+	if err != nil {
+		return err
+	}
+	OtlpE = e
+	return nil
+}
@@ -3,12 +3,23 @@ package server
 import (
 	"net"
 	"context"
+	"io"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"google.golang.org/grpc"
-
+	"google.golang.org/grpc/codes"
+	// agents push results gzip compressed
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"xprober/pkg/api"
 	"xprober/pkg/pb"
+	"xprober/pkg/tlsutil"
 )
 
 var (
@@ -19,30 +30,58 @@ type GRpcServerManager struct {
 	Logger            log.Logger
 	GrpcListenAddress string
 	Server            *grpc.Server
+	ServerOptions     []grpc.ServerOption
 }
 
-func NewManagager(logger log.Logger, addr string) {
+func NewManagager(logger log.Logger, addr string, tlsCfg *RpcTlsConfig) error {
 	gp := GRpcServerManager{
 		Logger:            logger,
 		GrpcListenAddress: addr,
 	}
+	if tlsCfg != nil {
+		r, err := tlsutil.NewReloader(logger, tlsutil.Files{CAFile: tlsCfg.CAFile, CertFile: tlsCfg.CertFile, KeyFile: tlsCfg.KeyFile})
+		if err != nil {
+			return err
+		}
+		creds, err := r.ServerCredentials(tlsCfg.ClientAuth)
+		if err != nil {
+			return err
+		}
+		gp.ServerOptions = append(gp.ServerOptions, grpc.Creds(creds))
+		level.Info(logger).Log("msg", "grpc tls enabled", "client_ca", tlsCfg.CAFile, "client_auth", tlsCfg.ClientAuth)
+	}
 
 	GRM = &gp
+	return nil
 }
 
 func (gs *GRpcServerManager) Run(ctx context.Context, logger log.Logger) error {
 
-	lis, err := net.Listen("tcp", gs.GrpcListenAddress)
-	if err != nil {
-		level.Error(gs.Logger).Log("msg", "grpc failed to listen: ", "err", err)
-	}
-	s := grpc.NewServer()
+	opts := append(gs.ServerOptions,
+		grpc.ChainUnaryInterceptor(agentAuthUnaryInterceptor),
+		grpc.ChainStreamInterceptor(agentAuthStreamInterceptor),
+	)
+	s := grpc.NewServer(opts...)
 	gs.Server = s
 
 	// register service
 	pb.RegisterGetProberTargetServer(s, &PServer{logger: logger})
 	pb.RegisterPushProberResultServer(s, &PResult{logger: logger})
 	pb.RegisterProberAgentIpReportServer(s, &PAgentR{logger: logger})
+	pb.RegisterProberAdminServer(s, &PAdmin{logger: logger})
+	pb.RegisterProberAdhocServer(s, &PAdhoc{logger: logger})
+	pb.RegisterProberClusterServer(s, &PCluster{logger: logger})
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	reflection.Register(s)
+	go watchReadiness(ctx, s, hs)
+
+	// the server is built first so stopping it works even when listen fails
+	lis, err := net.Listen("tcp", gs.GrpcListenAddress)
+	if err != nil {
+		level.Error(gs.Logger).Log("msg", "grpc failed to listen: ", "err", err)
+		return err
+	}
 	level.Info(gs.Logger).Log("msg", "grpc success to serve", "addr", gs.GrpcListenAddress)
 	if err := s.Serve(lis); err != nil {
 		level.Error(gs.Logger).Log("msg", "grpc failed to serve err", "err", err)
@@ -72,6 +111,11 @@ func (s *PServer) GetProberTargets(ctx context.Context, in *pb.ProberTargetsGetR
 	level.Info(s.logger).Log("msg", "GetProberTargets receive", "region", in.LocalRegion, "ip", in.LocalIp)
 	// TODO real get region
 	region := in.LocalRegion
+	identity := tlsutil.PeerIdentity(ctx)
+	touchAgent(in.LocalIp, func(a *api.AgentInfo) {
+		a.LastTargetsGet = nowUnix()
+		a.CertIdentity = identity
+	})
 	tgs := GetTargetsByRegion(region)
 	return &pb.ProberTargetsGetResponse{Targets: tgs}, nil
 }
@@ -85,19 +129,52 @@ func GetProbeResultUid(prr *pb.ProberResultOne) (uid string) {
 func (pr *PResult) PushProberResults(ctx context.Context, in *pb.ProberResultPushRequest) (*pb.ProberResultPushResponse, error) {
 
 	level.Debug(pr.logger).Log("msg", "PushProberResult receive", "args", in)
-	suNum := 0
-	for _, prr := range in.ProberResults {
-		uid := GetProbeResultUid(prr)
-		switch prr.ProbeType {
-		case `icmp`:
-			IcmpDataMap.Store(uid, prr)
-		case `http`:
-			HttpDataMap.Store(uid, prr)
+	resp := &pb.ProberResultPushResponse{}
+	if err := acceptResults(in.ProberResults, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PushProberResultStream takes batches until the agent closes the stream,
+// the response acknowledges every batch received
+func (pr *PResult) PushProberResultStream(stream pb.PushProberResult_PushProberResultStreamServer) error {
+	resp := &pb.ProberResultPushResponse{}
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		level.Debug(pr.logger).Log("msg", "PushProberResultStream receive", "results", len(in.ProberResults))
+		if err := acceptResults(in.ProberResults, resp); err != nil {
+			return err
 		}
-		suNum += 1
+	}
+}
 
+// acceptResults stores the valid pushed results and adds them to resp,
+// duplicates are acknowledged too so the agent does not send them again
+func acceptResults(prs []*pb.ProberResultOne, resp *pb.ProberResultPushResponse) error {
+	prs, err := IngestV.validate(prs, resp)
+	if err != nil {
+		return err
+	}
+	if len(prs) > 0 {
+		touchAgent(prs[0].WorkerName, func(a *api.AgentInfo) { a.LastPush = nowUnix() })
 	}
-	return &pb.ProberResultPushResponse{SuccessNum: int32(suNum)}, nil
+	resp.SuccessNum += int32(len(prs))
+	prs = dedupResults(prs)
+	for _, prr := range prs {
+		storeResult(prr)
+		SinkM.AddRaw(prr)
+		StreamM.Add(prr)
+	}
+	touchNewestResult(prs)
+	ClusterC.Forward(prs)
+	return nil
 }
 
 func (pr *PAgentR) ProberAgentIpReports(ctx context.Context, in *pb.ProberAgentIpReportRequest) (*pb.ProberAgentIpReportResponse, error) {
@@ -105,6 +182,57 @@ func (pr *PAgentR) ProberAgentIpReports(ctx context.Context, in *pb.ProberAgentI
 	level.Debug(pr.logger).Log("msg", "ProberAgentIpReports receive", "args", in)
 
 	AgentIpRegionMap.Store(in.Ip, in.Region)
+	updateAgentInventory(in, tlsutil.PeerIdentity(ctx))
 
 	return &pb.ProberAgentIpReportResponse{IsSuccess: true}, nil
 }
+
+// checkDeregister lets an agent take only itself out, the ip must be
+// registered in the claimed region. with agent auth the call must also carry
+// the certificate the agent registered with or come from the agent's ip
+func checkDeregister(ctx context.Context, in *pb.ProberAgentDeregisterRequest) error {
+	region, ok := AgentIpRegionMap.Load(in.Ip)
+	if !ok {
+		return status.Errorf(codes.NotFound, "unknown agent %s", in.Ip)
+	}
+	if region.(string) != in.Region {
+		return status.Errorf(codes.PermissionDenied, "agent %s is not in region %q", in.Ip, in.Region)
+	}
+	if AgentA == nil {
+		return nil
+	}
+	identity := ""
+	agentRegistryMux.Lock()
+	if a, ok := agentRegistry[in.Ip]; ok {
+		identity = a.CertIdentity
+	}
+	agentRegistryMux.Unlock()
+	if identity != "" {
+		if tlsutil.PeerIdentity(ctx) != identity {
+			return status.Errorf(codes.PermissionDenied, "client certificate is not the one agent %s registered with", in.Ip)
+		}
+		return nil
+	}
+	var addr net.IP
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			addr = net.ParseIP(host)
+		}
+	}
+	if addr == nil || !addr.Equal(net.ParseIP(in.Ip)) {
+		return status.Errorf(codes.PermissionDenied, "agent %s may only be deregistered from its own address", in.Ip)
+	}
+	return nil
+}
+
+// ProberAgentDeregister takes a shutting down agent out of the target pool
+// right away, peers stop probing it on their next target refresh
+func (pr *PAgentR) ProberAgentDeregister(ctx context.Context, in *pb.ProberAgentDeregisterRequest) (*pb.ProberAgentIpReportResponse, error) {
+	if err := checkDeregister(ctx, in); err != nil {
+		level.Warn(pr.logger).Log("msg", "agent deregister rejected", "ip", in.Ip, "region", in.Region, "err", err)
+		return nil, err
+	}
+	level.Info(pr.logger).Log("msg", "agent deregistered", "ip", in.Ip, "region", in.Region)
+	deregisterAgent(in.Ip, nowUnix())
+	return &pb.ProberAgentIpReportResponse{IsSuccess: true}, nil
+}
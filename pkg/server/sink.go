@@ -0,0 +1,403 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const (
+	SinkTypeInfluxdb = `influxdb`
+	SinkTypeOpentsdb = `opentsdb`
+
+	// raw ProberResultOne points are written with this prefix to keep them
+	// apart from the region-pair aggregates of the same metric
+	RawSinkMeasurementPrefix = `raw_`
+
+	defaultSinkBatchSize     = 500
+	defaultSinkFlushInterval = 10 * time.Second
+	defaultSinkMaxRetries    = 3
+	defaultSinkTimeout       = 5 * time.Second
+	sinkQueueSize            = 10000
+)
+
+var (
+	SinkM *SinkManager
+
+	SinkPointsWrittenCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameSinkPointsWritten,
+		Help: "points successfully written to output sink",
+	}, []string{"sink"})
+	SinkPointsDroppedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameSinkPointsDropped,
+		Help: "points dropped by output sink because of full queue or exhausted retries",
+	}, []string{"sink"})
+	SinkWriteFailuresCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: common.MetricsNameSinkWriteFailures,
+		Help: "failed write attempts of output sink",
+	}, []string{"sink"})
+)
+
+// SinkPoint is one value written to an output sink
+type SinkPoint struct {
+	Measurement string
+	Tags        map[string]string
+	Value       float64
+	TimeStamp   int64
+}
+
+// Sink writes a batch of points to an external storage, Name is the
+// configured sink name used in metrics and logs
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, points []*SinkPoint) error
+}
+
+type sinkWorker struct {
+	logger log.Logger
+	cfg    *SinkConfig
+	sink   Sink
+	queue  chan *SinkPoint
+}
+
+type SinkManager struct {
+	logger  log.Logger
+	workers []*sinkWorker
+}
+
+func NewSinkManager(logger log.Logger, cfgs []*SinkConfig) error {
+	sm := &SinkManager{logger: logger}
+	names := make(map[string]bool)
+	for i, c := range cfgs {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s-%d", c.Type, i)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate output sink name: %s", c.Name)
+		}
+		names[c.Name] = true
+		s, err := newSink(c)
+		if err != nil {
+			return err
+		}
+		sm.workers = append(sm.workers, &sinkWorker{
+			logger: log.With(logger, "sink", s.Name()),
+			cfg:    c,
+			sink:   s,
+			queue:  make(chan *SinkPoint, sinkQueueSize),
+		})
+	}
+	SinkM = sm
+	return nil
+}
+
+func newSink(c *SinkConfig) (Sink, error) {
+	if c.URL == "" {
+		return nil, fmt.Errorf("output sink %s: empty url", c.Name)
+	}
+	timeout := time.Duration(c.Timeout)
+	if timeout <= 0 {
+		timeout = defaultSinkTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	switch c.Type {
+	case SinkTypeInfluxdb:
+		return &InfluxdbSink{name: c.Name, url: c.URL, username: c.Username, password: c.Password, client: client}, nil
+	case SinkTypeOpentsdb:
+		return &OpentsdbSink{name: c.Name, url: strings.TrimSuffix(c.URL, "/") + "/api/put", client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown output sink type: %s", c.Type)
+}
+
+func (sm *SinkManager) Run(ctx context.Context) error {
+	if sm == nil || len(sm.workers) == 0 {
+		<-ctx.Done()
+		return nil
+	}
+	level.Info(sm.logger).Log("msg", "SinkManager start....", "sinks", len(sm.workers))
+	done := make(chan struct{}, len(sm.workers))
+	for _, w := range sm.workers {
+		go func(w *sinkWorker) {
+			w.run(ctx)
+			done <- struct{}{}
+		}(w)
+	}
+	for range sm.workers {
+		<-done
+	}
+	level.Info(sm.logger).Log("msg", "SinkManager exit....")
+	return nil
+}
+
+// AddAggregate queues a processed region-pair value for all sinks
+func (sm *SinkManager) AddAggregate(metricName string, labels prometheus.Labels, value float64) {
+	if sm == nil {
+		return
+	}
+	tags := make(map[string]string, len(labels))
+	for k, v := range labels {
+		tags[k] = v
+	}
+	sm.add(&SinkPoint{Measurement: metricName, Tags: tags, Value: value, TimeStamp: time.Now().Unix()}, false)
+}
+
+// AddRaw queues a raw agent result for sinks with raw_results enabled
+func (sm *SinkManager) AddRaw(prr *pb.ProberResultOne) {
+	if sm == nil {
+		return
+	}
+	sm.add(&SinkPoint{
+		Measurement: RawSinkMeasurementPrefix + prr.MetricName,
+		Tags: map[string]string{
+			"worker_name":   prr.WorkerName,
+			"source_region": prr.SourceRegion,
+			"target_region": prr.TargetRegion,
+			"target_addr":   prr.TargetAddr,
+			"probe_type":    prr.ProbeType,
+		},
+		Value:     float64(prr.Value),
+		TimeStamp: prr.TimeStamp,
+	}, true)
+}
+
+func (sm *SinkManager) add(p *SinkPoint, raw bool) {
+	for _, w := range sm.workers {
+		if raw && !w.cfg.RawResults {
+			continue
+		}
+		select {
+		case w.queue <- p:
+		default:
+			SinkPointsDroppedCounterVec.WithLabelValues(w.sink.Name()).Inc()
+		}
+	}
+}
+
+func (w *sinkWorker) run(ctx context.Context) {
+	batchSize := w.cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultSinkBatchSize
+	}
+	interval := time.Duration(w.cfg.FlushInterval)
+	if interval <= 0 {
+		interval = defaultSinkFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*SinkPoint, 0, batchSize)
+	for {
+		select {
+		case p := <-w.queue:
+			batch = append(batch, p)
+			if len(batch) >= batchSize {
+				w.flush(ctx, batch)
+				batch = make([]*SinkPoint, 0, batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(ctx, batch)
+				batch = make([]*SinkPoint, 0, batchSize)
+			}
+		case <-ctx.Done():
+			if len(batch) > 0 {
+				// best effort final flush, the run ctx is already canceled
+				fctx, cancel := context.WithTimeout(context.Background(), defaultSinkTimeout)
+				w.flush(fctx, batch)
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+func (w *sinkWorker) flush(ctx context.Context, batch []*SinkPoint) {
+	maxRetries := w.cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultSinkMaxRetries
+	}
+	name := w.sink.Name()
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 && ctx.Err() == nil {
+			select {
+			case <-time.After(time.Duration(attempt) * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		err := w.sink.Write(ctx, batch)
+		if err == nil {
+			SinkPointsWrittenCounterVec.WithLabelValues(name).Add(float64(len(batch)))
+			return
+		}
+		SinkWriteFailuresCounterVec.WithLabelValues(name).Inc()
+		level.Warn(w.logger).Log("msg", "sink write failed", "attempt", attempt+1, "points", len(batch), "err", err)
+	}
+	level.Error(w.logger).Log("msg", "sink write retries exhausted, dropping batch", "points", len(batch))
+	SinkPointsDroppedCounterVec.WithLabelValues(name).Add(float64(len(batch)))
+}
+
+// postSinkBody posts body to endpoint, the returned error never contains the
+// endpoint since it may carry credentials in the query
+func postSinkBody(ctx context.Context, client *http.Client, endpoint, contentType string, body []byte, setAuth func(*http.Request)) error {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return stripURLError(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", contentType)
+	if setAuth != nil {
+		setAuth(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return stripURLError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func stripURLError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return fmt.Errorf("%s: %v", ue.Op, ue.Err)
+	}
+	return err
+}
+
+// InfluxdbSink writes points in influx line protocol, url is the full write
+// endpoint eg: http://influxdb:8086/write?db=xprober
+type InfluxdbSink struct {
+	name     string
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+func (s *InfluxdbSink) Name() string {
+	return s.name
+}
+
+func (s *InfluxdbSink) Write(ctx context.Context, points []*SinkPoint) error {
+	var buf bytes.Buffer
+	for _, p := range points {
+		buf.WriteString(influxEscape(p.Measurement, false))
+		keys := make([]string, 0, len(p.Tags))
+		for k := range p.Tags {
+			keys = append(keys, k)
+		}
+		// influx expects tags sorted by key for best performance
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p.Tags[k] == "" {
+				continue
+			}
+			buf.WriteByte(',')
+			buf.WriteString(influxEscape(k, true))
+			buf.WriteByte('=')
+			buf.WriteString(influxEscape(p.Tags[k], true))
+		}
+		buf.WriteString(" value=")
+		buf.WriteString(strconv.FormatFloat(p.Value, 'f', -1, 64))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(p.TimeStamp, 10))
+		buf.WriteByte('\n')
+	}
+	var setAuth func(*http.Request)
+	if s.username != "" {
+		setAuth = func(req *http.Request) { req.SetBasicAuth(s.username, s.password) }
+	}
+	u := s.url
+	if !strings.Contains(u, "precision=") {
+		if strings.Contains(u, "?") {
+			u += "&precision=s"
+		} else {
+			u += "?precision=s"
+		}
+	}
+	return postSinkBody(ctx, s.client, u, "text/plain; charset=utf-8", buf.Bytes(), setAuth)
+}
+
+func influxEscape(s string, isTag bool) string {
+	r := strings.NewReplacer(",", `\,`, " ", `\ `)
+	if isTag {
+		r = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	}
+	return r.Replace(s)
+}
+
+// OpentsdbSink writes points to the /api/put endpoint of opentsdb
+type OpentsdbSink struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+type opentsdbPoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+func (s *OpentsdbSink) Name() string {
+	return s.name
+}
+
+func (s *OpentsdbSink) Write(ctx context.Context, points []*SinkPoint) error {
+	ops := make([]*opentsdbPoint, 0, len(points))
+	for _, p := range points {
+		tags := make(map[string]string, len(p.Tags))
+		for k, v := range p.Tags {
+			if v == "" {
+				continue
+			}
+			tags[opentsdbSanitize(k)] = opentsdbSanitize(v)
+		}
+		ops = append(ops, &opentsdbPoint{
+			Metric:    opentsdbSanitize(p.Measurement),
+			Timestamp: p.TimeStamp,
+			Value:     p.Value,
+			Tags:      tags,
+		})
+	}
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+	return postSinkBody(ctx, s.client, s.url, "application/json", body, nil)
+}
+
+// opentsdb only allows a-z, A-Z, 0-9, -, _, . and / in metric names and tags
+func opentsdbSanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-', r == '_', r == '.', r == '/':
+			return r
+		}
+		return '_'
+	}, s)
+}
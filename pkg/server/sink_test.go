@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type sinkRequest struct {
+	path, query, user, pass, body string
+}
+
+func newTestSinkServer(t *testing.T, status int) (*httptest.Server, chan *sinkRequest, *int32) {
+	reqs := make(chan *sinkRequest, 8)
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		user, pass, _ := r.BasicAuth()
+		reqs <- &sinkRequest{path: r.URL.Path, query: r.URL.RawQuery, user: user, pass: pass, body: string(body)}
+		w.WriteHeader(status)
+	}))
+	return ts, reqs, &calls
+}
+
+func newTestSinkManager(t *testing.T, cfgs ...*SinkConfig) *SinkManager {
+	if err := NewSinkManager(log.NewNopLogger(), cfgs); err != nil {
+		t.Fatal(err)
+	}
+	sm := SinkM
+	SinkM = nil
+	return sm
+}
+
+var testSinkPoints = []*SinkPoint{
+	{Measurement: "ping_latency_millonseconds", Tags: map[string]string{"target_region": "r 2", "source_region": "r1", "empty": ""}, Value: 12.5, TimeStamp: 1600000000},
+	{Measurement: "raw_http interface", Tags: map[string]string{"target_addr": "http://a=b/c,d"}, Value: 1, TimeStamp: 1600000001},
+}
+
+func TestSinkNames(t *testing.T) {
+	sm := newTestSinkManager(t,
+		&SinkConfig{Type: SinkTypeInfluxdb, URL: "http://127.0.0.1:1/write?db=x"},
+		&SinkConfig{Name: "tsdb-main", Type: SinkTypeOpentsdb, URL: "http://127.0.0.1:1"},
+		&SinkConfig{Type: SinkTypeInfluxdb, URL: "http://127.0.0.1:1/write?db=y"},
+	)
+	for i, want := range []string{"influxdb-0", "tsdb-main", "influxdb-2"} {
+		if got := sm.workers[i].sink.Name(); got != want {
+			t.Fatalf("sink %d named %s, want %s", i, got, want)
+		}
+	}
+	err := NewSinkManager(log.NewNopLogger(), []*SinkConfig{
+		{Name: "a", Type: SinkTypeInfluxdb, URL: "http://127.0.0.1:1"},
+		{Name: "a", Type: SinkTypeOpentsdb, URL: "http://127.0.0.1:1"},
+	})
+	if err == nil {
+		SinkM = nil
+		t.Fatalf("duplicate sink names accepted")
+	}
+}
+
+func TestInfluxdbSinkLineProtocol(t *testing.T) {
+	ts, reqs, _ := newTestSinkServer(t, http.StatusNoContent)
+	defer ts.Close()
+	sm := newTestSinkManager(t, &SinkConfig{Name: "influx", Type: SinkTypeInfluxdb, URL: ts.URL + "/write?db=xprober", Username: "u", Password: "p"})
+	written := testutil.ToFloat64(SinkPointsWrittenCounterVec.WithLabelValues("influx"))
+
+	sm.workers[0].flush(context.Background(), testSinkPoints)
+	r := <-reqs
+	if r.path != "/write" || r.query != "db=xprober&precision=s" || r.user != "u" || r.pass != "p" {
+		t.Fatalf("bad influx request %+v", r)
+	}
+	want := "ping_latency_millonseconds,source_region=r1,target_region=r\\ 2 value=12.5 1600000000\n" +
+		"raw_http\\ interface,target_addr=http://a\\=b/c\\,d value=1 1600000001\n"
+	if r.body != want {
+		t.Fatalf("line protocol\n%s\nwant\n%s", r.body, want)
+	}
+	if n := testutil.ToFloat64(SinkPointsWrittenCounterVec.WithLabelValues("influx")) - written; n != 2 {
+		t.Fatalf("written counter grew by %v, want 2", n)
+	}
+}
+
+func TestOpentsdbSinkPut(t *testing.T) {
+	ts, reqs, _ := newTestSinkServer(t, http.StatusNoContent)
+	defer ts.Close()
+	sm := newTestSinkManager(t, &SinkConfig{Name: "tsdb", Type: SinkTypeOpentsdb, URL: ts.URL + "/"})
+
+	sm.workers[0].flush(context.Background(), testSinkPoints)
+	r := <-reqs
+	if r.path != "/api/put" {
+		t.Fatalf("opentsdb path %s, want /api/put", r.path)
+	}
+	var ops []*opentsdbPoint
+	if err := json.Unmarshal([]byte(r.body), &ops); err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("got %d points, want 2", len(ops))
+	}
+	if ops[0].Metric != "ping_latency_millonseconds" || ops[0].Timestamp != 1600000000 || ops[0].Value != 12.5 ||
+		len(ops[0].Tags) != 2 || ops[0].Tags["target_region"] != "r_2" {
+		t.Fatalf("bad first point %+v", ops[0])
+	}
+	if ops[1].Metric != "raw_http_interface" || ops[1].Tags["target_addr"] != "http_//a_b/c_d" {
+		t.Fatalf("bad second point %+v", ops[1])
+	}
+}
+
+func TestSinkRetriesAndDrops(t *testing.T) {
+	ts, reqs, calls := newTestSinkServer(t, http.StatusInternalServerError)
+	defer ts.Close()
+	sm := newTestSinkManager(t, &SinkConfig{Name: "failing", Type: SinkTypeOpentsdb, URL: ts.URL, MaxRetries: 1})
+	failures := testutil.ToFloat64(SinkWriteFailuresCounterVec.WithLabelValues("failing"))
+	dropped := testutil.ToFloat64(SinkPointsDroppedCounterVec.WithLabelValues("failing"))
+
+	sm.workers[0].flush(context.Background(), testSinkPoints)
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Fatalf("got %d write attempts, want 2", n)
+	}
+	for i := 0; i < 2; i++ {
+		<-reqs
+	}
+	if n := testutil.ToFloat64(SinkWriteFailuresCounterVec.WithLabelValues("failing")) - failures; n != 2 {
+		t.Fatalf("failure counter grew by %v, want 2", n)
+	}
+	if n := testutil.ToFloat64(SinkPointsDroppedCounterVec.WithLabelValues("failing")) - dropped; n != 2 {
+		t.Fatalf("dropped counter grew by %v after exhausted retries, want 2", n)
+	}
+
+	// nothing drains the queue, the overflow is dropped
+	dropped = testutil.ToFloat64(SinkPointsDroppedCounterVec.WithLabelValues("failing"))
+	for i := 0; i < sinkQueueSize+3; i++ {
+		sm.AddAggregate("m", nil, 1)
+	}
+	if n := testutil.ToFloat64(SinkPointsDroppedCounterVec.WithLabelValues("failing")) - dropped; n != 3 {
+		t.Fatalf("dropped counter grew by %v with a full queue, want 3", n)
+	}
+}
@@ -0,0 +1,590 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"xprober/pkg/common"
+)
+
+/*
+   sla reports computed from the history store, one row per region pair and
+   per http source region and addr, plus the worst hours of the period
+*/
+
+const (
+	SlaPeriodDaily   = `daily`
+	SlaPeriodWeekly  = `weekly`
+	SlaPeriodMonthly = `monthly`
+	SlaPeriodCustom  = `custom`
+
+	SlaFormatJson = `json`
+	SlaFormatCsv  = `csv`
+	SlaFormatHtml = `html`
+
+	defaultSlaWorstIntervals = 5
+	slaIntervalSeconds       = 3600
+	slaHttpLatencyStep       = 60
+	slaCheckInterval         = 5 * time.Minute
+)
+
+var (
+	SlaR *SlaReporter
+
+	slaHttpStageMetrics = []string{
+		common.MetricsNameHttpResolvedurationMillonseconds,
+		common.MetricsNameHttpConnectDurationMillonseconds,
+		common.MetricsNameHttpTlsDurationMillonseconds,
+		common.MetricsNameHttpProcessingDurationMillonseconds,
+		common.MetricsNameHttpTransferDurationMillonseconds,
+	}
+)
+
+// SlaInterval is one of the worst hours of a row
+type SlaInterval struct {
+	Start        time.Time `json:"start"`
+	Availability *float64  `json:"availability_percent"`
+	MeanLatency  *float64  `json:"mean_latency_ms"`
+	Loss         *float64  `json:"loss_percent"`
+}
+
+// SlaRow values are nil when the period has no sample of that kind
+type SlaRow struct {
+	Type           string         `json:"type"`
+	SourceRegion   string         `json:"source_region"`
+	Target         string         `json:"target"`
+	Samples        int            `json:"samples"`
+	Availability   *float64       `json:"availability_percent"`
+	MeanLatency    *float64       `json:"mean_latency_ms"`
+	P95Latency     *float64       `json:"p95_latency_ms"`
+	Loss           *float64       `json:"loss_percent"`
+	WorstIntervals []*SlaInterval `json:"worst_intervals"`
+}
+
+type SlaReport struct {
+	Period    string    `json:"period"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Generated time.Time `json:"generated"`
+	Rows      []*SlaRow `json:"rows"`
+}
+
+type slaBucket struct {
+	availSum, latSum, lossSum float64
+	availN, latN, lossN       int
+}
+
+func ratio(sum float64, n int, scale float64) *float64 {
+	if n == 0 {
+		return nil
+	}
+	v := sum / float64(n) * scale
+	return &v
+}
+
+func (b *slaBucket) add(o *slaBucket) {
+	b.availSum += o.availSum
+	b.availN += o.availN
+	b.latSum += o.latSum
+	b.latN += o.latN
+	b.lossSum += o.lossSum
+	b.lossN += o.lossN
+}
+
+// worse orders buckets by lower availability, then higher loss and latency
+func (b *slaBucket) worse(o *slaBucket) bool {
+	ba, oa := b.availRatio(), o.availRatio()
+	if ba != oa {
+		return ba < oa
+	}
+	bl, ol := b.mean(b.lossSum, b.lossN), o.mean(o.lossSum, o.lossN)
+	if bl != ol {
+		return bl > ol
+	}
+	return b.mean(b.latSum, b.latN) > o.mean(o.latSum, o.latN)
+}
+
+func (b *slaBucket) availRatio() float64 {
+	if b.availN == 0 {
+		return 1
+	}
+	return b.availSum / float64(b.availN)
+}
+
+func (b *slaBucket) mean(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+type slaAccum struct {
+	row       *SlaRow
+	latencies []float64
+	buckets   map[int64]*slaBucket
+}
+
+func (a *slaAccum) bucket(t int64) *slaBucket {
+	k := t / slaIntervalSeconds * slaIntervalSeconds
+	b, ok := a.buckets[k]
+	if !ok {
+		b = &slaBucket{}
+		a.buckets[k] = b
+	}
+	return b
+}
+
+type slaBuilder struct {
+	rows map[string]*slaAccum
+}
+
+func (sb *slaBuilder) accum(typ, source, target string) *slaAccum {
+	key := typ + MetricUniqueSeparator + source + MetricUniqueSeparator + target
+	a, ok := sb.rows[key]
+	if !ok {
+		a = &slaAccum{
+			row:     &SlaRow{Type: typ, SourceRegion: source, Target: target},
+			buckets: make(map[int64]*slaBucket),
+		}
+		sb.rows[key] = a
+	}
+	return a
+}
+
+func (sb *slaBuilder) query(metricName string, start, end time.Time, step int64, fn func(a *slaAccum, t int64, v float64)) error {
+	// end is exclusive for reports
+	series, err := QueryHistory(metricName, nil, start, end.Add(-time.Second), step)
+	if err != nil {
+		return err
+	}
+	for _, s := range series {
+		typ, target := "icmp", s.Labels["target_region"]
+		if addr, ok := s.Labels["addr"]; ok {
+			typ, target = "http", addr
+		}
+		a := sb.accum(typ, s.Labels["source_region"], target)
+		for _, p := range s.Points {
+			fn(a, p.T, p.V)
+		}
+	}
+	return nil
+}
+
+// BuildSlaReport computes the report of [start, end) from the history store
+func BuildSlaReport(period string, start, end time.Time, worstN int) (*SlaReport, error) {
+	if HistoryDB == nil {
+		return nil, fmt.Errorf("history is disabled")
+	}
+	sb := &slaBuilder{rows: make(map[string]*slaAccum)}
+
+	err := sb.query(common.MetricsNamePingTargetSuccess, start, end, 0, func(a *slaAccum, t int64, v float64) {
+		b := a.bucket(t)
+		b.availN++
+		a.row.Samples++
+		if v > 0 {
+			b.availSum++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// -1 marks probes that failed to run, they count against availability only
+	err = sb.query(common.MetricsNamePingLatency, start, end, 0, func(a *slaAccum, t int64, v float64) {
+		if v < 0 {
+			return
+		}
+		b := a.bucket(t)
+		b.latSum += v
+		b.latN++
+		a.latencies = append(a.latencies, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = sb.query(common.MetricsNamePingPackageDrop, start, end, 0, func(a *slaAccum, t int64, v float64) {
+		if v < 0 {
+			return
+		}
+		b := a.bucket(t)
+		b.lossSum += v
+		b.lossN++
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = sb.query(common.MetricsNameHttpInterfaceSuccess, start, end, 0, func(a *slaAccum, t int64, v float64) {
+		b := a.bucket(t)
+		b.availSum += v
+		b.availN++
+		a.row.Samples++
+	})
+	if err != nil {
+		return nil, err
+	}
+	// http latency is the sum of the stage means per minute
+	totals := make(map[*slaAccum]map[int64]float64)
+	for _, m := range slaHttpStageMetrics {
+		err = sb.query(m, start, end, slaHttpLatencyStep, func(a *slaAccum, t int64, v float64) {
+			if totals[a] == nil {
+				totals[a] = make(map[int64]float64)
+			}
+			totals[a][t] += v
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for a, byT := range totals {
+		for t, v := range byT {
+			b := a.bucket(t)
+			b.latSum += v
+			b.latN++
+			a.latencies = append(a.latencies, v)
+		}
+	}
+
+	report := &SlaReport{Period: period, Start: start, End: end, Generated: time.Now(), Rows: make([]*SlaRow, 0, len(sb.rows))}
+	for _, a := range sb.rows {
+		report.Rows = append(report.Rows, a.finish(worstN))
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		ri, rj := report.Rows[i], report.Rows[j]
+		if ri.Type != rj.Type {
+			return ri.Type < rj.Type
+		}
+		if ri.SourceRegion != rj.SourceRegion {
+			return ri.SourceRegion < rj.SourceRegion
+		}
+		return ri.Target < rj.Target
+	})
+	return report, nil
+}
+
+func (a *slaAccum) finish(worstN int) *SlaRow {
+	total := &slaBucket{}
+	keys := make([]int64, 0, len(a.buckets))
+	for k, b := range a.buckets {
+		total.add(b)
+		keys = append(keys, k)
+	}
+	r := a.row
+	r.Availability = ratio(total.availSum, total.availN, 100)
+	r.MeanLatency = ratio(total.latSum, total.latN, 1)
+	r.Loss = ratio(total.lossSum, total.lossN, 1)
+	if len(a.latencies) > 0 {
+		sort.Float64s(a.latencies)
+		p95 := a.latencies[int(math.Ceil(0.95*float64(len(a.latencies))))-1]
+		r.P95Latency = &p95
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		bi, bj := a.buckets[keys[i]], a.buckets[keys[j]]
+		if bi.worse(bj) != bj.worse(bi) {
+			return bi.worse(bj)
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > worstN {
+		keys = keys[:worstN]
+	}
+	r.WorstIntervals = make([]*SlaInterval, 0, len(keys))
+	for _, k := range keys {
+		b := a.buckets[k]
+		r.WorstIntervals = append(r.WorstIntervals, &SlaInterval{
+			Start:        time.Unix(k, 0),
+			Availability: ratio(b.availSum, b.availN, 100),
+			MeanLatency:  ratio(b.latSum, b.latN, 1),
+			Loss:         ratio(b.lossSum, b.lossN, 1),
+		})
+	}
+	return r
+}
+
+// SlaPeriodRange returns the last complete period before now in local time,
+// weeks start on monday
+func SlaPeriodRange(period string, now time.Time) (start, end time.Time, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case SlaPeriodDaily:
+		end = today
+		start = end.AddDate(0, 0, -1)
+	case SlaPeriodWeekly:
+		end = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		start = end.AddDate(0, 0, -7)
+	case SlaPeriodMonthly:
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		start = end.AddDate(0, -1, 0)
+	default:
+		err = fmt.Errorf("unknown sla period: %s", period)
+	}
+	return
+}
+
+func fmtOptional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 3, 64)
+}
+
+func fmtInterval(iv *SlaInterval) string {
+	return fmt.Sprintf("%s availability=%s latency=%s loss=%s", iv.Start.Format(time.RFC3339),
+		fmtOptional(iv.Availability), fmtOptional(iv.MeanLatency), fmtOptional(iv.Loss))
+}
+
+func (r *SlaReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"type", "source_region", "target", "samples", "availability_percent", "mean_latency_ms", "p95_latency_ms", "loss_percent", "worst_intervals"})
+	for _, row := range r.Rows {
+		worst := make([]string, 0, len(row.WorstIntervals))
+		for _, iv := range row.WorstIntervals {
+			worst = append(worst, fmtInterval(iv))
+		}
+		cw.Write([]string{row.Type, row.SourceRegion, row.Target, strconv.Itoa(row.Samples),
+			fmtOptional(row.Availability), fmtOptional(row.MeanLatency), fmtOptional(row.P95Latency), fmtOptional(row.Loss),
+			strings.Join(worst, "; ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var slaHtmlTemplate = template.Must(template.New("sla").Funcs(template.FuncMap{
+	"opt": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return strconv.FormatFloat(*v, 'f', 3, 64)
+	},
+	"ts": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>xprober {{ .Period }} sla report</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #f0f0f0; }
+td.l { text-align: left; }
+</style>
+</head>
+<body>
+<h2>xprober {{ .Period }} sla report</h2>
+<p>{{ ts .Start }} - {{ ts .End }}, generated {{ ts .Generated }}</p>
+<table>
+<tr><th>type</th><th>source region</th><th>target</th><th>samples</th><th>availability %</th><th>mean latency ms</th><th>p95 latency ms</th><th>loss %</th><th>worst intervals</th></tr>
+{{- range .Rows }}
+<tr>
+<td class="l">{{ .Type }}</td><td class="l">{{ .SourceRegion }}</td><td class="l">{{ .Target }}</td><td>{{ .Samples }}</td>
+<td>{{ opt .Availability }}</td><td>{{ opt .MeanLatency }}</td><td>{{ opt .P95Latency }}</td><td>{{ opt .Loss }}</td>
+<td class="l">{{ range .WorstIntervals }}{{ ts .Start }}: {{ opt .Availability }}% / {{ opt .MeanLatency }}ms / {{ opt .Loss }}%<br>{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+func (r *SlaReport) WriteHtml(w io.Writer) error {
+	return slaHtmlTemplate.Execute(w, r)
+}
+
+// Render writes the report in one of the supported formats
+func (r *SlaReport) Render(w io.Writer, format string) error {
+	switch format {
+	case SlaFormatCsv:
+		return r.WriteCsv(w)
+	case SlaFormatHtml:
+		return r.WriteHtml(w)
+	case SlaFormatJson:
+		return json.NewEncoder(w).Encode(r)
+	}
+	return fmt.Errorf("unknown sla report format: %s", format)
+}
+
+var slaContentTypes = map[string]string{
+	SlaFormatJson: "application/json",
+	SlaFormatCsv:  "text/csv; charset=utf-8",
+	SlaFormatHtml: "text/html; charset=utf-8",
+}
+
+// slaReportHandler serves /api/v1/reports/sla?period=daily&format=csv, with
+// start and end instead of period for a custom range
+func slaReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = SlaFormatJson
+	}
+	contentType, ok := slaContentTypes[format]
+	if !ok {
+		writeJsonError(w, http.StatusBadRequest, "unknown format: "+format)
+		return
+	}
+	worstN := defaultSlaWorstIntervals
+	if s := q.Get("worst"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeJsonError(w, http.StatusBadRequest, "invalid worst: "+s)
+			return
+		}
+		worstN = n
+	}
+
+	period := q.Get("period")
+	var start, end time.Time
+	var err error
+	if q.Get("start") != "" || q.Get("end") != "" {
+		period = SlaPeriodCustom
+		if end, err = parseTime(q.Get("end"), time.Now()); err == nil {
+			start, err = parseTime(q.Get("start"), end.AddDate(0, 0, -1))
+		}
+		if err == nil && !end.After(start) {
+			err = fmt.Errorf("end must be after start")
+		}
+	} else {
+		if period == "" {
+			period = SlaPeriodDaily
+		}
+		start, end, err = SlaPeriodRange(period, time.Now())
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := BuildSlaReport(period, start, end, worstN)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var buf bytes.Buffer
+	if err := report.Render(&buf, format); err != nil {
+		writeJsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if format != SlaFormatJson {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", slaReportFileName(period, start, format)))
+	}
+	w.Write(buf.Bytes())
+}
+
+func slaReportFileName(period string, start time.Time, format string) string {
+	return fmt.Sprintf("sla-%s-%s.%s", period, start.Format("2006-01-02"), format)
+}
+
+// SlaReporter writes the reports of every finished period to a directory
+type SlaReporter struct {
+	logger log.Logger
+	cfg    *SlaReportConfig
+}
+
+func NewSlaReporter(logger log.Logger, cfg *SlaReportConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if HistoryDB == nil {
+		return fmt.Errorf("sla_reports needs history to be configured")
+	}
+	if cfg.Dir == "" {
+		return fmt.Errorf("sla_reports dir is required")
+	}
+	if len(cfg.Periods) == 0 {
+		cfg.Periods = []string{SlaPeriodDaily, SlaPeriodWeekly, SlaPeriodMonthly}
+	}
+	if len(cfg.Formats) == 0 {
+		cfg.Formats = []string{SlaFormatCsv, SlaFormatHtml}
+	}
+	if cfg.WorstIntervals <= 0 {
+		cfg.WorstIntervals = defaultSlaWorstIntervals
+	}
+	for _, p := range cfg.Periods {
+		if _, _, err := SlaPeriodRange(p, time.Now()); err != nil {
+			return err
+		}
+	}
+	for _, f := range cfg.Formats {
+		if _, ok := slaContentTypes[f]; !ok {
+			return fmt.Errorf("unknown sla report format: %s", f)
+		}
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return err
+	}
+	SlaR = &SlaReporter{logger: log.With(logger, "component", "sla"), cfg: cfg}
+	return nil
+}
+
+// Run generates every missing report of the last finished periods, the file
+// name carries the period start so a restart never writes one twice
+func (sr *SlaReporter) Run(ctx context.Context) error {
+	if sr == nil {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(slaCheckInterval)
+	level.Info(sr.logger).Log("msg", "SlaReporter start....", "dir", sr.cfg.Dir)
+	defer ticker.Stop()
+	sr.writeMissing()
+	for {
+		select {
+		case <-ticker.C:
+			sr.writeMissing()
+		case <-ctx.Done():
+			level.Info(sr.logger).Log("msg", "SlaReporter exit....")
+			return nil
+		}
+	}
+}
+
+func (sr *SlaReporter) writeMissing() {
+	now := time.Now()
+	for _, period := range sr.cfg.Periods {
+		start, end, _ := SlaPeriodRange(period, now)
+		var missing []string
+		for _, f := range sr.cfg.Formats {
+			if _, err := os.Stat(filepath.Join(sr.cfg.Dir, slaReportFileName(period, start, f))); os.IsNotExist(err) {
+				missing = append(missing, f)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		report, err := BuildSlaReport(period, start, end, sr.cfg.WorstIntervals)
+		if err != nil {
+			level.Error(sr.logger).Log("msg", "build sla report error", "period", period, "err", err)
+			continue
+		}
+		for _, f := range missing {
+			var buf bytes.Buffer
+			if err := report.Render(&buf, f); err != nil {
+				level.Error(sr.logger).Log("msg", "render sla report error", "period", period, "format", f, "err", err)
+				continue
+			}
+			name := filepath.Join(sr.cfg.Dir, slaReportFileName(period, start, f))
+			if err := writeFileAtomic(name, buf.Bytes()); err != nil {
+				level.Error(sr.logger).Log("msg", "write sla report error", "file", name, "err", err)
+				continue
+			}
+			level.Info(sr.logger).Log("msg", "sla report written", "file", name, "rows", len(report.Rows))
+		}
+	}
+}
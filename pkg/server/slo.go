@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+)
+
+/*
+   slo tracking on the processed region-pair and http values. a window slo
+   counts fixed windows whose mean compares true against the threshold, a
+   ratio slo treats the metric as a 0..1 success ratio. outcomes are kept per
+   hour for the compliance period and per window for the burn rate windows
+*/
+
+const (
+	SloSliWindow = `window`
+	SloSliRatio  = `ratio`
+
+	defaultSloWindow = time.Minute
+	defaultSloPeriod = 30 * 24 * time.Hour
+	sloEvalInterval  = 30 * time.Second
+	sloHourSeconds   = 3600
+)
+
+var (
+	SloM *SloManager
+
+	sloBurnWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+	SloObjectiveGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameSloObjective,
+		Help: "objective of the slo as a ratio",
+	}, []string{"slo"})
+	SloSliGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameSloSli,
+		Help: "good ratio over the slo period",
+	}, []string{"slo", "source_region", "target"})
+	SloErrorBudgetGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameSloErrorBudgetRemaining,
+		Help: "remaining error budget of the slo period as a ratio, negative when exhausted",
+	}, []string{"slo", "source_region", "target"})
+	SloBurnRateGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: common.MetricsNameSloBurnRate,
+		Help: "error budget burn rate over the window, 1 spends the budget exactly in the period",
+	}, []string{"slo", "source_region", "target", "window"})
+)
+
+type sloCount struct {
+	good, total float64
+}
+
+type sloEvent struct {
+	t int64
+	sloCount
+}
+
+type sloSeries struct {
+	sourceRegion, target string
+
+	winStart  int64
+	winSum    float64
+	winN      int
+	winFailed int
+
+	recent []sloEvent
+	hours  map[int64]*sloCount
+}
+
+type compiledSlo struct {
+	*SloConfig
+	matchers  map[string]*regexp.Regexp
+	compare   func(a, b float64) bool
+	windowSec int64
+	periodSec int64
+	objective float64
+
+	series map[string]*sloSeries
+}
+
+// SloStatus is the current state of one slo and series
+type SloStatus struct {
+	Slo                  string             `json:"slo"`
+	SourceRegion         string             `json:"source_region"`
+	Target               string             `json:"target"`
+	Objective            float64            `json:"objective"`
+	Sli                  float64            `json:"sli"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"`
+	BurnRates            map[string]float64 `json:"burn_rates"`
+	Good                 float64            `json:"good"`
+	Total                float64            `json:"total"`
+}
+
+type SloManager struct {
+	logger log.Logger
+
+	mu   sync.Mutex
+	slos []*compiledSlo
+}
+
+func NewSloManager(logger log.Logger, cfgs []*SloConfig) error {
+	if len(cfgs) == 0 {
+		return nil
+	}
+	sm := &SloManager{logger: log.With(logger, "component", "slo")}
+	for _, c := range cfgs {
+		cs, err := compileSlo(c)
+		if err != nil {
+			return err
+		}
+		sm.slos = append(sm.slos, cs)
+		SloObjectiveGaugeVec.WithLabelValues(c.Name).Set(cs.objective)
+	}
+	sm.backfill(time.Now())
+	SloM = sm
+	return nil
+}
+
+func compileSlo(c *SloConfig) (*compiledSlo, error) {
+	if c.Name == "" || c.Metric == "" {
+		return nil, fmt.Errorf("slo needs name and metric")
+	}
+	if c.Objective <= 0 || c.Objective >= 100 {
+		return nil, fmt.Errorf("slo %s: objective must be a percent between 0 and 100", c.Name)
+	}
+	cs := &compiledSlo{
+		SloConfig: c,
+		objective: c.Objective / 100,
+		windowSec: int64(time.Duration(c.Window).Seconds()),
+		periodSec: int64(time.Duration(c.Period).Seconds()),
+		series:    make(map[string]*sloSeries),
+	}
+	if cs.windowSec <= 0 {
+		cs.windowSec = int64(defaultSloWindow.Seconds())
+	}
+	if cs.periodSec <= 0 {
+		cs.periodSec = int64(defaultSloPeriod.Seconds())
+	}
+	switch c.Sli {
+	case "", SloSliWindow:
+		c.Sli = SloSliWindow
+		f, ok := compareFuncs[c.Op]
+		if !ok {
+			return nil, fmt.Errorf("slo %s: unknown op %q", c.Name, c.Op)
+		}
+		cs.compare = f
+	case SloSliRatio:
+	default:
+		return nil, fmt.Errorf("slo %s: unknown sli %q", c.Name, c.Sli)
+	}
+	matchers, err := compileLabelMatchers(c.SourceRegion, c.TargetRegion, c.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("slo %s: %v", c.Name, err)
+	}
+	cs.matchers = matchers
+	return cs, nil
+}
+
+// backfill replays the history store so a restart keeps the period
+func (sm *SloManager) backfill(now time.Time) {
+	if HistoryDB == nil {
+		return
+	}
+	for _, cs := range sm.slos {
+		start := now.Add(-time.Duration(cs.periodSec) * time.Second)
+		series, err := QueryHistory(cs.Metric, nil, start, now, 0)
+		if err != nil {
+			level.Error(sm.logger).Log("msg", "slo backfill error", "slo", cs.Name, "err", err)
+			continue
+		}
+		samples := 0
+		for _, s := range series {
+			if !matchLabels(cs.matchers, s.Labels) {
+				continue
+			}
+			for _, p := range s.Points {
+				cs.observe(s.Labels, p.T, p.V)
+				samples++
+			}
+		}
+		level.Info(sm.logger).Log("msg", "slo backfilled from history", "slo", cs.Name, "samples", samples)
+	}
+}
+
+func (cs *compiledSlo) observe(labels map[string]string, t int64, value float64) {
+	target := labels["target_region"]
+	if addr, ok := labels["addr"]; ok {
+		target = addr
+	}
+	key := labels["source_region"] + MetricUniqueSeparator + target
+	s, ok := cs.series[key]
+	if !ok {
+		s = &sloSeries{sourceRegion: labels["source_region"], target: target, hours: make(map[int64]*sloCount)}
+		cs.series[key] = s
+	}
+	w := t / cs.windowSec * cs.windowSec
+	if w < s.winStart {
+		return
+	}
+	if w != s.winStart {
+		cs.closeWindow(s)
+		s.winStart = w
+	}
+	// -1 marks a failed probe, it makes a window or ratio sample bad
+	if value < 0 {
+		s.winFailed++
+		return
+	}
+	s.winSum += value
+	s.winN++
+}
+
+func (cs *compiledSlo) closeWindow(s *sloSeries) {
+	if s.winN == 0 && s.winFailed == 0 {
+		return
+	}
+	var c sloCount
+	switch cs.Sli {
+	case SloSliRatio:
+		c.total = float64(s.winN + s.winFailed)
+		c.good = math.Min(s.winSum, float64(s.winN))
+	default:
+		c.total = 1
+		if s.winN > 0 && cs.compare(s.winSum/float64(s.winN), cs.Threshold) {
+			c.good = 1
+		}
+	}
+	s.recent = append(s.recent, sloEvent{t: s.winStart, sloCount: c})
+	h := s.winStart / sloHourSeconds * sloHourSeconds
+	hc, ok := s.hours[h]
+	if !ok {
+		hc = &sloCount{}
+		s.hours[h] = hc
+	}
+	hc.good += c.good
+	hc.total += c.total
+	s.winSum, s.winN, s.winFailed = 0, 0, 0
+}
+
+// Observe feeds a processed value to every matching slo
+func (sm *SloManager) Observe(metricName string, labels prometheus.Labels, value float64) {
+	if sm == nil {
+		return
+	}
+	now := time.Now().Unix()
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for _, cs := range sm.slos {
+		if cs.Metric == metricName && matchLabels(cs.matchers, labels) {
+			cs.observe(labels, now, value)
+		}
+	}
+}
+
+func (sm *SloManager) Run(ctx context.Context) error {
+	if sm == nil {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(sloEvalInterval)
+	level.Info(sm.logger).Log("msg", "SloManager start....", "slos", len(sm.slos))
+	defer ticker.Stop()
+	sm.eval(time.Now())
+	for {
+		select {
+		case <-ticker.C:
+			sm.eval(time.Now())
+		case <-ctx.Done():
+			level.Info(sm.logger).Log("msg", "SloManager exit....")
+			return nil
+		}
+	}
+}
+
+// eval closes finished windows, prunes old outcomes and exports the gauges
+func (sm *SloManager) eval(now time.Time) {
+	for _, st := range sm.Status(now) {
+		SloSliGaugeVec.WithLabelValues(st.Slo, st.SourceRegion, st.Target).Set(st.Sli)
+		SloErrorBudgetGaugeVec.WithLabelValues(st.Slo, st.SourceRegion, st.Target).Set(st.ErrorBudgetRemaining)
+		for w, v := range st.BurnRates {
+			SloBurnRateGaugeVec.WithLabelValues(st.Slo, st.SourceRegion, st.Target, w).Set(v)
+		}
+	}
+}
+
+// Status returns every slo series, windows are closed once a full collect
+// interval has passed their end so late values still count
+func (sm *SloManager) Status(now time.Time) []*SloStatus {
+	if sm == nil {
+		return nil
+	}
+	ts := now.Unix()
+	maxBurn := int64(sloBurnWindows[len(sloBurnWindows)-1].Seconds())
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	var res []*SloStatus
+	for _, cs := range sm.slos {
+		for _, s := range cs.series {
+			if s.winStart > 0 && ts >= s.winStart+cs.windowSec+int64(MetricCollectInterval.Seconds()) {
+				cs.closeWindow(s)
+				// anything later for the closed window is dropped by observe
+				s.winStart += cs.windowSec
+			}
+			i := 0
+			for i < len(s.recent) && s.recent[i].t < ts-maxBurn {
+				i++
+			}
+			s.recent = s.recent[i:]
+			var period sloCount
+			for h, c := range s.hours {
+				if h+sloHourSeconds <= ts-cs.periodSec {
+					delete(s.hours, h)
+					continue
+				}
+				period.good += c.good
+				period.total += c.total
+			}
+			if period.total == 0 {
+				continue
+			}
+			st := &SloStatus{
+				Slo:          cs.Name,
+				SourceRegion: s.sourceRegion,
+				Target:       s.target,
+				Objective:    cs.objective,
+				Good:         period.good,
+				Total:        period.total,
+				Sli:          period.good / period.total,
+				BurnRates:    make(map[string]float64),
+			}
+			budget := 1 - cs.objective
+			st.ErrorBudgetRemaining = 1 - (1-st.Sli)/budget
+			for _, bw := range sloBurnWindows {
+				var c sloCount
+				for _, e := range s.recent {
+					if e.t >= ts-int64(bw.Seconds()) {
+						c.good += e.good
+						c.total += e.total
+					}
+				}
+				if c.total > 0 {
+					st.BurnRates[fmtBurnWindow(bw)] = (1 - c.good/c.total) / budget
+				}
+			}
+			res = append(res, st)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Slo != res[j].Slo {
+			return res[i].Slo < res[j].Slo
+		}
+		if res[i].SourceRegion != res[j].SourceRegion {
+			return res[i].SourceRegion < res[j].SourceRegion
+		}
+		return res[i].Target < res[j].Target
+	})
+	return res
+}
+
+func fmtBurnWindow(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// sloHandler serves /api/v1/slos with the current status of every slo series
+func sloHandler(w http.ResponseWriter, r *http.Request) {
+	if SloM == nil {
+		writeJsonError(w, http.StatusNotFound, "no slo configured")
+		return
+	}
+	res := SloM.Status(time.Now())
+	if res == nil {
+		res = []*SloStatus{}
+	}
+	writeJson(w, http.StatusOK, res)
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"xprober/pkg/common"
+	"xprober/pkg/pb"
+)
+
+const (
+	StreamTypeNats = `nats`
+
+	StreamEncodingJson     = `json`
+	StreamEncodingProtobuf = `protobuf`
+
+	defaultStreamSubject       = `xprober.results`
+	defaultStreamBatchSize     = 100
+	defaultStreamFlushInterval = time.Second
+	defaultStreamQueueSize     = 10000
+)
+
+var (
+	StreamM *ResultStreamer
+
+	StreamResultsPublishedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameStreamResultsPublished,
+		Help: "raw results published to the message bus",
+	})
+	StreamResultsDroppedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameStreamResultsDropped,
+		Help: "raw results dropped because the stream queue was full or publish failed",
+	})
+	StreamPublishFailuresCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: common.MetricsNameStreamPublishFailures,
+		Help: "failed publish calls to the message bus",
+	})
+	StreamQueueLengthGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: common.MetricsNameStreamQueueLength,
+		Help: "raw results waiting in the stream queue",
+	})
+)
+
+// ResultStreamer publishes every raw ProberResultOne to a message bus,
+// subjects are partitioned by region pair: <subject>.<source_region>.<target_region>
+type ResultStreamer struct {
+	logger log.Logger
+	cfg    *StreamConfig
+	conn   *nats.Conn
+	queue  chan *pb.ProberResultOne
+}
+
+func NewResultStreamer(logger log.Logger, cfg *StreamConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Type != StreamTypeNats {
+		return fmt.Errorf("unknown result stream type: %s", cfg.Type)
+	}
+	switch cfg.Encoding {
+	case "":
+		cfg.Encoding = StreamEncodingJson
+	case StreamEncodingJson, StreamEncodingProtobuf:
+	default:
+		return fmt.Errorf("unknown result stream encoding: %s", cfg.Encoding)
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = defaultStreamSubject
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultStreamQueueSize
+	}
+
+	logger = log.With(logger, "stream", cfg.Type)
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("xprober-server"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			level.Warn(logger).Log("msg", "result stream disconnected", "err", err)
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			level.Info(logger).Log("msg", "result stream reconnected", "url", c.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	StreamM = &ResultStreamer{
+		logger: logger,
+		cfg:    cfg,
+		conn:   conn,
+		queue:  make(chan *pb.ProberResultOne, queueSize),
+	}
+	return nil
+}
+
+// Add queues a raw result without blocking the rpc handler
+func (rs *ResultStreamer) Add(prr *pb.ProberResultOne) {
+	if rs == nil {
+		return
+	}
+	select {
+	case rs.queue <- prr:
+	default:
+		StreamResultsDroppedCounter.Inc()
+	}
+}
+
+func (rs *ResultStreamer) Run(ctx context.Context) error {
+	if rs == nil {
+		<-ctx.Done()
+		return nil
+	}
+	batchSize := rs.cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+	interval := time.Duration(rs.cfg.FlushInterval)
+	if interval <= 0 {
+		interval = defaultStreamFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	level.Info(rs.logger).Log("msg", "ResultStreamer start....", "subject", rs.cfg.Subject, "encoding", rs.cfg.Encoding)
+	defer ticker.Stop()
+
+	batch := make([]*pb.ProberResultOne, 0, batchSize)
+	for {
+		select {
+		case prr := <-rs.queue:
+			batch = append(batch, prr)
+			if len(batch) >= batchSize {
+				rs.publish(batch)
+				batch = make([]*pb.ProberResultOne, 0, batchSize)
+			}
+		case <-ticker.C:
+			StreamQueueLengthGauge.Set(float64(len(rs.queue)))
+			if len(batch) > 0 {
+				rs.publish(batch)
+				batch = make([]*pb.ProberResultOne, 0, batchSize)
+			}
+		case <-ctx.Done():
+			if len(batch) > 0 {
+				rs.publish(batch)
+			}
+			rs.conn.FlushTimeout(5 * time.Second)
+			rs.conn.Close()
+			level.Info(rs.logger).Log("msg", "ResultStreamer exit....")
+			return nil
+		}
+	}
+}
+
+// publish groups the batch by region pair and sends one message per subject
+func (rs *ResultStreamer) publish(batch []*pb.ProberResultOne) {
+	groups := make(map[string][]*pb.ProberResultOne)
+	for _, prr := range batch {
+		subj := rs.subject(prr)
+		groups[subj] = append(groups[subj], prr)
+	}
+	for subj, prs := range groups {
+		data, err := rs.encode(prs)
+		if err != nil {
+			level.Error(rs.logger).Log("msg", "encode results error", "subject", subj, "err", err)
+			StreamResultsDroppedCounter.Add(float64(len(prs)))
+			continue
+		}
+		if err := rs.conn.Publish(subj, data); err != nil {
+			level.Error(rs.logger).Log("msg", "publish results error", "subject", subj, "err", err)
+			StreamPublishFailuresCounter.Inc()
+			StreamResultsDroppedCounter.Add(float64(len(prs)))
+			continue
+		}
+		StreamResultsPublishedCounter.Add(float64(len(prs)))
+	}
+}
+
+func (rs *ResultStreamer) subject(prr *pb.ProberResultOne) string {
+	return rs.cfg.Subject + "." + streamToken(prr.SourceRegion) + "." + streamToken(prr.TargetRegion)
+}
+
+func (rs *ResultStreamer) encode(prs []*pb.ProberResultOne) ([]byte, error) {
+	if rs.cfg.Encoding == StreamEncodingProtobuf {
+		req := &pb.ProberResultPushRequest{ProberResults: prs}
+		return req.Marshal()
+	}
+	return json.Marshal(prs)
+}
+
+// nats subject tokens must not be empty or contain `.`, `*`, `>` and whitespace
+func streamToken(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+
+	"xprober/pkg/pb"
+)
+
+func runNatsServer() *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	return natsserver.RunServer(&opts)
+}
+
+func newTestStreamer(t *testing.T, url string, cfg StreamConfig) *ResultStreamer {
+	cfg.Type = StreamTypeNats
+	cfg.URL = url
+	if err := NewResultStreamer(log.NewNopLogger(), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	rs := StreamM
+	StreamM = nil
+	return rs
+}
+
+func TestResultStreamerSubjectsAndBatches(t *testing.T) {
+	ns := runNatsServer()
+	defer ns.Shutdown()
+
+	sub, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	msgs := make(chan *nats.Msg, 16)
+	if _, err := sub.ChanSubscribe("xprober.results.>", msgs); err != nil {
+		t.Fatal(err)
+	}
+	sub.Flush()
+
+	// only full batches are published before ctx is done
+	rs := newTestStreamer(t, ns.ClientURL(), StreamConfig{BatchSize: 3, FlushInterval: model.Duration(time.Hour)})
+	published := testutil.ToFloat64(StreamResultsPublishedCounter)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		rs.Run(ctx)
+		close(done)
+	}()
+
+	for _, regions := range [][2]string{
+		{"r1", "r2"}, {"r1", "r.3"}, {"r1", "r2"},
+		{"r1", "r2"}, {"r1", "r2"}, {"r2", "r1"},
+	} {
+		rs.Add(&pb.ProberResultOne{SourceRegion: regions[0], TargetRegion: regions[1], TargetAddr: "1.1.1.1"})
+	}
+
+	got := make(map[string][]int)
+	for i := 0; i < 4; i++ {
+		select {
+		case m := <-msgs:
+			var prs []*pb.ProberResultOne
+			if err := json.Unmarshal(m.Data, &prs); err != nil {
+				t.Fatalf("decode %s: %v", m.Subject, err)
+			}
+			for _, prr := range prs {
+				if rs.subject(prr) != m.Subject {
+					t.Fatalf("result %s->%s published on %s", prr.SourceRegion, prr.TargetRegion, m.Subject)
+				}
+			}
+			got[m.Subject] = append(got[m.Subject], len(prs))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got messages %v, want 4", got)
+		}
+	}
+	cancel()
+	<-done
+
+	// two batches of three, one message per region pair and batch
+	want := map[string][]int{
+		"xprober.results.r1.r2":  {2, 2},
+		"xprober.results.r1.r_3": {1},
+		"xprober.results.r2.r1":  {1},
+	}
+	for subj, sizes := range want {
+		if len(got[subj]) != len(sizes) {
+			t.Fatalf("subject %s got batches %v, want %v", subj, got[subj], sizes)
+		}
+		for i := range sizes {
+			if got[subj][i] != sizes[i] {
+				t.Fatalf("subject %s got batches %v, want %v", subj, got[subj], sizes)
+			}
+		}
+	}
+	if n := testutil.ToFloat64(StreamResultsPublishedCounter) - published; n != 6 {
+		t.Fatalf("published counter grew by %v, want 6", n)
+	}
+}
+
+func TestResultStreamerDropsWhenQueueFull(t *testing.T) {
+	ns := runNatsServer()
+	defer ns.Shutdown()
+
+	rs := newTestStreamer(t, ns.ClientURL(), StreamConfig{QueueSize: 2})
+	defer rs.conn.Close()
+	dropped := testutil.ToFloat64(StreamResultsDroppedCounter)
+	// not running, nothing drains the queue
+	for i := 0; i < 5; i++ {
+		rs.Add(&pb.ProberResultOne{SourceRegion: "r1", TargetRegion: "r2"})
+	}
+	if n := testutil.ToFloat64(StreamResultsDroppedCounter) - dropped; n != 3 {
+		t.Fatalf("dropped counter grew by %v, want 3", n)
+	}
+	if len(rs.queue) != 2 {
+		t.Fatalf("queue holds %d results, want 2", len(rs.queue))
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 	"context"
 
@@ -21,11 +22,15 @@ var (
 	IcmpRegionProberMap  = sync.Map{}
 	OtherRegionProberMap = sync.Map{}
 	AgentIpRegionMap     = sync.Map{}
+
+	TFM *TargetFlushManager
 )
 
 type TargetFlushManager struct {
 	Logger     log.Logger
 	ConfigFile string
+	mux        sync.Mutex
+	lastConfig *Config
 }
 
 func rangeIcmpMap() {
@@ -39,133 +44,155 @@ func rangeIcmpMap() {
 	IcmpRegionProberMap.Range(f)
 }
 
-func (t *TargetFlushManager) flushAgentIpIntoGlobalMap() {
+// regionTargetSet collects the targets of one pool, keeping the first seen order
+type regionTargetSet struct {
+	icmp  map[string][]string
+	other map[string][]*pb.Targets
+}
+
+func newRegionTargetSet() *regionTargetSet {
+	return &regionTargetSet{
+		icmp:  make(map[string][]string),
+		other: make(map[string][]*pb.Targets),
+	}
+}
+
+func (rs *regionTargetSet) add(proberType, region string, targets []string) {
+	switch proberType {
+	case "icmp":
+		rs.icmp[region] = appendUniq(rs.icmp[region], targets...)
+	default:
+		rs.other[region] = append(rs.other[region], &pb.Targets{
+			Region:     region,
+			ProberType: proberType,
+			Target:     targets,
+		})
+	}
+}
+
+func appendUniq(dst []string, src ...string) []string {
+	seen := make(map[string]bool, len(dst))
+	for _, d := range dst {
+		seen[d] = true
+	}
+	for _, s := range src {
+		if !seen[s] {
+			seen[s] = true
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+// store replaces the global region maps, regions no longer present are removed
+func (rs *regionTargetSet) store() {
+	for region, ips := range rs.icmp {
+		IcmpRegionProberMap.Store(region, &pb.Targets{
+			Region:     region,
+			ProberType: "icmp",
+			Target:     ips,
+		})
+	}
+	for region, tgs := range rs.other {
+		OtherRegionProberMap.Store(region, tgs)
+	}
+	IcmpRegionProberMap.Range(func(k, v interface{}) bool {
+		if _, ok := rs.icmp[k.(string)]; !ok {
+			IcmpRegionProberMap.Delete(k)
+		}
+		return true
+	})
+	OtherRegionProberMap.Range(func(k, v interface{}) bool {
+		if _, ok := rs.other[k.(string)]; !ok {
+			OtherRegionProberMap.Delete(k)
+		}
+		return true
+	})
+}
+
+func (t *TargetFlushManager) flushAgentIpIntoGlobalMap(rs *regionTargetSet) {
 	level.Info(t.Logger).Log("msg", "flushAgentIpIntoGlobalMap run....")
-	tmpM := make(map[string][]string)
 
 	f := func(k, v interface{}) bool {
 		ip := k.(string)
 		region := v.(string)
-		tmpM[region] = append(tmpM[region], ip)
+		rs.add("icmp", region, []string{ip})
 
 		return true
 	}
 	AgentIpRegionMap.Range(f)
-	for region, ips := range tmpM {
-		tNew := &pb.Targets{}
-		tNew.Region = region
-		tNew.ProberType = "icmp"
-		tNew.Target = ips
-
-		preData, loaded := IcmpRegionProberMap.LoadOrStore(region, tNew)
-		preDataN := preData.(*pb.Targets)
-		if loaded {
-			thisT := tNew.Target
-			originT := preDataN.Target
-			thisTM := make(map[string]string)
-			for _, tt := range thisT {
-				thisTM[tt] = tt
-			}
-			for _, tt := range originT {
-				if _, exists := thisTM[tt]; exists == false {
-					thisT = append(thisT, tt)
-				}
-			}
-			a := &pb.Targets{
-				Region:     region,
-				ProberType: "icmp",
-				Target:     thisT,
-			}
-			IcmpRegionProberMap.Store(region, a)
-		}
-
-	}
 	//rangeIcmpMap()
 
 }
 
 func NewTargetFlushManager(logger log.Logger, configFile string) *TargetFlushManager {
 
-	return &TargetFlushManager{Logger: logger, ConfigFile: configFile}
+	TFM = &TargetFlushManager{Logger: logger, ConfigFile: configFile}
+	return TFM
 }
 func (t *TargetFlushManager) Run(ctx context.Context) error {
 
 	ticker := time.NewTicker(TargetFlushManagerInterval)
 	level.Info(t.Logger).Log("msg", "TargetFlushManager start....")
-	t.refresh()
+	t.Refresh()
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			t.refresh()
+			t.Refresh()
 
 		case <-ctx.Done():
 			level.Info(t.Logger).Log("msg", "TargetFlushManager exit....")
 			return nil
 		}
 	}
-
-	return nil
 }
 
-func (t *TargetFlushManager) refreshFromConfigFile() {
+func (t *TargetFlushManager) refreshFromConfigFile(rs *regionTargetSet) {
 	level.Info(t.Logger).Log("msg", "refreshFromConfigFile run....")
 
-	config, _ := LoadFile(t.ConfigFile, t.Logger)
-	otmpM := make(map[string][]*pb.Targets)
-	icmpM := make(map[string]*pb.Targets)
+	config, err := LoadFile(t.ConfigFile, t.Logger)
+	if err != nil || config == nil {
+		// keep probing the last good targets while the file is broken
+		level.Error(t.Logger).Log("msg", "refreshFromConfigFile load error, use last config....", "err", err)
+		config = t.lastConfig
+	}
+	if config == nil {
+		return
+	}
+	t.lastConfig = config
 	if len(config.ProberTargets) <= 0 {
 		level.Info(t.Logger).Log("msg", "refreshFromConfigFile empty targets....")
 		return
 	}
-	for _, t := range config.ProberTargets {
-		tNew := &pb.Targets{}
-		tNew.Region = t.Region
-		tNew.ProberType = t.ProberType
-		tNew.Target = t.Target
-		otmpM[tNew.Region] = append(otmpM[tNew.Region], tNew)
-		switch t.ProberType {
-		case "icmp":
-			icmpM[tNew.Region] = tNew
-		default:
-			otmpM[tNew.Region] = append(otmpM[tNew.Region], tNew)
-		}
-
+	for _, tg := range config.ProberTargets {
+		rs.add(tg.ProberType, tg.Region, tg.Target)
 	}
-	for k, v := range icmpM {
-		preData, loaded := IcmpRegionProberMap.LoadOrStore(k, v)
-		preDataN := preData.(*pb.Targets)
-		if loaded {
-			thisT := v.Target
-			originT := preDataN.Target
-			thisTM := make(map[string]string)
-			for _, tt := range thisT {
-				thisTM[tt] = tt
-			}
-			for _, tt := range originT {
-				if _, exists := thisTM[tt]; exists == false {
-					thisT = append(thisT, tt)
-				}
-			}
-			a := &pb.Targets{
-				Region:     k,
-				ProberType: "icmp",
-				Target:     thisT,
-			}
-			IcmpRegionProberMap.Store(k, a)
-		}
-
-	}
-
-	for k, v := range otmpM {
+}
 
-		OtherRegionProberMap.Store(k, v)
+// FileTargets returns the targets of the last good config file
+func (t *TargetFlushManager) FileTargets() []*Targets {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.lastConfig == nil {
+		return nil
 	}
+	return t.lastConfig.ProberTargets
 }
 
-func (t *TargetFlushManager) refresh() {
-	go t.refreshFromConfigFile()
-	go t.flushAgentIpIntoGlobalMap()
+// Refresh rebuilds the target pool from agent ips, config file and admin
+// state, agents pick it up on their next GetProberTargets
+func (t *TargetFlushManager) Refresh() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	rs := newRegionTargetSet()
+	t.flushAgentIpIntoGlobalMap(rs)
+	t.refreshFromConfigFile(rs)
+	AdminS.mergeInto(rs)
+	rs.store()
+	if t.lastConfig != nil {
+		atomic.StoreInt32(&targetPoolLoaded, 1)
+	}
 }
 
 func GetTargetsByRegion(sourceRegion string) (res []*pb.Targets) {
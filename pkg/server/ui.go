@@ -0,0 +1,220 @@
+package server
+
+import (
+	"net/http"
+)
+
+// the page only talks to the json api, so it works without prometheus/grafana
+func uiHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(uiIndexHtml))
+}
+
+func rootHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "/ui/", http.StatusFound)
+}
+
+const uiIndexHtml = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>xprober</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 20px; color: #222; }
+h1 { font-size: 20px; }
+h2 { font-size: 16px; margin-top: 28px; }
+table { border-collapse: collapse; font-size: 12px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: center; }
+th { background: #f5f5f5; }
+td.cell { cursor: pointer; min-width: 60px; color: #111; }
+td.empty { background: #fafafa; color: #bbb; }
+.bar { margin-bottom: 12px; }
+.bar label { margin-right: 16px; }
+#updated { color: #888; font-size: 12px; margin-left: 16px; }
+#detail { margin-top: 16px; }
+.ok { color: #2a8a2a; }
+.fail { color: #c62828; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>xprober ping mesh</h1>
+<div class="bar">
+  <label><input type="radio" name="metric" value="ping_latency_millonseconds" checked> latency (ms)</label>
+  <label><input type="radio" name="metric" value="ping_packageDrop_rate"> loss (%)</label>
+  <label><input type="radio" name="metric" value="ping_target_success"> target success</label>
+  <span id="updated"></span>
+</div>
+<div id="matrix"></div>
+<div id="detail"></div>
+
+<h2>http targets</h2>
+<div class="bar">
+  <label>source region <select id="httpRegion"><option value="">all</option></select></label>
+</div>
+<div id="http"></div>
+
+<script>
+var refreshMs = 15000;
+var current = null;
+
+function metric() {
+  return document.querySelector('input[name=metric]:checked').value;
+}
+
+function esc(s) {
+  return String(s).replace(/[&<>"']/g, function (c) {
+    return {'&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;'}[c];
+  });
+}
+
+// green -> yellow -> red, scaled per metric
+function color(m, v) {
+  var r;
+  if (m === 'ping_target_success') {
+    r = v >= 1 ? 0 : 1;
+  } else if (m === 'ping_packageDrop_rate') {
+    r = Math.min(Math.max(v, 0) / 10, 1);
+  } else {
+    r = Math.min(Math.max(v, 0) / 200, 1);
+  }
+  var hue = (1 - r) * 120;
+  return 'hsl(' + hue + ', 70%, 70%)';
+}
+
+function fmt(v) {
+  return (Math.round(v * 100) / 100).toString();
+}
+
+function age(ts) {
+  return Math.max(0, Math.round(Date.now() / 1000 - ts)) + 's ago';
+}
+
+function getJson(url, cb) {
+  var xhr = new XMLHttpRequest();
+  xhr.open('GET', url);
+  xhr.onload = function () {
+    if (xhr.status === 200) {
+      cb(JSON.parse(xhr.responseText));
+    }
+  };
+  xhr.send();
+}
+
+function renderMatrix(data) {
+  var m = data.metric;
+  var regions = data.regions || [];
+  if (regions.length === 0) {
+    document.getElementById('matrix').innerHTML = '<p>no data yet</p>';
+    return;
+  }
+  var h = '<table><tr><th>source \\ target</th>';
+  regions.forEach(function (t) { h += '<th>' + esc(t) + '</th>'; });
+  h += '</tr>';
+  regions.forEach(function (s) {
+    h += '<tr><th>' + esc(s) + '</th>';
+    regions.forEach(function (t) {
+      var c = (data.matrix[s] || {})[t];
+      if (!c) {
+        h += '<td class="empty">-</td>';
+        return;
+      }
+      h += '<td class="cell" style="background:' + color(m, c.value) + '" data-s="' + esc(s) + '" data-t="' + esc(t) + '"' +
+        ' title="' + c.agent_num + ' agents, ' + c.sample_num + ' samples, ' + age(c.timestamp) + '">' + fmt(c.value) + '</td>';
+    });
+    h += '</tr>';
+  });
+  h += '</table>';
+  document.getElementById('matrix').innerHTML = h;
+  Array.prototype.forEach.call(document.querySelectorAll('td.cell'), function (td) {
+    td.onclick = function () {
+      current = {s: td.getAttribute('data-s'), t: td.getAttribute('data-t')};
+      loadDetail();
+    };
+  });
+}
+
+function loadDetail() {
+  if (!current) {
+    return;
+  }
+  var url = '/api/v1/samples?source_region=' + encodeURIComponent(current.s) +
+    '&target_region=' + encodeURIComponent(current.t) + '&metric=' + encodeURIComponent(metric());
+  getJson(url, function (samples) {
+    var h = '<h2>' + esc(current.s) + ' &rarr; ' + esc(current.t) + ' (' + esc(metric()) + ')</h2>';
+    h += '<table><tr><th>agent</th><th>target addr</th><th>value</th><th>updated</th></tr>';
+    samples.forEach(function (p) {
+      h += '<tr><td>' + esc(p.worker_name) + '</td><td>' + esc(p.target_addr) + '</td><td>' + fmt(p.value || 0) +
+        '</td><td>' + age(p.time_stamp) + '</td></tr>';
+    });
+    h += '</table>';
+    document.getElementById('detail').innerHTML = h;
+  });
+}
+
+var httpMetrics = [
+  ['http_interface_success', 'success'],
+  ['http_resolveDuration_millonseconds', 'resolve ms'],
+  ['http_connectDuration_millonseconds', 'connect ms'],
+  ['http_tlsDuration_millonseconds', 'tls ms'],
+  ['http_processingDuration_millonseconds', 'processing ms'],
+  ['http_transferDuration_millonseconds', 'transfer ms']
+];
+
+function renderHttp(rows) {
+  var sel = document.getElementById('httpRegion');
+  var known = {};
+  Array.prototype.forEach.call(sel.options, function (o) { known[o.value] = true; });
+  var h = '<table><tr><th>source region</th><th>addr</th>';
+  httpMetrics.forEach(function (hm) { h += '<th>' + hm[1] + '</th>'; });
+  h += '<th>agents</th><th>updated</th></tr>';
+  rows.forEach(function (r) {
+    if (!known[r.source_region]) {
+      var o = document.createElement('option');
+      o.value = o.text = r.source_region;
+      sel.appendChild(o);
+      known[r.source_region] = true;
+    }
+    var agents = 0, ts = 0;
+    h += '<tr><td>' + esc(r.source_region) + '</td><td style="text-align:left">' + esc(r.addr) + '</td>';
+    httpMetrics.forEach(function (hm) {
+      var c = r.metrics[hm[0]];
+      if (!c) {
+        h += '<td class="empty">-</td>';
+        return;
+      }
+      agents = Math.max(agents, c.agent_num);
+      ts = Math.max(ts, c.timestamp);
+      if (hm[0] === 'http_interface_success') {
+        h += c.value >= 1 ? '<td class="ok">up</td>' : '<td class="fail">' + fmt(c.value * 100) + '%</td>';
+      } else {
+        h += '<td>' + fmt(c.value) + '</td>';
+      }
+    });
+    h += '<td>' + agents + '</td><td>' + age(ts) + '</td></tr>';
+  });
+  h += '</table>';
+  document.getElementById('http').innerHTML = rows.length ? h : '<p>no data yet</p>';
+}
+
+function refresh() {
+  getJson('/api/v1/matrix?metric=' + encodeURIComponent(metric()), renderMatrix);
+  var region = document.getElementById('httpRegion').value;
+  getJson('/api/v1/http?source_region=' + encodeURIComponent(region), renderHttp);
+  loadDetail();
+  document.getElementById('updated').textContent = 'updated ' + new Date().toLocaleTimeString();
+}
+
+Array.prototype.forEach.call(document.querySelectorAll('input[name=metric]'), function (i) {
+  i.onchange = refresh;
+});
+document.getElementById('httpRegion').onchange = refresh;
+refresh();
+setInterval(refresh, refreshMs);
+</script>
+</body>
+</html>
+`
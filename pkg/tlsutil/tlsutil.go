@@ -0,0 +1,257 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+/*
+   tls material for the agent <-> server grpc connection. the files are
+   watched by modification time and reloaded on the next handshake after a
+   change, so rotated certificates are picked up without a restart
+*/
+
+const (
+	ClientAuthNone    = `none`
+	ClientAuthRequest = `request`
+	ClientAuthRequire = `require`
+
+	// files are stat'ed at most this often
+	reloadCheckInterval = 10 * time.Second
+)
+
+type Files struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// Reloader holds the current certificate and ca pool loaded from Files
+type Reloader struct {
+	files  Files
+	logger log.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+func NewReloader(logger log.Logger, files Files) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, fmt.Errorf("tls cert file and key file must be set together")
+	}
+	r := &Reloader{files: files, logger: log.With(logger, "component", "tls")}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) paths() []string {
+	var ps []string
+	for _, p := range []string{r.files.CAFile, r.files.CertFile, r.files.KeyFile} {
+		if p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, p := range r.paths() {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		modTimes[p] = fi.ModTime()
+	}
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load tls key pair: %v", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := ioutil.ReadFile(r.files.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in ca file %s", r.files.CAFile)
+		}
+	}
+	r.mu.Lock()
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// current reloads the files when one changed and returns the material to use,
+// a broken rotation keeps the previous certificate
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	changed := false
+	if time.Since(r.lastCheck) >= reloadCheckInterval {
+		r.lastCheck = time.Now()
+		for _, p := range r.paths() {
+			fi, err := os.Stat(p)
+			if err == nil && !fi.ModTime().Equal(r.modTimes[p]) {
+				changed = true
+				break
+			}
+		}
+	}
+	r.mu.Unlock()
+	if changed {
+		if err := r.load(); err != nil {
+			level.Error(r.logger).Log("msg", "tls reload failed, keeping previous certificate", "err", err)
+		} else {
+			level.Info(r.logger).Log("msg", "tls certificate reloaded", "cert_file", r.files.CertFile, "ca_file", r.files.CAFile)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.pool
+}
+
+// ServerCredentials verifies client certificates against the ca file according to clientAuth
+func (r *Reloader) ServerCredentials(clientAuth string) (credentials.TransportCredentials, error) {
+	if r.files.CertFile == "" {
+		return nil, fmt.Errorf("tls server needs a cert file and key file")
+	}
+	var auth tls.ClientAuthType
+	switch clientAuth {
+	case "":
+		auth = tls.NoClientCert
+		if r.files.CAFile != "" {
+			auth = tls.RequireAndVerifyClientCert
+		}
+	case ClientAuthNone:
+		auth = tls.NoClientCert
+	case ClientAuthRequest:
+		auth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		auth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown tls client auth %q", clientAuth)
+	}
+	if auth != tls.NoClientCert && r.files.CAFile == "" {
+		return nil, fmt.Errorf("tls client auth %q needs a ca file", clientAuth)
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   auth,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+	return credentials.NewTLS(base), nil
+}
+
+// ClientCredentials verifies the server against the ca file, or the system
+// pool when unset, and presents the client certificate when one is set
+func (r *Reloader) ClientCredentials(serverName string) credentials.TransportCredentials {
+	return &reloadingClientCreds{r: r, serverName: serverName}
+}
+
+// reloadingClientCreds builds a fresh tls config per handshake, the root
+// pool of a tls.Config can not be swapped otherwise
+type reloadingClientCreds struct {
+	r          *Reloader
+	serverName string
+}
+
+func (c *reloadingClientCreds) creds() credentials.TransportCredentials {
+	cert, pool := c.r.current()
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+		ServerName: c.serverName,
+	}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+	return credentials.NewTLS(cfg)
+}
+
+func (c *reloadingClientCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.creds().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingClientCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("client credentials can not be used on a server")
+}
+
+func (c *reloadingClientCreds) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls", SecurityVersion: "1.2", ServerName: c.serverName}
+}
+
+func (c *reloadingClientCreds) Clone() credentials.TransportCredentials {
+	return &reloadingClientCreds{r: c.r, serverName: c.serverName}
+}
+
+func (c *reloadingClientCreds) OverrideServerName(name string) error {
+	c.serverName = name
+	return nil
+}
+
+// PeerIdentity returns the verified client certificate identity of the rpc
+// peer, the common name followed by the dns and uri sans, empty without mtls
+func PeerIdentity(ctx context.Context) string {
+	return strings.Join(PeerNames(ctx), ",")
+}
+
+// PeerNames returns the names of the verified client certificate of the rpc peer
+func PeerNames(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil
+	}
+	ti, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(ti.State.VerifiedChains) == 0 || len(ti.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return CertNames(ti.State.VerifiedChains[0][0])
+}
+
+func CertNames(c *x509.Certificate) []string {
+	var names []string
+	if c.Subject.CommonName != "" {
+		names = append(names, c.Subject.CommonName)
+	}
+	for _, n := range c.DNSNames {
+		if n != c.Subject.CommonName {
+			names = append(names, n)
+		}
+	}
+	for _, u := range c.URIs {
+		names = append(names, u.String())
+	}
+	return names
+}
@@ -0,0 +1,679 @@
+package tsdb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+/*
+   a small embedded store for the server's aggregated series: recent samples
+   live in the head backed by a json lines wal, completed block ranges are
+   cut into gzipped gob files, finished days are compacted into one block per
+   day and blocks past retention are deleted
+*/
+
+const (
+	DefaultRetention     = 7 * 24 * time.Hour
+	DefaultBlockDuration = 2 * time.Hour
+
+	walFileName     = `wal`
+	blockFilePrefix = `block-`
+	blockFileSuffix = `.gob.gz`
+
+	daySeconds          = 24 * 3600
+	maintenanceInterval = time.Minute
+	walFlushInterval    = 5 * time.Second
+)
+
+type Labels map[string]string
+
+// String is the canonical sorted form used as series identity
+func (l Labels) String() string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(l[k])
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// Matches reports whether every non empty value of m equals the label
+func (l Labels) Matches(m Labels) bool {
+	for k, v := range m {
+		if v != "" && l[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type Point struct {
+	T int64
+	V float64
+}
+
+// MarshalJSON writes a point as [unix_seconds, value] like prometheus
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]interface{}{p.T, p.V})
+}
+
+type Series struct {
+	Metric string  `json:"metric"`
+	Labels Labels  `json:"labels"`
+	Points []Point `json:"points"`
+}
+
+func seriesKey(metric string, labels Labels) string {
+	return metric + "{" + labels.String() + "}"
+}
+
+type Options struct {
+	Dir           string
+	Retention     time.Duration
+	BlockDuration time.Duration
+}
+
+type blockMeta struct {
+	path       string
+	minT, maxT int64
+}
+
+// blockData is the gob encoded content of a block file, [MinT, MaxT)
+type blockData struct {
+	MinT, MaxT int64
+	Series     []*blockSeries
+}
+
+type blockSeries struct {
+	Metric string
+	Labels Labels
+	Ts     []int64
+	Vs     []float64
+}
+
+type DB struct {
+	logger log.Logger
+	opts   Options
+
+	mu     sync.Mutex
+	head   map[string]*blockSeries
+	blocks []*blockMeta
+	wal    *os.File
+	walW   *bufio.Writer
+	// set by Close, later appends are dropped
+	closed bool
+}
+
+type walRecord struct {
+	M string  `json:"m"`
+	L Labels  `json:"l"`
+	T int64   `json:"t"`
+	V float64 `json:"v"`
+}
+
+func Open(logger log.Logger, opts Options) (*DB, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("tsdb dir is required")
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.BlockDuration <= 0 {
+		opts.BlockDuration = DefaultBlockDuration
+	}
+	if daySeconds%int64(opts.BlockDuration.Seconds()) != 0 {
+		return nil, fmt.Errorf("block duration %s must divide a day", opts.BlockDuration)
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+	db := &DB{
+		logger: logger,
+		opts:   opts,
+		head:   make(map[string]*blockSeries),
+	}
+	if err := db.loadBlocks(); err != nil {
+		return nil, err
+	}
+	if err := db.replayWal(); err != nil {
+		return nil, err
+	}
+	if err := db.openWal(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *DB) loadBlocks() error {
+	files, err := ioutil.ReadDir(db.opts.Dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasPrefix(name, blockFilePrefix) || !strings.HasSuffix(name, blockFileSuffix) {
+			continue
+		}
+		bm := &blockMeta{path: filepath.Join(db.opts.Dir, name)}
+		rng := strings.TrimSuffix(strings.TrimPrefix(name, blockFilePrefix), blockFileSuffix)
+		if _, err := fmt.Sscanf(rng, "%d-%d", &bm.minT, &bm.maxT); err != nil {
+			level.Warn(db.logger).Log("msg", "skip unknown block file", "file", name)
+			continue
+		}
+		db.blocks = append(db.blocks, bm)
+	}
+	db.sortBlocks()
+	return nil
+}
+
+func (db *DB) sortBlocks() {
+	sort.Slice(db.blocks, func(i, j int) bool { return db.blocks[i].minT < db.blocks[j].minT })
+}
+
+func (db *DB) replayWal() error {
+	f, err := os.Open(filepath.Join(db.opts.Dir, walFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	n, bad := 0, 0
+	for sc.Scan() {
+		r := &walRecord{}
+		if err := json.Unmarshal(sc.Bytes(), r); err != nil {
+			// a torn last line after a crash
+			bad++
+			continue
+		}
+		db.appendHead(r.M, r.L, r.T, r.V)
+		n++
+	}
+	level.Info(db.logger).Log("msg", "tsdb wal replayed", "samples", n, "bad_records", bad, "blocks", len(db.blocks))
+	return sc.Err()
+}
+
+func (db *DB) openWal() error {
+	f, err := os.OpenFile(filepath.Join(db.opts.Dir, walFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	db.wal = f
+	db.walW = bufio.NewWriter(f)
+	return nil
+}
+
+func (db *DB) appendHead(metric string, labels Labels, t int64, v float64) {
+	key := seriesKey(metric, labels)
+	s, ok := db.head[key]
+	if !ok {
+		s = &blockSeries{Metric: metric, Labels: labels}
+		db.head[key] = s
+	}
+	s.Ts = append(s.Ts, t)
+	s.Vs = append(s.Vs, v)
+}
+
+// Append adds one sample, t is unix seconds
+func (db *DB) Append(metric string, labels map[string]string, t int64, v float64) {
+	l := make(Labels, len(labels))
+	for k, lv := range labels {
+		l[k] = lv
+	}
+	line, err := json.Marshal(&walRecord{M: metric, L: l, T: t, V: v})
+	if err != nil {
+		return
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return
+	}
+	db.appendHead(metric, l, t, v)
+	db.walW.Write(line)
+	db.walW.WriteByte('\n')
+}
+
+// Run flushes the wal and does cutting, compaction and retention until ctx is done
+func (db *DB) Run(ctx context.Context) error {
+	flush := time.NewTicker(walFlushInterval)
+	maint := time.NewTicker(maintenanceInterval)
+	defer flush.Stop()
+	defer maint.Stop()
+	db.maintenance()
+	for {
+		select {
+		case <-flush.C:
+			db.flushWal()
+		case <-maint.C:
+			db.maintenance()
+		case <-ctx.Done():
+			return db.Close()
+		}
+	}
+}
+
+func (db *DB) flushWal() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return
+	}
+	if err := db.walW.Flush(); err != nil {
+		level.Error(db.logger).Log("msg", "tsdb wal flush error", "err", err)
+	}
+}
+
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	if err := db.walW.Flush(); err != nil {
+		return err
+	}
+	return db.wal.Close()
+}
+
+func (db *DB) maintenance() {
+	now := time.Now().Unix()
+	if err := db.cut(now); err != nil {
+		level.Error(db.logger).Log("msg", "tsdb cut head error", "err", err)
+	}
+	if err := db.compact(now); err != nil {
+		level.Error(db.logger).Log("msg", "tsdb compact error", "err", err)
+	}
+	db.applyRetention(now)
+}
+
+// cut moves every head sample of a finished block range into block files
+// and rewrites the wal with what is left
+func (db *DB) cut(now int64) error {
+	bd := int64(db.opts.BlockDuration.Seconds())
+	boundary := now / bd * bd
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		// the wal is closed and must not be rewritten
+		return nil
+	}
+	blocks := make(map[int64]*blockData)
+	remaining := make(map[string]*blockSeries)
+	for key, s := range db.head {
+		rest := &blockSeries{Metric: s.Metric, Labels: s.Labels}
+		parts := make(map[int64]*blockSeries)
+		for i, t := range s.Ts {
+			if t >= boundary {
+				rest.Ts = append(rest.Ts, t)
+				rest.Vs = append(rest.Vs, s.Vs[i])
+				continue
+			}
+			minT := t / bd * bd
+			p, ok := parts[minT]
+			if !ok {
+				p = &blockSeries{Metric: s.Metric, Labels: s.Labels}
+				parts[minT] = p
+				b, ok := blocks[minT]
+				if !ok {
+					b = &blockData{MinT: minT, MaxT: minT + bd}
+					blocks[minT] = b
+				}
+				b.Series = append(b.Series, p)
+			}
+			p.Ts = append(p.Ts, t)
+			p.Vs = append(p.Vs, s.Vs[i])
+		}
+		if len(rest.Ts) > 0 {
+			remaining[key] = rest
+		}
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	for _, b := range blocks {
+		// a late sample for a range that is already on disk is merged into it
+		if old := db.findBlock(b.MinT, b.MaxT); old != nil {
+			od, err := readBlock(old.path)
+			if err != nil {
+				return err
+			}
+			b = mergeBlocks(b.MinT, b.MaxT, []*blockData{od, b})
+		}
+		if err := db.writeBlock(b); err != nil {
+			return err
+		}
+	}
+	db.head = remaining
+	return db.rewriteWal()
+}
+
+func (db *DB) findBlock(minT, maxT int64) *blockMeta {
+	for _, bm := range db.blocks {
+		if bm.minT == minT && bm.maxT == maxT {
+			return bm
+		}
+	}
+	return nil
+}
+
+func (db *DB) rewriteWal() error {
+	db.walW.Flush()
+	db.wal.Close()
+	tmp := filepath.Join(db.opts.Dir, walFileName+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, s := range db.head {
+		for i, t := range s.Ts {
+			line, _ := json.Marshal(&walRecord{M: s.Metric, L: s.Labels, T: t, V: s.Vs[i]})
+			w.Write(line)
+			w.WriteByte('\n')
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, filepath.Join(db.opts.Dir, walFileName)); err != nil {
+		return err
+	}
+	return db.openWal()
+}
+
+// compact merges the blocks of every finished day into a single day block
+func (db *DB) compact(now int64) error {
+	today := now / daySeconds * daySeconds
+	db.mu.Lock()
+	days := make(map[int64][]*blockMeta)
+	for _, bm := range db.blocks {
+		if bm.maxT > today || bm.maxT-bm.minT >= daySeconds {
+			continue
+		}
+		day := bm.minT / daySeconds * daySeconds
+		days[day] = append(days[day], bm)
+	}
+	db.mu.Unlock()
+
+	for day, bms := range days {
+		var parts []*blockData
+		// a late block of an already compacted day is merged into it
+		db.mu.Lock()
+		existing := db.findBlock(day, day+daySeconds)
+		db.mu.Unlock()
+		if existing != nil {
+			b, err := readBlock(existing.path)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, b)
+		}
+		for _, bm := range bms {
+			b, err := readBlock(bm.path)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, b)
+		}
+		merged := mergeBlocks(day, day+daySeconds, parts)
+		db.mu.Lock()
+		err := db.writeBlock(merged)
+		if err == nil {
+			db.removeBlocks(bms)
+		}
+		db.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		level.Info(db.logger).Log("msg", "tsdb blocks compacted", "day", time.Unix(day, 0).UTC().Format("2006-01-02"), "blocks", len(bms))
+	}
+	return nil
+}
+
+func (db *DB) removeBlocks(bms []*blockMeta) {
+	drop := make(map[*blockMeta]bool)
+	for _, bm := range bms {
+		drop[bm] = true
+		if err := os.Remove(bm.path); err != nil && !os.IsNotExist(err) {
+			level.Warn(db.logger).Log("msg", "tsdb remove block error", "file", bm.path, "err", err)
+		}
+	}
+	res := db.blocks[:0]
+	for _, bm := range db.blocks {
+		if !drop[bm] {
+			res = append(res, bm)
+		}
+	}
+	db.blocks = res
+}
+
+func (db *DB) applyRetention(now int64) {
+	cutoff := now - int64(db.opts.Retention.Seconds())
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var expired []*blockMeta
+	for _, bm := range db.blocks {
+		if bm.maxT <= cutoff {
+			expired = append(expired, bm)
+		}
+	}
+	if len(expired) > 0 {
+		db.removeBlocks(expired)
+		level.Info(db.logger).Log("msg", "tsdb blocks expired", "blocks", len(expired))
+	}
+}
+
+func mergeBlocks(minT, maxT int64, parts []*blockData) *blockData {
+	merged := &blockData{MinT: minT, MaxT: maxT}
+	byKey := make(map[string]*blockSeries)
+	for _, p := range parts {
+		for _, s := range p.Series {
+			key := seriesKey(s.Metric, s.Labels)
+			m, ok := byKey[key]
+			if !ok {
+				m = &blockSeries{Metric: s.Metric, Labels: s.Labels}
+				byKey[key] = m
+				merged.Series = append(merged.Series, m)
+			}
+			m.Ts = append(m.Ts, s.Ts...)
+			m.Vs = append(m.Vs, s.Vs...)
+		}
+	}
+	for _, s := range merged.Series {
+		sortSamples(s)
+	}
+	return merged
+}
+
+func sortSamples(s *blockSeries) {
+	idx := make([]int, len(s.Ts))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return s.Ts[idx[i]] < s.Ts[idx[j]] })
+	ts := make([]int64, 0, len(idx))
+	vs := make([]float64, 0, len(idx))
+	for _, i := range idx {
+		// keep the last written sample of a duplicated timestamp
+		if n := len(ts); n > 0 && ts[n-1] == s.Ts[i] {
+			vs[n-1] = s.Vs[i]
+			continue
+		}
+		ts = append(ts, s.Ts[i])
+		vs = append(vs, s.Vs[i])
+	}
+	s.Ts, s.Vs = ts, vs
+}
+
+func blockFileName(minT, maxT int64) string {
+	return fmt.Sprintf("%s%d-%d%s", blockFilePrefix, minT, maxT, blockFileSuffix)
+}
+
+// writeBlock persists b and registers it, callers hold db.mu
+func (db *DB) writeBlock(b *blockData) error {
+	path := filepath.Join(db.opts.Dir, blockFileName(b.MinT, b.MaxT))
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if err := gob.NewEncoder(zw).Encode(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if db.findBlock(b.MinT, b.MaxT) == nil {
+		db.blocks = append(db.blocks, &blockMeta{path: path, minT: b.MinT, maxT: b.MaxT})
+		db.sortBlocks()
+	}
+	return nil
+}
+
+func readBlock(path string) (*blockData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	b := &blockData{}
+	if err := gob.NewDecoder(zr).Decode(b); err != nil {
+		return nil, fmt.Errorf("decode block %s: %v", path, err)
+	}
+	return b, nil
+}
+
+// Query returns the samples of metric whose labels match in [start, end],
+// averaged per step when step > 0, series are sorted by labels
+func (db *DB) Query(metric string, match Labels, start, end, step int64) ([]*Series, error) {
+	db.mu.Lock()
+	var paths []string
+	for _, bm := range db.blocks {
+		if bm.maxT > start && bm.minT <= end {
+			paths = append(paths, bm.path)
+		}
+	}
+	collected := make(map[string]*blockSeries)
+	collect := func(s *blockSeries) {
+		if s.Metric != metric || !s.Labels.Matches(match) {
+			return
+		}
+		key := seriesKey(s.Metric, s.Labels)
+		c, ok := collected[key]
+		if !ok {
+			c = &blockSeries{Metric: s.Metric, Labels: s.Labels}
+			collected[key] = c
+		}
+		for i, t := range s.Ts {
+			if t >= start && t <= end {
+				c.Ts = append(c.Ts, t)
+				c.Vs = append(c.Vs, s.Vs[i])
+			}
+		}
+	}
+	for _, s := range db.head {
+		collect(s)
+	}
+	db.mu.Unlock()
+
+	// block files are immutable once written, a concurrent compaction may
+	// remove one in between so a missing file is skipped
+	for _, p := range paths {
+		b, err := readBlock(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range b.Series {
+			collect(s)
+		}
+	}
+
+	res := make([]*Series, 0, len(collected))
+	for _, c := range collected {
+		if len(c.Ts) == 0 {
+			continue
+		}
+		sortSamples(c)
+		res = append(res, &Series{Metric: c.Metric, Labels: c.Labels, Points: downsample(c, start, step)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Labels.String() < res[j].Labels.String() })
+	return res, nil
+}
+
+func downsample(s *blockSeries, start, step int64) []Point {
+	points := make([]Point, 0, len(s.Ts))
+	if step <= 0 {
+		for i, t := range s.Ts {
+			points = append(points, Point{T: t, V: s.Vs[i]})
+		}
+		return points
+	}
+	var (
+		bucket int64 = -1
+		sum    float64
+		n      int
+	)
+	for i, t := range s.Ts {
+		b := start + (t-start)/step*step
+		if b != bucket && n > 0 {
+			points = append(points, Point{T: bucket, V: sum / float64(n)})
+			sum, n = 0, 0
+		}
+		bucket = b
+		sum += s.Vs[i]
+		n++
+	}
+	if n > 0 {
+		points = append(points, Point{T: bucket, V: sum / float64(n)})
+	}
+	return points
+}
@@ -0,0 +1,196 @@
+package tsdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+var testLabels = map[string]string{"source_region": "r1", "target_region": "r2"}
+
+func openTestDB(t *testing.T, dir string, retention time.Duration) *DB {
+	db, err := Open(log.NewNopLogger(), Options{Dir: dir, Retention: retention, BlockDuration: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "xprober-tsdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func blockFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, blockFilePrefix+"*"+blockFileSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range files {
+		files[i] = filepath.Base(f)
+	}
+	return files
+}
+
+func queryPoints(t *testing.T, db *DB, start, end int64) []Point {
+	res, err := db.Query("m", Labels{"source_region": "r1"}, start, end, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	if len(res) != 1 {
+		t.Fatalf("got %d series, want 1", len(res))
+	}
+	return res[0].Points
+}
+
+func TestWalReplay(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	now := time.Now().Unix()
+	db := openTestDB(t, dir, 0)
+	for i := int64(0); i < 10; i++ {
+		db.Append("m", testLabels, now-i, float64(i))
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// a torn record after a crash is skipped
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"m":"m","l":`)
+	f.Close()
+
+	db = openTestDB(t, dir, 0)
+	defer db.Close()
+	points := queryPoints(t, db, now-100, now)
+	if len(points) != 10 {
+		t.Fatalf("replayed %d points, want 10", len(points))
+	}
+	if points[0].T != now-9 || points[0].V != 9 {
+		t.Fatalf("first point %v, want [%d 9]", points[0], now-9)
+	}
+}
+
+func TestCutAndCompact(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	db := openTestDB(t, dir, 30*24*time.Hour)
+	defer db.Close()
+	today := time.Now().Unix() / daySeconds * daySeconds
+	yesterday := today - daySeconds
+	now := today + 3*3600 + 60
+	// two blocks of yesterday, one finished block of today and the head
+	for _, ts := range []int64{yesterday + 60, yesterday + 3*3600, today + 60, now - 30} {
+		db.Append("m", testLabels, ts, 1)
+	}
+
+	if err := db.cut(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(blockFiles(t, dir)); n != 3 {
+		t.Fatalf("cut wrote %d blocks, want 3: %v", n, blockFiles(t, dir))
+	}
+	if len(db.head) != 1 {
+		t.Fatalf("head keeps %d series, want 1", len(db.head))
+	}
+	wal, err := ioutil.ReadFile(filepath.Join(dir, walFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := len(splitLines(wal)); lines != 1 {
+		t.Fatalf("rewritten wal holds %d samples, want 1", lines)
+	}
+
+	if err := db.compact(now); err != nil {
+		t.Fatal(err)
+	}
+	files := blockFiles(t, dir)
+	want := map[string]bool{
+		blockFileName(yesterday, today):    true,
+		blockFileName(today, today+2*3600): true,
+	}
+	if len(files) != len(want) {
+		t.Fatalf("blocks after compaction %v, want %v", files, want)
+	}
+	for _, f := range files {
+		if !want[f] {
+			t.Fatalf("unexpected block %s after compaction", f)
+		}
+	}
+	if points := queryPoints(t, db, yesterday, now); len(points) != 4 {
+		t.Fatalf("query after compaction got %d points, want 4", len(points))
+	}
+}
+
+func TestRetention(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	db := openTestDB(t, dir, 48*time.Hour)
+	defer db.Close()
+	now := time.Now().Unix()
+	db.Append("m", testLabels, now-5*daySeconds, 1)
+	db.Append("m", testLabels, now-3600*3, 2)
+	if err := db.cut(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(blockFiles(t, dir)); n != 2 {
+		t.Fatalf("cut wrote %d blocks, want 2", n)
+	}
+	db.applyRetention(now)
+	if n := len(blockFiles(t, dir)); n != 1 {
+		t.Fatalf("%d blocks left after retention, want 1", n)
+	}
+	points := queryPoints(t, db, now-7*daySeconds, now)
+	if len(points) != 1 || points[0].V != 2 {
+		t.Fatalf("query after retention got %v, want only the recent point", points)
+	}
+}
+
+func TestAppendAfterClose(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	db := openTestDB(t, dir, 0)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	db.Append("m", testLabels, now, 1)
+	db.flushWal()
+	if err := db.cut(now + daySeconds); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+	if points := queryPoints(t, db, now-10, now); len(points) != 0 {
+		t.Fatalf("append after close stored %v", points)
+	}
+}
+
+func splitLines(b []byte) [][]byte {
+	var lines [][]byte
+	start := 0
+	for i, c := range b {
+		if c == '\n' {
+			lines = append(lines, b[start:i])
+			start = i + 1
+		}
+	}
+	return lines
+}